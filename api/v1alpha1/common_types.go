@@ -20,12 +20,36 @@ type KafkaAccessType string
 // KafkaPatternType hold the Resource Pattern Type of kafka ACL
 type KafkaPatternType string
 
+// KafkaResourceType is the type of the Kafka resource an ACL applies to
+// +kubebuilder:validation:Enum={"topic","group","cluster","transactional-id","delegation-token"}
+type KafkaResourceType string
+
+// KafkaACLOperation is an operation allowed or denied by a Kafka ACL
+// +kubebuilder:validation:Enum={"all","read","write","create","delete","alter","describe","cluster-action","describe-configs","alter-configs","idempotent-write"}
+type KafkaACLOperation string
+
+// KafkaACLPermissionType states whether a Kafka ACL allows or denies its operations
+// +kubebuilder:validation:Enum={"allow","deny"}
+type KafkaACLPermissionType string
+
 // TopicState defines the state of a KafkaTopic
 type TopicState string
 
+// TopicDriftPolicy defines how the differences between a KafkaTopic and the actual Kafka topic are handled
+type TopicDriftPolicy string
+
 // UserState defines the state of a KafkaUser
 type UserState string
 
+// UserAuthenticationType defines the mechanism a KafkaUser authenticates with
+type UserAuthenticationType string
+
+// OffsetResetStrategy defines how the committed offsets of a consumer group are reset
+type OffsetResetStrategy string
+
+// OffsetResetState defines the state of a consumer group offset reset
+type OffsetResetState string
+
 // ClusterReference states a reference to a cluster for topic/user
 // provisioning
 type ClusterReference struct {
@@ -60,10 +84,64 @@ const (
 	KafkaPatternTypeMatch    KafkaPatternType = "match"
 	KafkaPatternTypePrefixed KafkaPatternType = "prefixed"
 	KafkaPatternTypeDefault  KafkaPatternType = "literal"
+	// Resource types of the Kafka ACLs
+	KafkaResourceTypeTopic           KafkaResourceType = "topic"
+	KafkaResourceTypeGroup           KafkaResourceType = "group"
+	KafkaResourceTypeCluster         KafkaResourceType = "cluster"
+	KafkaResourceTypeTransactionalID KafkaResourceType = "transactional-id"
+	KafkaResourceTypeDelegationToken KafkaResourceType = "delegation-token"
+	// KafkaClusterResourceName is the name of the single cluster resource of Kafka
+	KafkaClusterResourceName string = "kafka-cluster"
+	// Operations of the Kafka ACLs. More info: https://kafka.apache.org/documentation/#operations_resources_and_protocols
+	KafkaACLOperationAll             KafkaACLOperation = "all"
+	KafkaACLOperationRead            KafkaACLOperation = "read"
+	KafkaACLOperationWrite           KafkaACLOperation = "write"
+	KafkaACLOperationCreate          KafkaACLOperation = "create"
+	KafkaACLOperationDelete          KafkaACLOperation = "delete"
+	KafkaACLOperationAlter           KafkaACLOperation = "alter"
+	KafkaACLOperationDescribe        KafkaACLOperation = "describe"
+	KafkaACLOperationClusterAction   KafkaACLOperation = "cluster-action"
+	KafkaACLOperationDescribeConfigs KafkaACLOperation = "describe-configs"
+	KafkaACLOperationAlterConfigs    KafkaACLOperation = "alter-configs"
+	KafkaACLOperationIdempotentWrite KafkaACLOperation = "idempotent-write"
+	// KafkaACLPermissionAllow states that an ACL allows its operations
+	KafkaACLPermissionAllow KafkaACLPermissionType = "allow"
+	// KafkaACLPermissionDeny states that an ACL denies its operations, deny rules take precedence over allow rules
+	KafkaACLPermissionDeny KafkaACLPermissionType = "deny"
+	// KafkaACLAnyHost is the host of the ACLs applying to every host
+	KafkaACLAnyHost string = "*"
+	// KafkaAnyConsumerGroup is the name of the group resource of the ACLs allowing to join every consumer group
+	KafkaAnyConsumerGroup string = "*"
 	// TopicStateCreated describes the status of a KafkaTopic as created
 	TopicStateCreated TopicState = "created"
+	// TopicDriftPolicyEnforce states that the Kafka topic is changed to match the KafkaTopic
+	TopicDriftPolicyEnforce TopicDriftPolicy = "Enforce"
+	// TopicDriftPolicyDetect states that the differences from the KafkaTopic are only reported in its status
+	TopicDriftPolicyDetect TopicDriftPolicy = "Detect"
+	// TopicConditionDrifted is the condition type reporting whether the Kafka topic differs from the KafkaTopic
+	TopicConditionDrifted string = "Drifted"
 	// UserStateCreated describes the status of a KafkaUser as created
 	UserStateCreated UserState = "created"
+	// UserAuthenticationTLS states that the KafkaUser authenticates with a client certificate
+	UserAuthenticationTLS UserAuthenticationType = "tls"
+	// UserAuthenticationScramSHA256 states that the KafkaUser authenticates with a SCRAM-SHA-256 password
+	UserAuthenticationScramSHA256 UserAuthenticationType = "scram-sha-256"
+	// UserAuthenticationScramSHA512 states that the KafkaUser authenticates with a SCRAM-SHA-512 password
+	UserAuthenticationScramSHA512 UserAuthenticationType = "scram-sha-512"
+	// OffsetResetToEarliest resets the offsets to the earliest offset of the partitions
+	OffsetResetToEarliest OffsetResetStrategy = "to-earliest"
+	// OffsetResetToLatest resets the offsets to the end of the partitions
+	OffsetResetToLatest OffsetResetStrategy = "to-latest"
+	// OffsetResetToTimestamp resets the offsets to the first message produced at or after the timestamp
+	OffsetResetToTimestamp OffsetResetStrategy = "to-timestamp"
+	// OffsetResetShiftBy moves the committed offsets forward or backward by the given number of messages
+	OffsetResetShiftBy OffsetResetStrategy = "shift-by"
+	// OffsetResetStatePending describes an offset reset waiting for the consumer group to become empty
+	OffsetResetStatePending OffsetResetState = "Pending"
+	// OffsetResetStateCompleted describes an offset reset whose offsets have been committed
+	OffsetResetStateCompleted OffsetResetState = "Completed"
+	// OffsetResetStateFailed describes an offset reset which could not be run
+	OffsetResetStateFailed OffsetResetState = "Failed"
 	// TLSJKSKeyStore is where a JKS keystore is stored in a user secret when requested
 	TLSJKSKeyStore string = "keystore.jks"
 	// TLSJKSTrustStore is where a JKS truststore is stored in a user secret when requested
@@ -84,6 +162,8 @@ const (
 	PeerCertKey string = "peerCert"
 	// PeerPrivateKeyKey stores the peer private key
 	PeerPrivateKeyKey string = "peerKey"
-	// PasswordKey stores the JKS password
+	// PasswordKey stores the JKS password, or the SCRAM password of users authenticating with SCRAM
 	PasswordKey string = "password"
+	// UsernameKey stores the SCRAM user name of users authenticating with SCRAM
+	UsernameKey string = "username"
 )
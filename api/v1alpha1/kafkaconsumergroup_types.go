@@ -0,0 +1,162 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"errors"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KafkaConsumerGroupSpec defines the desired state of KafkaConsumerGroup
+// +k8s:openapi-gen=true
+type KafkaConsumerGroupSpec struct {
+	// Name is the id of the consumer group in Kafka
+	Name       string           `json:"name"`
+	ClusterRef ClusterReference `json:"clusterRef"`
+	// OffsetReset is a one-shot reset of the committed offsets of the group. It is run once, while the group has no
+	// active members; changing it requests a new reset.
+	// +optional
+	OffsetReset *ConsumerGroupOffsetReset `json:"offsetReset,omitempty"`
+}
+
+// ConsumerGroupOffsetReset defines how the committed offsets of the consumer group are reset
+type ConsumerGroupOffsetReset struct {
+	// +kubebuilder:validation:Enum=to-earliest;to-latest;to-timestamp;shift-by
+	Strategy OffsetResetStrategy `json:"strategy"`
+	// Topics whose offsets are reset, all the topics the group has committed offsets for are reset when empty
+	// +optional
+	Topics []string `json:"topics,omitempty"`
+	// Timestamp the offsets are reset to with the to-timestamp strategy
+	// +optional
+	Timestamp *metav1.Time `json:"timestamp,omitempty"`
+	// Shift is the number of messages the committed offsets are moved by with the shift-by strategy,
+	// negative values move the offsets backward. The offsets are kept within the range of the partitions.
+	// +optional
+	Shift *int64 `json:"shift,omitempty"`
+}
+
+// Validate checks that the parameters of the reset strategy are set
+func (r *ConsumerGroupOffsetReset) Validate() error {
+	switch r.Strategy {
+	case OffsetResetToEarliest, OffsetResetToLatest:
+	case OffsetResetToTimestamp:
+		if r.Timestamp == nil {
+			return errors.New("timestamp must be set for the to-timestamp strategy")
+		}
+	case OffsetResetShiftBy:
+		if r.Shift == nil {
+			return errors.New("shift must be set for the shift-by strategy")
+		}
+	default:
+		return errors.New("unknown offset reset strategy " + string(r.Strategy))
+	}
+	return nil
+}
+
+// KafkaConsumerGroupStatus defines the observed state of KafkaConsumerGroup
+// +k8s:openapi-gen=true
+type KafkaConsumerGroupStatus struct {
+	// State is the state of the consumer group in Kafka, e.g. Empty, Stable or Dead
+	State string `json:"state,omitempty"`
+	// Members are the active members of the consumer group
+	// +optional
+	Members []ConsumerGroupMember `json:"members,omitempty"`
+	// Lag is the total number of messages the group is behind the end of the partitions it committed offsets for
+	Lag int64 `json:"lag"`
+	// TopicLags is the lag of the group by topic
+	// +optional
+	TopicLags []ConsumerGroupTopicLag `json:"topicLags,omitempty"`
+	// OffsetReset reports the progress of the last requested offset reset
+	// +optional
+	OffsetReset *ConsumerGroupOffsetResetStatus `json:"offsetReset,omitempty"`
+}
+
+// ConsumerGroupMember describes an active member of a consumer group
+type ConsumerGroupMember struct {
+	MemberID   string `json:"memberId"`
+	ClientID   string `json:"clientId,omitempty"`
+	ClientHost string `json:"clientHost,omitempty"`
+	// Assignments are the partitions assigned to the member
+	// +optional
+	Assignments []ConsumerGroupTopicPartitions `json:"assignments,omitempty"`
+}
+
+// ConsumerGroupTopicPartitions lists partitions of a topic
+type ConsumerGroupTopicPartitions struct {
+	Topic      string  `json:"topic"`
+	Partitions []int32 `json:"partitions"`
+}
+
+// ConsumerGroupTopicLag describes the lag of a consumer group on a topic
+type ConsumerGroupTopicLag struct {
+	Topic string `json:"topic"`
+	// Lag is the number of messages the group is behind the end of the partitions of the topic
+	Lag int64 `json:"lag"`
+}
+
+// ConsumerGroupOffsetResetStatus describes the progress of an offset reset
+type ConsumerGroupOffsetResetStatus struct {
+	// Reset is the offset reset the status belongs to
+	Reset ConsumerGroupOffsetReset `json:"reset"`
+	State OffsetResetState         `json:"state"`
+	// Message explains why the reset is pending or failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// CompletedAt is the time when the offsets were committed
+	// +optional
+	CompletedAt string `json:"completedAt,omitempty"`
+}
+
+// IsOffsetResetRequested returns true if the spec requests an offset reset that has not been run yet
+func (g *KafkaConsumerGroup) IsOffsetResetRequested() bool {
+	if g.Spec.OffsetReset == nil {
+		return false
+	}
+	return g.Status.OffsetReset == nil || g.Status.OffsetReset.State == OffsetResetStatePending ||
+		!reflect.DeepEqual(*g.Spec.OffsetReset, g.Status.OffsetReset.Reset)
+}
+
+// KafkaConsumerGroup is the Schema for the kafkaconsumergroups API
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=".spec.name",name="Group",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.state",name="State",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.lag",name="Lag",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".status.offsetReset.state",name="Offset reset",type="string"
+// +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name="Age",type="date"
+type KafkaConsumerGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaConsumerGroupSpec   `json:"spec,omitempty"`
+	Status KafkaConsumerGroupStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KafkaConsumerGroupList contains a list of KafkaConsumerGroup
+type KafkaConsumerGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaConsumerGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaConsumerGroup{}, &KafkaConsumerGroupList{})
+}
@@ -16,6 +16,8 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
 )
 
 const (
@@ -27,14 +29,107 @@ const (
 // +k8s:openapi-gen=true
 type KafkaTopicSpec struct {
 	Name string `json:"name"`
-	// Partitions defines the desired number of partitions; must be positive, or -1 to signify using the broker's default
+	// Partitions defines the desired number of partitions; must be positive, or -1 to signify using the broker's default.
+	// It can be omitted when the topic class defines it.
 	// +kubebuilder:validation:Minimum=-1
-	Partitions int32 `json:"partitions"`
-	// ReplicationFactor defines the desired replication factor; must be positive, or -1 to signify using the broker's default
+	// +optional
+	Partitions int32 `json:"partitions,omitempty"`
+	// ReplicationFactor defines the desired replication factor; must be positive, or -1 to signify using the broker's default.
+	// It can be omitted when the topic class defines it.
 	// +kubebuilder:validation:Minimum=-1
-	ReplicationFactor int32             `json:"replicationFactor"`
+	// +optional
+	ReplicationFactor int32             `json:"replicationFactor,omitempty"`
 	Config            map[string]string `json:"config,omitempty"`
 	ClusterRef        ClusterReference  `json:"clusterRef"`
+	// TopicClassName is the name of the KafkaTopicClass supplying the default partitions, replication factor and config of the topic
+	// +optional
+	TopicClassName string `json:"topicClassName,omitempty"`
+	// DriftPolicy defines how the changes made to the Kafka topic outside of the KafkaTopic are handled.
+	// With "Enforce" the Kafka topic is changed back to match the KafkaTopic, with "Detect" the differences
+	// are only reported in the status so they can be audited before being enforced.
+	// +kubebuilder:validation:Enum=Enforce;Detect
+	// +kubebuilder:default=Enforce
+	// +optional
+	DriftPolicy TopicDriftPolicy `json:"driftPolicy,omitempty"`
+	// DeletionPolicy defines whether the Kafka topic is deleted or retained when the KafkaTopic is deleted.
+	// If not set, the topic deletion policy of the KafkaCluster is used.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy v1beta1.TopicDeletionPolicy `json:"deletionPolicy,omitempty"`
+	// Placement constrains the brokers the replicas of the topic are placed on. Replicas violating the
+	// constraints are reassigned when the placement is changed.
+	// +optional
+	Placement *TopicPlacement `json:"placement,omitempty"`
+}
+
+// TopicPlacement defines the brokers the replicas of a topic can be placed on, either by restricting the
+// set of brokers and racks the replicas are spread across, or by an explicit replica assignment
+type TopicPlacement struct {
+	// Brokers lists the ids of the brokers the replicas can be placed on, all brokers are allowed when empty
+	// +optional
+	Brokers []int32 `json:"brokers,omitempty"`
+	// Racks lists the racks (the broker.rack of the brokers) the replicas can be placed in, all racks are allowed when empty
+	// +optional
+	Racks []string `json:"racks,omitempty"`
+	// ReplicaAssignment is the list of replica broker ids of every partition indexed by the partition id,
+	// the first replica being the preferred leader. It cannot be used together with brokers and racks.
+	// +optional
+	ReplicaAssignment [][]int32 `json:"replicaAssignment,omitempty"`
+}
+
+// IsConstrained returns true if the placement restricts the brokers or racks of the replicas
+func (p *TopicPlacement) IsConstrained() bool {
+	return p != nil && (len(p.Brokers) > 0 || len(p.Racks) > 0)
+}
+
+// HasReplicaAssignment returns true if the placement defines an explicit replica assignment
+func (p *TopicPlacement) HasReplicaAssignment() bool {
+	return p != nil && len(p.ReplicaAssignment) > 0
+}
+
+// WithTopicClass returns the spec with the partitions, replication factor and config not set in it taken from the topic class
+func (spec KafkaTopicSpec) WithTopicClass(class *KafkaTopicClassSpec) KafkaTopicSpec {
+	if class == nil {
+		return spec
+	}
+	merged := *spec.DeepCopy()
+	if merged.Partitions == 0 {
+		merged.Partitions = class.Partitions
+	}
+	if merged.ReplicationFactor == 0 {
+		merged.ReplicationFactor = class.ReplicationFactor
+	}
+	if len(class.Config) > 0 {
+		config := make(map[string]string, len(class.Config)+len(spec.Config))
+		for key, value := range class.Config {
+			config[key] = value
+		}
+		for key, value := range spec.Config {
+			config[key] = value
+		}
+		merged.Config = config
+	}
+	return merged
+}
+
+// GetDeletionPolicy returns the deletion policy of the topic, falling back to the one of the KafkaCluster
+// and to Delete if neither is set
+func (spec *KafkaTopicSpec) GetDeletionPolicy(cluster *v1beta1.KafkaCluster) v1beta1.TopicDeletionPolicy {
+	if spec.DeletionPolicy != "" {
+		return spec.DeletionPolicy
+	}
+	if cluster != nil && cluster.Spec.TopicDeletionPolicy != "" {
+		return cluster.Spec.TopicDeletionPolicy
+	}
+	return v1beta1.TopicDeletionPolicyDelete
+}
+
+// GetDriftPolicy returns the drift policy of the topic, which is Enforce if not set
+func (spec *KafkaTopicSpec) GetDriftPolicy() TopicDriftPolicy {
+	if spec.DriftPolicy == "" {
+		return TopicDriftPolicyEnforce
+	}
+	return spec.DriftPolicy
 }
 
 // KafkaTopicStatus defines the observed state of KafkaTopic
@@ -45,15 +140,70 @@ type KafkaTopicStatus struct {
 	// Manager of the Kafka topic can be changed by adding the "managedBy: <manager>" annotation to the KafkaTopic CR.
 	ManagedBy string     `json:"managedBy"`
 	State     TopicState `json:"state"`
+	// Partitions is the actual number of partitions of the topic
+	// +optional
+	Partitions int32 `json:"partitions,omitempty"`
+	// ReplicationFactor is the actual replication factor of the topic
+	// +optional
+	ReplicationFactor int32 `json:"replicationFactor,omitempty"`
+	// UnderReplicatedPartitions is the number of partitions having less in-sync replicas than replicas
+	// +optional
+	UnderReplicatedPartitions int32 `json:"underReplicatedPartitions,omitempty"`
+	// OfflinePartitions is the number of partitions without a leader
+	// +optional
+	OfflinePartitions int32 `json:"offlinePartitions,omitempty"`
+	// SizeBytes is the total size on disk of all the replicas of the topic
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// Drift lists the differences between the KafkaTopic and the Kafka topic when the drift policy is Detect
+	// +optional
+	Drift []TopicDrift `json:"drift,omitempty"`
+	// Conditions describe the observed state of the topic, e.g. whether it drifted from the KafkaTopic
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ReplicationFactorChange holds the progress of the partition reassignment changing the replication factor of the topic
+	// +optional
+	ReplicationFactorChange *ReplicationFactorChangeStatus `json:"replicationFactorChange,omitempty"`
+}
+
+// TopicDrift describes a difference between the KafkaTopic and the Kafka topic
+type TopicDrift struct {
+	// Field is the drifted property of the topic, e.g. "partitions", "replicationFactor" or "config.retention.ms"
+	Field string `json:"field"`
+	// Desired is the value set in the KafkaTopic, empty if the config is not set there
+	Desired string `json:"desired,omitempty"`
+	// Actual is the value of the Kafka topic, empty if the config is not set on the topic
+	Actual string `json:"actual,omitempty"`
+}
+
+// ReplicationFactorChangeStatus describes an ongoing replication factor change of a topic
+type ReplicationFactorChangeStatus struct {
+	// TargetReplicationFactor is the replication factor the partitions are reassigned to
+	TargetReplicationFactor int32 `json:"targetReplicationFactor"`
+	// ReassigningPartitions is the number of partitions whose reassignment has not finished yet
+	ReassigningPartitions int32 `json:"reassigningPartitions"`
+	// StartedAt is the time when the partition reassignment was started
+	StartedAt string `json:"startedAt,omitempty"`
 }
 
-// +kubebuilder:webhook:verbs=create;update,path=/validate-kafka-banzaicloud-io-v1alpha1-kafkatopic,mutating=false,failurePolicy=fail,groups=kafka.banzaicloud.io,resources=kafkatopics,versions=v1alpha1,name=kafkatopics.kafka.banzaicloud.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-kafka-banzaicloud-io-v1alpha1-kafkatopic,mutating=false,failurePolicy=fail,groups=kafka.banzaicloud.io,resources=kafkatopics,versions=v1alpha1,name=kafkatopics.kafka.banzaicloud.io,sideEffects=None,admissionReviewVersions=v1
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:openapi-gen=true
 // KafkaTopic is the Schema for the kafkatopics API
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=".spec.name",name="Topic",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.state",name="State",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.partitions",name="Partitions",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".status.replicationFactor",name="Replication factor",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".status.underReplicatedPartitions",name="Under replicated",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".status.offlinePartitions",name="Offline",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".status.sizeBytes",name="Size bytes",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".status.conditions[?(@.type==\"Drifted\")].status",name="Drifted",type="string"
+// +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name="Age",type="date"
 type KafkaTopic struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,125 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestKafkaTopicSpecGetDeletionPolicy(t *testing.T) {
+	t.Parallel()
+	retainCluster := &v1beta1.KafkaCluster{Spec: v1beta1.KafkaClusterSpec{TopicDeletionPolicy: v1beta1.TopicDeletionPolicyRetain}}
+	tests := []struct {
+		name     string
+		spec     KafkaTopicSpec
+		cluster  *v1beta1.KafkaCluster
+		expected v1beta1.TopicDeletionPolicy
+	}{
+		{
+			name:     "default",
+			spec:     KafkaTopicSpec{},
+			cluster:  &v1beta1.KafkaCluster{},
+			expected: v1beta1.TopicDeletionPolicyDelete,
+		},
+		{
+			name:     "cluster is gone",
+			spec:     KafkaTopicSpec{},
+			cluster:  nil,
+			expected: v1beta1.TopicDeletionPolicyDelete,
+		},
+		{
+			name:     "cluster policy",
+			spec:     KafkaTopicSpec{},
+			cluster:  retainCluster,
+			expected: v1beta1.TopicDeletionPolicyRetain,
+		},
+		{
+			name:     "topic policy overrides the cluster policy",
+			spec:     KafkaTopicSpec{DeletionPolicy: v1beta1.TopicDeletionPolicyDelete},
+			cluster:  retainCluster,
+			expected: v1beta1.TopicDeletionPolicyDelete,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.spec.GetDeletionPolicy(tt.cluster), tt.expected)
+		})
+	}
+}
+
+func TestKafkaTopicSpecWithTopicClass(t *testing.T) {
+	t.Parallel()
+	topicClass := &KafkaTopicClassSpec{
+		Partitions:        6,
+		ReplicationFactor: 3,
+		Config:            map[string]string{"retention.ms": "1000", "min.insync.replicas": "2"},
+	}
+	tests := []struct {
+		name     string
+		spec     KafkaTopicSpec
+		class    *KafkaTopicClassSpec
+		expected KafkaTopicSpec
+	}{
+		{
+			name:     "without topic class",
+			spec:     KafkaTopicSpec{Name: "topic", Partitions: 1, ReplicationFactor: 1},
+			class:    nil,
+			expected: KafkaTopicSpec{Name: "topic", Partitions: 1, ReplicationFactor: 1},
+		},
+		{
+			name:  "defaults from the topic class",
+			spec:  KafkaTopicSpec{Name: "topic", TopicClassName: "standard"},
+			class: topicClass,
+			expected: KafkaTopicSpec{
+				Name:              "topic",
+				TopicClassName:    "standard",
+				Partitions:        6,
+				ReplicationFactor: 3,
+				Config:            map[string]string{"retention.ms": "1000", "min.insync.replicas": "2"},
+			},
+		},
+		{
+			name: "topic settings take precedence",
+			spec: KafkaTopicSpec{
+				Name:              "topic",
+				TopicClassName:    "standard",
+				Partitions:        12,
+				ReplicationFactor: -1,
+				Config:            map[string]string{"retention.ms": "2000"},
+			},
+			class: topicClass,
+			expected: KafkaTopicSpec{
+				Name:              "topic",
+				TopicClassName:    "standard",
+				Partitions:        12,
+				ReplicationFactor: -1,
+				Config:            map[string]string{"retention.ms": "2000", "min.insync.replicas": "2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.DeepEqual(t, tt.spec.WithTopicClass(tt.class), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KafkaTopicClassSpec defines the default settings of the KafkaTopics referencing the class
+// +k8s:openapi-gen=true
+type KafkaTopicClassSpec struct {
+	// Partitions defines the default number of partitions; must be positive, or -1 to signify using the broker's default
+	// +kubebuilder:validation:Minimum=-1
+	// +optional
+	Partitions int32 `json:"partitions,omitempty"`
+	// ReplicationFactor defines the default replication factor; must be positive, or -1 to signify using the broker's default
+	// +kubebuilder:validation:Minimum=-1
+	// +optional
+	ReplicationFactor int32 `json:"replicationFactor,omitempty"`
+	// Config defines the default topic configs, the ones set in the KafkaTopic take precedence
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// +kubebuilder:webhook:verbs=update;delete,path=/validate-kafka-banzaicloud-io-v1alpha1-kafkatopicclass,mutating=false,failurePolicy=fail,groups=kafka.banzaicloud.io,resources=kafkatopicclasses,versions=v1alpha1,name=kafkatopicclasses.kafka.banzaicloud.io,sideEffects=None,admissionReviewVersions=v1
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+// KafkaTopicClass is the Schema for the kafkatopicclasses API
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:JSONPath=".spec.partitions",name="Partitions",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".spec.replicationFactor",name="Replication factor",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name="Age",type="date"
+type KafkaTopicClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KafkaTopicClassSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KafkaTopicClassList contains a list of KafkaTopicClass
+type KafkaTopicClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaTopicClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaTopicClass{}, &KafkaTopicClassList{})
+}
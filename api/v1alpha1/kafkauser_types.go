@@ -27,8 +27,14 @@ import (
 const (
 	// default certificate duration if kafkauser.spec.expirationSeconds is not set
 	defaultCertificateDuration = time.Hour * 24 * 90
+	// default percentage of the certificate lifetime after which the certificate is renewed, the same as the
+	// default of cert-manager
+	defaultCertificateRenewalPercentage = 67
 	// CertManagerSignerNamePrefix is acceptable pki backend signerName prefix for cert-manager
 	CertManagerSignerNamePrefix string = "clusterissuers.cert-manager.io"
+	// RotatePasswordAnnotationKey requests a new SCRAM password for the KafkaUser whenever its value is changed,
+	// e.g. to the current timestamp. The secret of the user is annotated with the value of the last rotation.
+	RotatePasswordAnnotationKey = "kafka.banzaicloud.io/rotate-password"
 )
 
 // KafkaUserSpec defines the desired state of KafkaUser
@@ -38,18 +44,45 @@ type KafkaUserSpec struct {
 	SecretName string           `json:"secretName"`
 	ClusterRef ClusterReference `json:"clusterRef"`
 	// Annotations defines the annotations placed on the certificate or certificate signing request object
-	Annotations    map[string]string `json:"annotations,omitempty"`
-	DNSNames       []string          `json:"dnsNames,omitempty"`
-	TopicGrants    []UserTopicGrant  `json:"topicGrants,omitempty"`
-	IncludeJKS     bool              `json:"includeJKS,omitempty"`
-	CreateCert     *bool             `json:"createCert,omitempty"`
-	PKIBackendSpec *PKIBackendSpec   `json:"pkiBackendSpec,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	DNSNames    []string          `json:"dnsNames,omitempty"`
+	TopicGrants []UserTopicGrant  `json:"topicGrants,omitempty"`
+	// acls are the ACLs of the principal of the KafkaUser on any kind of Kafka resource, created in addition to the
+	// ACLs of the topic grants. ACLs bound to the principal of the KafkaUser needed by neither are deleted.
+	// +optional
+	ACLs           []UserACL       `json:"acls,omitempty"`
+	IncludeJKS     bool            `json:"includeJKS,omitempty"`
+	CreateCert     *bool           `json:"createCert,omitempty"`
+	PKIBackendSpec *PKIBackendSpec `json:"pkiBackendSpec,omitempty"`
 	// expirationSeconds is the requested duration of validity of the issued certificate.
 	// The minimum valid value for expirationSeconds is 3600 i.e. 1h.
 	// When it is not specified the default validation duration is 90 days
 	// +optional
 	// +kubebuilder:validation:Minimum=3600
 	ExpirationSeconds *int32 `json:"expirationSeconds,omitempty"`
+	// certificateRenewalPercentage is the percentage of the lifetime of the issued certificate after which it is
+	// renewed. When it is not specified the certificate is renewed after two thirds of its lifetime
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	CertificateRenewalPercentage *int32 `json:"certificateRenewalPercentage,omitempty"`
+	// authentication is the mechanism the KafkaUser authenticates with. With tls a certificate is issued for the user,
+	// with scram-sha-256 or scram-sha-512 a password is generated into the secret and registered as the SCRAM credential
+	// of the user named after the KafkaUser. When it is not specified tls is used
+	// +optional
+	// +kubebuilder:validation:Enum={"tls","scram-sha-256","scram-sha-512"}
+	Authentication UserAuthenticationType `json:"authentication,omitempty"`
+	// quotas are the client quotas applied to the principal of the KafkaUser by the brokers
+	// +optional
+	Quotas *UserQuotas `json:"quotas,omitempty"`
+}
+
+// UserACLChanges are the ACLs created and deleted in Kafka by the last reconciliation changing the ACLs of a KafkaUser
+type UserACLChanges struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	// ChangedAt is the time of the change in RFC3339 format
+	ChangedAt string `json:"changedAt,omitempty"`
 }
 
 type PKIBackendSpec struct {
@@ -67,6 +100,54 @@ type UserTopicGrant struct {
 	AccessType KafkaAccessType `json:"accessType"`
 	// +kubebuilder:validation:Enum={"literal","match","prefixed","any"}
 	PatternType KafkaPatternType `json:"patternType,omitempty"`
+	// consumerGroup is the name of the consumer group a read grant allows to use, or its prefix with the prefixed
+	// consumerGroupPatternType. Every consumer group is allowed when it is not set, unless the KafkaCluster
+	// requires scoped consumer groups.
+	// +optional
+	ConsumerGroup string `json:"consumerGroup,omitempty"`
+	// +kubebuilder:validation:Enum={"literal","prefixed"}
+	// +optional
+	ConsumerGroupPatternType KafkaPatternType `json:"consumerGroupPatternType,omitempty"`
+}
+
+// UserACL is the desired ACL of the KafkaUser on a Kafka resource, an ACL is created for every operation and host
+type UserACL struct {
+	ResourceType KafkaResourceType `json:"resourceType"`
+	// resourceName is the name of the resource, or its prefix with the prefixed pattern type.
+	// It is not needed for the cluster resource.
+	// +optional
+	ResourceName string `json:"resourceName,omitempty"`
+	// +kubebuilder:validation:Enum={"literal","prefixed"}
+	// +optional
+	PatternType KafkaPatternType `json:"patternType,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Operations []KafkaACLOperation `json:"operations"`
+	// permissionType is allow when it is not specified
+	// +optional
+	PermissionType KafkaACLPermissionType `json:"permissionType,omitempty"`
+	// hosts the ACL is restricted to, the ACL applies to every host when it is empty
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// UserQuotas are the client quotas of a KafkaUser, a quota is not enforced for the user when it is not set
+type UserQuotas struct {
+	// producerByteRate is the number of bytes per second the user can produce to a broker
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ProducerByteRate *int64 `json:"producerByteRate,omitempty"`
+	// consumerByteRate is the number of bytes per second the user can consume from a broker
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ConsumerByteRate *int64 `json:"consumerByteRate,omitempty"`
+	// requestPercentage is the percentage of time the user can use the request handler and network threads of a broker
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	RequestPercentage *int32 `json:"requestPercentage,omitempty"`
+	// controllerMutationRate is the number of partitions per second the user can create, add or delete
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ControllerMutationRate *int32 `json:"controllerMutationRate,omitempty"`
 }
 
 // KafkaUserStatus defines the observed state of KafkaUser
@@ -74,8 +155,26 @@ type UserTopicGrant struct {
 type KafkaUserStatus struct {
 	State UserState `json:"state"`
 	ACLs  []string  `json:"acls,omitempty"`
+	// Quotas are the effective client quotas of the user, set for the user or as default for all users
+	Quotas *UserQuotas `json:"quotas,omitempty"`
+	// ACLChanges are the ACLs changed by the last reconciliation changing the ACLs of the user
+	ACLChanges *UserACLChanges `json:"aclChanges,omitempty"`
+	// Certificate is the validity of the certificate issued for the user
+	Certificate *UserCertificateStatus `json:"certificate,omitempty"`
 }
 
+// UserCertificateStatus is the validity of the certificate of a KafkaUser, the times are in RFC3339 format
+type UserCertificateStatus struct {
+	NotBefore string `json:"notBefore,omitempty"`
+	NotAfter  string `json:"notAfter,omitempty"`
+	// RenewalTime is the time the certificate is renewed after
+	RenewalTime string `json:"renewalTime,omitempty"`
+	// LastRenewalFailureTime is the time of the last failed renewal of the certificate
+	LastRenewalFailureTime string `json:"lastRenewalFailureTime,omitempty"`
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-kafka-banzaicloud-io-v1alpha1-kafkauser,mutating=false,failurePolicy=fail,groups=kafka.banzaicloud.io,resources=kafkausers,versions=v1alpha1,name=kafkausers.kafka.banzaicloud.io,sideEffects=None,admissionReviewVersions=v1
+
 // KafkaUser is the Schema for the kafka users API
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:openapi-gen=true
@@ -103,6 +202,9 @@ func init() {
 }
 
 func (spec *KafkaUserSpec) GetIfCertShouldBeCreated() bool {
+	if spec.IsScramAuthentication() {
+		return false
+	}
 	if spec.CreateCert != nil {
 		return *spec.CreateCert
 	}
@@ -133,3 +235,86 @@ func (spec *KafkaUserSpec) GetExpirationSeconds() int32 {
 	}
 	return *spec.ExpirationSeconds
 }
+
+// GetCertificateRenewalPercentage returns the percentage of the certificate lifetime after which it is renewed
+func (spec *KafkaUserSpec) GetCertificateRenewalPercentage() int32 {
+	if spec.CertificateRenewalPercentage == nil {
+		return defaultCertificateRenewalPercentage
+	}
+	return *spec.CertificateRenewalPercentage
+}
+
+// GetCertificateRenewalTime returns the time after which the certificate valid between notBefore and notAfter is renewed
+func (spec *KafkaUserSpec) GetCertificateRenewalTime(notBefore, notAfter time.Time) time.Time {
+	lifetime := notAfter.Sub(notBefore)
+	return notBefore.Add(lifetime / 100 * time.Duration(spec.GetCertificateRenewalPercentage()))
+}
+
+// GetAuthentication returns the authentication mechanism of the user, tls by default
+func (spec *KafkaUserSpec) GetAuthentication() UserAuthenticationType {
+	if spec.Authentication == "" {
+		return UserAuthenticationTLS
+	}
+	return spec.Authentication
+}
+
+// IsScramAuthentication returns true if the user authenticates with a SCRAM password instead of a certificate
+func (spec *KafkaUserSpec) IsScramAuthentication() bool {
+	auth := spec.GetAuthentication()
+	return auth == UserAuthenticationScramSHA256 || auth == UserAuthenticationScramSHA512
+}
+
+// GetConsumerGroup returns the consumer group of the grant, every consumer group by default
+func (grant *UserTopicGrant) GetConsumerGroup() string {
+	if grant.ConsumerGroup == "" {
+		return KafkaAnyConsumerGroup
+	}
+	return grant.ConsumerGroup
+}
+
+// HasScopedConsumerGroup returns whether the grant does not allow every consumer group, only read grants allow
+// consumer groups
+func (grant *UserTopicGrant) HasScopedConsumerGroup() bool {
+	return grant.AccessType != KafkaAccessTypeRead || grant.GetConsumerGroup() != KafkaAnyConsumerGroup
+}
+
+// GetConsumerGroupPatternType returns the pattern type of the consumer group of the grant, literal by default.
+// The pattern type is ignored when the grant allows every consumer group.
+func (grant *UserTopicGrant) GetConsumerGroupPatternType() KafkaPatternType {
+	if grant.ConsumerGroupPatternType == "" || grant.ConsumerGroup == "" {
+		return KafkaPatternTypeDefault
+	}
+	return grant.ConsumerGroupPatternType
+}
+
+// GetResourceName returns the name of the resource of the ACL, the cluster resource has a fixed name
+func (acl *UserACL) GetResourceName() string {
+	if acl.ResourceType == KafkaResourceTypeCluster {
+		return KafkaClusterResourceName
+	}
+	return acl.ResourceName
+}
+
+// GetPatternType returns the pattern type of the ACL, literal by default
+func (acl *UserACL) GetPatternType() KafkaPatternType {
+	if acl.PatternType == "" || acl.ResourceType == KafkaResourceTypeCluster {
+		return KafkaPatternTypeDefault
+	}
+	return acl.PatternType
+}
+
+// GetPermissionType returns the permission type of the ACL, allow by default
+func (acl *UserACL) GetPermissionType() KafkaACLPermissionType {
+	if acl.PermissionType == "" {
+		return KafkaACLPermissionAllow
+	}
+	return acl.PermissionType
+}
+
+// GetHosts returns the hosts the ACL applies to, every host by default
+func (acl *UserACL) GetHosts() []string {
+	if len(acl.Hosts) == 0 {
+		return []string{KafkaACLAnyHost}
+	}
+	return acl.Hosts
+}
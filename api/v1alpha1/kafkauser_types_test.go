@@ -17,6 +17,7 @@ package v1alpha1
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -101,3 +102,116 @@ func TestKafkaUserSpecValidateAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestKafkaUserSpecAuthentication(t *testing.T) {
+	t.Parallel()
+	createCert := true
+	tests := []struct {
+		name                  string
+		spec                  KafkaUserSpec
+		wantedAuthentication  UserAuthenticationType
+		wantedScram           bool
+		wantedCertToBeCreated bool
+	}{
+		{
+			name:                  "tls by default",
+			spec:                  KafkaUserSpec{},
+			wantedAuthentication:  UserAuthenticationTLS,
+			wantedScram:           false,
+			wantedCertToBeCreated: true,
+		},
+		{
+			name:                  "scram-sha-256",
+			spec:                  KafkaUserSpec{Authentication: UserAuthenticationScramSHA256},
+			wantedAuthentication:  UserAuthenticationScramSHA256,
+			wantedScram:           true,
+			wantedCertToBeCreated: false,
+		},
+		{
+			name:                  "scram-sha-512 does not create certificate even if requested",
+			spec:                  KafkaUserSpec{Authentication: UserAuthenticationScramSHA512, CreateCert: &createCert},
+			wantedAuthentication:  UserAuthenticationScramSHA512,
+			wantedScram:           true,
+			wantedCertToBeCreated: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.wantedAuthentication, tt.spec.GetAuthentication())
+			assert.Equal(t, tt.wantedScram, tt.spec.IsScramAuthentication())
+			assert.Equal(t, tt.wantedCertToBeCreated, tt.spec.GetIfCertShouldBeCreated())
+		})
+	}
+}
+
+func TestUserTopicGrantConsumerGroup(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name               string
+		grant              UserTopicGrant
+		wantedGroup        string
+		wantedGroupPattern KafkaPatternType
+	}{
+		{
+			name:               "every consumer group by default",
+			grant:              UserTopicGrant{ConsumerGroupPatternType: KafkaPatternTypePrefixed},
+			wantedGroup:        KafkaAnyConsumerGroup,
+			wantedGroupPattern: KafkaPatternTypeLiteral,
+		},
+		{
+			name:               "literal consumer group",
+			grant:              UserTopicGrant{ConsumerGroup: "billing"},
+			wantedGroup:        "billing",
+			wantedGroupPattern: KafkaPatternTypeLiteral,
+		},
+		{
+			name:               "prefixed consumer group",
+			grant:              UserTopicGrant{ConsumerGroup: "billing-", ConsumerGroupPatternType: KafkaPatternTypePrefixed},
+			wantedGroup:        "billing-",
+			wantedGroupPattern: KafkaPatternTypePrefixed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.wantedGroup, tt.grant.GetConsumerGroup())
+			assert.Equal(t, tt.wantedGroupPattern, tt.grant.GetConsumerGroupPatternType())
+		})
+	}
+}
+
+func TestKafkaUserSpecCertificateRenewalTime(t *testing.T) {
+	t.Parallel()
+	notBefore := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := notBefore.Add(100 * time.Hour)
+	percentage := int32(90)
+	tests := []struct {
+		name              string
+		spec              KafkaUserSpec
+		wantedRenewalTime time.Time
+	}{
+		{
+			name:              "two thirds of the lifetime by default",
+			spec:              KafkaUserSpec{},
+			wantedRenewalTime: notBefore.Add(67 * time.Hour),
+		},
+		{
+			name:              "configured percentage of the lifetime",
+			spec:              KafkaUserSpec{CertificateRenewalPercentage: &percentage},
+			wantedRenewalTime: notBefore.Add(90 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.wantedRenewalTime, tt.spec.GetCertificateRenewalTime(notBefore, notAfter))
+		})
+	}
+}
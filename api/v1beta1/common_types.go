@@ -49,6 +49,15 @@ type SSLClientAuthentication string
 // PerBrokerConfigurationState holds info about the per-broker configuration state
 type PerBrokerConfigurationState string
 
+// LeadershipDrainPhase holds info about the phase of moving the partition leadership away from a broker
+type LeadershipDrainPhase string
+
+// ImageUpgradePhase holds info about the phase of a broker image upgrade
+type ImageUpgradePhase string
+
+// TopicDeletionPolicy defines what happens with the Kafka topic when its KafkaTopic is deleted
+type TopicDeletionPolicy string
+
 // ExternalListenerConfigNames type describes a collection of external listener names
 type ExternalListenerConfigNames []string
 
@@ -60,6 +69,10 @@ type KafkaVersion struct {
 	Image string `json:"image,omitempty"`
 }
 
+// ProcessRole defines the KRaft process role of a Kafka node.
+// Valid values are: broker, controller, combined
+type ProcessRole string
+
 // PKIBackend represents an interface implementing the PKIManager
 type PKIBackend string
 
@@ -239,6 +252,35 @@ type BrokerState struct {
 	Image string `json:"image,omitempty"`
 	// Compressed data from broker configuration to restore broker pod in specific cases
 	ConfigurationBackup string `json:"configurationBackup,omitempty"`
+	// LeadershipDrainState holds info about moving the partition leadership away from the broker during rolling upgrades
+	LeadershipDrainState *LeadershipDrainState `json:"leadershipDrainState,omitempty"`
+	// ImageUpgradeState holds info about the last image upgrade of the broker, used to roll it back if the broker fails to start
+	ImageUpgradeState *ImageUpgradeState `json:"imageUpgradeState,omitempty"`
+}
+
+// ImageUpgradeState holds info about the last image upgrade of a broker
+type ImageUpgradeState struct {
+	// Phase of the image upgrade
+	Phase ImageUpgradePhase `json:"phase"`
+	// PreviousImage is the image the broker ran before the upgrade
+	PreviousImage string `json:"previousImage"`
+	// TargetImage is the image the broker is upgraded to
+	TargetImage string `json:"targetImage"`
+	// StartedAt is the time the broker was restarted with the target image at
+	StartedAt string `json:"startedAt"`
+}
+
+// LeadershipDrainState holds info about moving the partition leadership away from a broker before its pod is deleted
+type LeadershipDrainState struct {
+	// Phase of the leadership drain
+	Phase LeadershipDrainPhase `json:"phase"`
+	// StartedAt is the time the leadership drain was started at
+	StartedAt string `json:"startedAt"`
+	// LeaderCount is the number of partitions the broker led at the last check
+	LeaderCount int `json:"leaderCount"`
+	// DemotedPartitions are the partitions the broker was the preferred replica of when it was demoted by topic,
+	// the broker is moved back to the front of their replicas once it is in sync again after its restart
+	DemotedPartitions map[string][]int32 `json:"demotedPartitions,omitempty"`
 }
 
 const (
@@ -322,6 +364,18 @@ const (
 	// KafkaClusterRunning states that the cluster is in running state
 	KafkaClusterRunning ClusterState = "ClusterRunning"
 
+	// KafkaClusterKRaftMigrationDeployingControllers states that the KRaft controllers are being deployed in migration mode
+	KafkaClusterKRaftMigrationDeployingControllers ClusterState = "KRaftMigrationDeployingControllers"
+	// KafkaClusterKRaftMigrationEnablingBrokers states that the brokers are being rolled with the migration enabled
+	// which makes the KRaft controllers copy the metadata from ZooKeeper
+	KafkaClusterKRaftMigrationEnablingBrokers ClusterState = "KRaftMigrationEnablingBrokers"
+	// KafkaClusterKRaftMigrationMigratingBrokers states that the brokers are being rolled into KRaft mode
+	KafkaClusterKRaftMigrationMigratingBrokers ClusterState = "KRaftMigrationMigratingBrokers"
+	// KafkaClusterKRaftMigrationFinalizing states that the KRaft controllers are being rolled without ZooKeeper
+	KafkaClusterKRaftMigrationFinalizing ClusterState = "KRaftMigrationFinalizing"
+	// KafkaClusterKRaftMigrationCompleted states that the cluster has been migrated to KRaft mode
+	KafkaClusterKRaftMigrationCompleted ClusterState = "KRaftMigrationCompleted"
+
 	// ConfigInSync states that the generated brokerConfig is in sync with the Broker
 	ConfigInSync ConfigurationState = "ConfigInSync"
 	// ConfigOutOfSync states that the generated brokerConfig is out of sync with the Broker
@@ -333,6 +387,25 @@ const (
 	// PerBrokerConfigError states that the generated per-broker brokerConfig can not be set in the Broker
 	PerBrokerConfigError PerBrokerConfigurationState = "PerBrokerConfigError"
 
+	// LeadershipDraining states that the partition leadership is being moved away from the broker before its pod is deleted
+	LeadershipDraining LeadershipDrainPhase = "Draining"
+	// LeadershipDrained states that the broker led zero partitions when its pod was deleted
+	LeadershipDrained LeadershipDrainPhase = "Drained"
+	// LeadershipDrainTimedOut states that the broker pod was deleted while it still led partitions as the drain timed out
+	LeadershipDrainTimedOut LeadershipDrainPhase = "TimedOut"
+
+	// ImageUpgradeInProgress states that the broker has been restarted with a new image which is not ready yet
+	ImageUpgradeInProgress ImageUpgradePhase = "InProgress"
+	// ImageUpgradeSucceeded states that the broker became ready with the new image
+	ImageUpgradeSucceeded ImageUpgradePhase = "Succeeded"
+	// ImageUpgradeRolledBack states that the broker did not become ready with the new image in time and it was rolled back
+	ImageUpgradeRolledBack ImageUpgradePhase = "RolledBack"
+
+	// TopicDeletionPolicyDelete states that the Kafka topic is deleted together with its KafkaTopic
+	TopicDeletionPolicyDelete TopicDeletionPolicy = "Delete"
+	// TopicDeletionPolicyRetain states that the Kafka topic is kept when its KafkaTopic is deleted
+	TopicDeletionPolicyRetain TopicDeletionPolicy = "Retain"
+
 	// SecurityProtocolSSL
 	SecurityProtocolSSL SecurityProtocol = "ssl"
 	// SecurityProtocolPlaintext
@@ -344,4 +417,11 @@ const (
 
 	// SSLClientAuthRequired states that the client authentication is required when SSL is enabled
 	SSLClientAuthRequired SSLClientAuthentication = "required"
+
+	// ProcessRoleBroker states that the node acts only as a data broker in KRaft mode
+	ProcessRoleBroker ProcessRole = "broker"
+	// ProcessRoleController states that the node acts only as a member of the KRaft controller quorum
+	ProcessRoleController ProcessRole = "controller"
+	// ProcessRoleCombined states that the node acts both as a data broker and as a KRaft controller
+	ProcessRoleCombined ProcessRole = "combined"
 )
@@ -42,6 +42,14 @@ const (
 	KafkaCRLabelKey  = "kafka_cr"
 	BrokerIdLabelKey = "brokerId"
 
+	// RestartAnnotationKey requests a rolling restart of the brokers whenever its value is changed on the KafkaCluster,
+	// e.g. to the current timestamp. The restarted broker pods are annotated with the value of the request.
+	RestartAnnotationKey = "kafka.banzaicloud.io/restart"
+	// RestartBrokersAnnotationKey limits the rolling restart to the comma separated list of broker ids
+	RestartBrokersAnnotationKey = "kafka.banzaicloud.io/restart-brokers"
+	// RestartRackAnnotationKey limits the rolling restart to the brokers of the given broker.rack
+	RestartRackAnnotationKey = "kafka.banzaicloud.io/restart-rack"
+
 	// These are default values for API keys
 
 	/* General Config */
@@ -130,6 +138,20 @@ const (
 	// KafkaBrokerPod.spec.initContainer["jmx-exporter"].command
 	// kafkaClusterDeployment.spec.template.spec.initContainer["jmx-exporter"].command
 	defaultMonitorPathToJar = "/jmx_prometheus_javaagent.jar"
+
+	/* Consumer Lag Metrics Config */
+
+	// the collection interval of the consumer lag is kept within these bounds to limit the load on the operator
+	defaultConsumerLagMetricsIntervalSeconds = 60
+	minConsumerLagMetricsIntervalSeconds     = 30
+	maxConsumerLagMetricsIntervalSeconds     = 3600
+
+	/* Topic Discovery Config */
+
+	// the topics are not listed more often than the minimum interval to limit the load on the brokers
+	defaultTopicDiscoveryIntervalSeconds = 300
+	minTopicDiscoveryIntervalSeconds     = 60
+	maxTopicDiscoveryIntervalSeconds     = 86400
 )
 
 // KafkaClusterSpec defines the desired state of KafkaCluster
@@ -138,9 +160,23 @@ type KafkaClusterSpec struct {
 	ListenersConfig        ListenersConfig `json:"listenersConfig"`
 	// Custom ports to expose in the container. Example use case: a custom kafka distribution, that includes an integrated metrics api endpoint
 	AdditionalPorts []corev1.ContainerPort `json:"additionalPorts,omitempty"`
+	// KRaftMode runs the Kafka cluster without ZooKeeper, using the KRaft consensus protocol for metadata management.
+	// When enabled, the internal listener with 'usedForControllerCommunication' set is used as the KRaft controller listener
+	// and the zkAddresses field is ignored.
+	// +kubebuilder:default=false
+	// +optional
+	KRaftMode bool `json:"kRaft,omitempty"`
+	// MigrateToKRaft migrates a running ZooKeeper based cluster to KRaft mode.
+	// Every node has to have its processRole set: the nodes with the controller role are added as the new KRaft controller quorum
+	// while the ones with the broker role are rolled through the migration phases. The brokers must already use
+	// an inter.broker.protocol.version which supports the migration. The progress is reported in status.kRaftMigrationState.
+	// +optional
+	MigrateToKRaft bool `json:"migrateToKRaft,omitempty"`
 	// ZKAddresses specifies the ZooKeeper connection string
 	// in the form hostname:port where host and port are the host and port of a ZooKeeper server.
-	ZKAddresses []string `json:"zkAddresses"`
+	// It is required unless the cluster runs in KRaft mode.
+	// +optional
+	ZKAddresses []string `json:"zkAddresses,omitempty"`
 	// ZKPath specifies the ZooKeeper chroot path as part
 	// of its ZooKeeper connection string which puts its data under some path in the global ZooKeeper namespace.
 	ZKPath                      string                  `json:"zkPath,omitempty"`
@@ -187,6 +223,89 @@ type KafkaClusterSpec struct {
 	// The secret must contain the keystore, truststore jks files and the password for them in base64 encoded format
 	// under the keystore.jks, truststore.jks, password data fields.
 	ClientSSLCertSecret *corev1.LocalObjectReference `json:"clientSSLCertSecret,omitempty"`
+	// TopicDiscovery configures the creation of KafkaTopic resources for the topics of the cluster which are
+	// not managed by any KafkaTopic yet
+	// +optional
+	TopicDiscovery *TopicDiscoveryConfig `json:"topicDiscovery,omitempty"`
+	// TopicDeletionPolicy defines whether the Kafka topics are deleted or retained when their KafkaTopic is deleted,
+	// unless the KafkaTopic sets its own deletion policy
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +kubebuilder:default=Delete
+	// +optional
+	TopicDeletionPolicy TopicDeletionPolicy `json:"topicDeletionPolicy,omitempty"`
+	// ConsumerLagMetrics configures the export of the consumer group lag of the cluster as Prometheus metrics
+	// on the metrics endpoint of the operator
+	// +optional
+	ConsumerLagMetrics *ConsumerLagMetricsConfig `json:"consumerLagMetrics,omitempty"`
+	// RequireScopedConsumerGroups rejects the KafkaUsers of the cluster with read topic grants not restricted to
+	// a consumer group name or prefix, which would allow the users to join every consumer group.
+	// Such grants of the existing KafkaUsers are not applied, a warning event is emitted for them.
+	// +optional
+	RequireScopedConsumerGroups bool `json:"requireScopedConsumerGroups,omitempty"`
+}
+
+// TopicDiscoveryConfig defines how the topics not managed by any KafkaTopic are imported
+type TopicDiscoveryConfig struct {
+	// Enabled turns on the creation of KafkaTopic resources for the topics without one.
+	// The KafkaTopics are created in the namespace of the KafkaCluster with the "managedBy: koperator-discovery"
+	// annotation, so the topics are not changed until the annotation is set to "koperator".
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// ExcludePatterns are regular expressions matching the names of the topics not to be imported.
+	// The internal topics whose name starts with "__" are always excluded.
+	// +optional
+	ExcludePatterns []string `json:"excludePatterns,omitempty"`
+	// IntervalSeconds is the time between two discoveries of the topics of the cluster
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=86400
+	// +kubebuilder:default=300
+	// +optional
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+}
+
+// GetIntervalSeconds returns the discovery interval of the topics within its bounds, 300 seconds if not set
+func (c *TopicDiscoveryConfig) GetIntervalSeconds() int32 {
+	switch {
+	case c.IntervalSeconds == 0:
+		return defaultTopicDiscoveryIntervalSeconds
+	case c.IntervalSeconds < minTopicDiscoveryIntervalSeconds:
+		return minTopicDiscoveryIntervalSeconds
+	case c.IntervalSeconds > maxTopicDiscoveryIntervalSeconds:
+		return maxTopicDiscoveryIntervalSeconds
+	default:
+		return c.IntervalSeconds
+	}
+}
+
+// ConsumerLagMetricsConfig defines which consumer groups the lag is exported for and how often it is collected
+type ConsumerLagMetricsConfig struct {
+	// Enabled turns on the collection of the consumer group lag
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Groups are regular expressions matching the whole id of the consumer groups whose lag is exported,
+	// the lag of the other groups is not collected
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+	// IntervalSeconds is the time between two collections of the lag of the cluster
+	// +kubebuilder:validation:Minimum=30
+	// +kubebuilder:validation:Maximum=3600
+	// +kubebuilder:default=60
+	// +optional
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+}
+
+// GetIntervalSeconds returns the collection interval of the consumer lag within its bounds, 60 seconds if not set
+func (c *ConsumerLagMetricsConfig) GetIntervalSeconds() int32 {
+	switch {
+	case c.IntervalSeconds == 0:
+		return defaultConsumerLagMetricsIntervalSeconds
+	case c.IntervalSeconds < minConsumerLagMetricsIntervalSeconds:
+		return minConsumerLagMetricsIntervalSeconds
+	case c.IntervalSeconds > maxConsumerLagMetricsIntervalSeconds:
+		return maxConsumerLagMetricsIntervalSeconds
+	default:
+		return c.IntervalSeconds
+	}
 }
 
 // KafkaClusterStatus defines the observed state of KafkaCluster
@@ -197,6 +316,49 @@ type KafkaClusterStatus struct {
 	RollingUpgrade           RollingUpgradeStatus     `json:"rollingUpgradeStatus,omitempty"`
 	AlertCount               int                      `json:"alertCount"`
 	ListenerStatuses         ListenerStatuses         `json:"listenerStatuses,omitempty"`
+	// ClusterID is the KRaft cluster ID the broker storage directories are formatted with.
+	// It is generated by the operator once, or taken over from the running cluster when it is migrated from ZooKeeper,
+	// and must not change during the lifetime of the cluster.
+	ClusterID string `json:"clusterID,omitempty"`
+	// KRaftMigrationState holds the current phase of the migration from ZooKeeper to KRaft
+	KRaftMigrationState ClusterState `json:"kRaftMigrationState,omitempty"`
+	// ProtocolVersion is the major.minor Kafka version the inter.broker.protocol.version and log.message.format.version
+	// of the ZooKeeper based brokers are pinned to. It is set from the config of the brokers when their image changes,
+	// raised only once every broker runs a newer Kafka version, and downgrading the brokers below it is not allowed afterwards.
+	// In KRaft mode the metadata.version feature of the cluster is raised instead once every node runs the new Kafka version.
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+	// PreferredLeaderElection holds info about the preferred leader elections run after broker restarts
+	PreferredLeaderElection PreferredLeaderElectionStatus `json:"preferredLeaderElection,omitempty"`
+	// ImageRollback holds info about the broker image upgrade rolled back by the operator
+	ImageRollback *ImageRollbackStatus `json:"imageRollback,omitempty"`
+}
+
+// ImageRollbackStatus holds info about a broker image upgrade rolled back by the operator. The rollout of the failed image
+// is paused while the failed image is set for the brokers in the KafkaCluster spec.
+type ImageRollbackStatus struct {
+	// FailedImage is the image the brokers failed to become ready with
+	FailedImage string `json:"failedImage"`
+	// BrokerImages holds the images the brokers run with instead of the failed image while the rollout is paused
+	BrokerImages map[string]string `json:"brokerImages,omitempty"`
+	// Reason describes why the image upgrade was rolled back
+	Reason string `json:"reason"`
+	// RolledBackAt is the time the image upgrade was rolled back at
+	RolledBackAt string `json:"rolledBackAt"`
+}
+
+// PreferredLeaderElectionStatus holds info about the preferred leader elections run after broker restarts
+type PreferredLeaderElectionStatus struct {
+	// PendingBrokers are the restarted brokers the preferred leader election has not been run for yet,
+	// as they are not back in the ISR of their partitions
+	PendingBrokers []int32 `json:"pendingBrokers,omitempty"`
+	// LastElectionTime is the time the last preferred leader election was run at
+	LastElectionTime string `json:"lastElectionTime,omitempty"`
+	// LastElectedBrokers are the brokers the last preferred leader election was run for
+	LastElectedBrokers []int32 `json:"lastElectedBrokers,omitempty"`
+	// ElectedPartitions is the number of partitions whose leadership was moved to their preferred leader by the last election
+	ElectedPartitions int `json:"electedPartitions,omitempty"`
+	// Error holds the error of the last preferred leader election if it failed for some of the partitions
+	Error string `json:"error,omitempty"`
 }
 
 // RollingUpgradeStatus defines status of rolling upgrade
@@ -205,8 +367,25 @@ type RollingUpgradeStatus struct {
 	// ErrorCount keeps track the number of errors reported by alerts labeled with 'rollingupgrade'.
 	// It's reset once these alerts stop firing.
 	ErrorCount int `json:"errorCount"`
+	// LastRestartRequest holds the value of the last restart annotation whose requested brokers have all been restarted
+	LastRestartRequest string `json:"lastRestartRequest,omitempty"`
+	// BlockedReason explains why the restart of the next broker is blocked by the rolling upgrade health checks,
+	// e.g. the partitions which would become under-min-ISR or leaderless. It's cleared once the rolling upgrade proceeds.
+	BlockedReason string `json:"blockedReason,omitempty"`
 }
 
+// RollingUpgradeStrategy defines the order the brokers are restarted in during a rolling upgrade
+type RollingUpgradeStrategy string
+
+const (
+	// BrokerByBrokerRollingUpgradeStrategy restarts the brokers one by one, or up to
+	// ConcurrentBrokerRestartCountPerRack brokers of the same rack in parallel
+	BrokerByBrokerRollingUpgradeStrategy RollingUpgradeStrategy = "BrokerByBroker"
+	// RackByRackRollingUpgradeStrategy restarts every broker of a rack in parallel and moves on to the next rack
+	// once every replica is back in sync
+	RackByRackRollingUpgradeStrategy RollingUpgradeStrategy = "RackByRack"
+)
+
 // RollingUpgradeConfig defines the desired config of the RollingUpgrade
 type RollingUpgradeConfig struct {
 	// FailureThreshold controls how many failures the cluster can tolerate during a rolling upgrade. Once the number of
@@ -226,6 +405,45 @@ type RollingUpgradeConfig struct {
 	// +kubebuilder:default=1
 	// +optional
 	ConcurrentBrokerRestartCountPerRack int `json:"concurrentBrokerRestartCountPerRack,omitempty"`
+
+	// Strategy controls the order the brokers are restarted in during a rolling upgrade. With the BrokerByBroker strategy
+	// the brokers are restarted one by one, or up to ConcurrentBrokerRestartCountPerRack brokers of the same rack in
+	// parallel. With the RackByRack strategy every broker of a rack (as specified by "broker.rack" in broker read-only
+	// configs, which is set from the RackAwareness labels) is restarted in parallel, and the next rack is restarted only
+	// once every replica is back in sync. ConcurrentBrokerRestartCountPerRack is ignored with the RackByRack strategy,
+	// and FailureThreshold only counts the alerts as the brokers of the restarted rack are expected to be out of sync.
+	// Brokers without "broker.rack" are treated as if they were in racks of their own. Default value is BrokerByBroker.
+	// +kubebuilder:validation:Enum=BrokerByBroker;RackByRack
+	// +optional
+	Strategy RollingUpgradeStrategy `json:"strategy,omitempty"`
+
+	// LeadershipDrainTimeoutSeconds enables moving the partition leadership away from a broker with Cruise Control demote
+	// before its pod is deleted during a rolling upgrade, so that clients do not run into NOT_LEADER errors. The operator
+	// waits until the broker leads zero partitions, or until the timeout elapses, before deleting the pod.
+	// As the demote moves the broker to the end of the replicas, the broker is moved back to the front of the replicas
+	// of its partitions once it is in sync again after the restart. Leadership drain is disabled if it is not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	LeadershipDrainTimeoutSeconds int `json:"leadershipDrainTimeoutSeconds,omitempty"`
+
+	// PreferredLeaderElection enables running preferred leader election for the partitions of the restarted brokers
+	// once they are back in the ISR, so that the partition leadership does not stay skewed towards the brokers
+	// restarted first until auto.leader.rebalance.enable kicks in.
+	// +optional
+	PreferredLeaderElection bool `json:"preferredLeaderElection,omitempty"`
+
+	// ImageRollbackDeadlineSeconds enables the automatic rollback of broker image upgrades. If a broker restarted with a
+	// new image does not become ready within this deadline, it is reverted to the image recorded in its BrokerState, and
+	// the rollout of the new image is paused until the image is changed in the KafkaCluster spec.
+	// Automatic rollback is disabled if it is not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ImageRollbackDeadlineSeconds int `json:"imageRollbackDeadlineSeconds,omitempty"`
+
+	// RollbackWholeRollout reverts every broker already upgraded to the new image as well when an image upgrade is rolled back,
+	// not only the broker that failed to become ready
+	// +optional
+	RollbackWholeRollout bool `json:"rollbackWholeRollout,omitempty"`
 }
 
 // DisruptionBudget defines the configuration for PodDisruptionBudget where the workload is managed by the kafka-operator
@@ -305,6 +523,14 @@ type BrokerConfig struct {
 	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
 	// SecurityContext allows to set security context for the kafka container
 	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	// ProcessRole defines the role of the node when the cluster runs in KRaft mode.
+	// Controller nodes only take part in the metadata quorum, they get no data volumes and no external listeners.
+	// Their first persistent storage is used as their metadata.log.dir, which is kept on an emptyDir storage otherwise.
+	// Broker nodes only serve client traffic, while combined nodes do both. Defaults to combined.
+	// It can only be set when the cluster runs in KRaft mode.
+	// +kubebuilder:validation:Enum=broker;controller;combined
+	// +optional
+	ProcessRole ProcessRole `json:"processRole,omitempty"`
 	// BrokerIngressMapping allows to set specific ingress to a specific broker mappings.
 	// If left empty, all broker will inherit the default one specified under external listeners config
 	// Only used when ExternalListeners.Config is populated
@@ -881,6 +1107,22 @@ func (kSpec *KafkaClusterSpec) GetKubernetesClusterDomain() string {
 	return kSpec.KubernetesClusterDomain
 }
 
+// IsKRaftMode returns true if the Kafka cluster runs without ZooKeeper in KRaft mode
+func (kSpec *KafkaClusterSpec) IsKRaftMode() bool {
+	return kSpec.KRaftMode
+}
+
+// IsKRaftMode returns true if the Kafka cluster runs in KRaft mode, either from the beginning
+// or because its migration from ZooKeeper has been completed
+func (k *KafkaCluster) IsKRaftMode() bool {
+	return k.Spec.IsKRaftMode() || k.Status.KRaftMigrationState == KafkaClusterKRaftMigrationCompleted
+}
+
+// IsKRaftMigrationInProgress returns true if the Kafka cluster is being migrated from ZooKeeper to KRaft mode
+func (k *KafkaCluster) IsKRaftMigrationInProgress() bool {
+	return k.Spec.MigrateToKRaft && !k.IsKRaftMode()
+}
+
 // GetZkPath returns the default "/" ZkPath if not specified otherwise
 func (kSpec *KafkaClusterSpec) GetZkPath() string {
 	const prefix = "/"
@@ -991,6 +1233,29 @@ func (bConfig *BrokerConfig) GetTerminationGracePeriod() int64 {
 	return *bConfig.TerminationGracePeriod
 }
 
+// GetProcessRole returns the KRaft process role of the node, it defaults to combined
+func (bConfig *BrokerConfig) GetProcessRole() ProcessRole {
+	if bConfig == nil || bConfig.ProcessRole == "" {
+		return ProcessRoleCombined
+	}
+	return bConfig.ProcessRole
+}
+
+// IsControllerNode returns true if the node takes part in the KRaft controller quorum
+func (bConfig *BrokerConfig) IsControllerNode() bool {
+	return bConfig.GetProcessRole() != ProcessRoleBroker
+}
+
+// IsBrokerNode returns true if the node serves client traffic and stores data
+func (bConfig *BrokerConfig) IsBrokerNode() bool {
+	return bConfig.GetProcessRole() != ProcessRoleController
+}
+
+// IsControllerOnlyNode returns true if the node is a dedicated KRaft controller
+func (bConfig *BrokerConfig) IsControllerOnlyNode() bool {
+	return bConfig.GetProcessRole() == ProcessRoleController
+}
+
 // GetNodeSelector returns the node selector for cruise control
 func (cConfig *CruiseControlConfig) GetNodeSelector() map[string]string {
 	return cConfig.NodeSelector
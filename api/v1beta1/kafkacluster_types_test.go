@@ -458,3 +458,39 @@ func TestGetBrokerLabels(t *testing.T) {
 		t.Error("Expected:", expected, "Got:", result)
 	}
 }
+
+func TestConsumerLagMetricsIntervalSeconds(t *testing.T) {
+	testCases := []struct {
+		intervalSeconds int32
+		expected        int32
+	}{
+		{intervalSeconds: 0, expected: 60},
+		{intervalSeconds: 10, expected: 30},
+		{intervalSeconds: 120, expected: 120},
+		{intervalSeconds: 7200, expected: 3600},
+	}
+	for _, test := range testCases {
+		config := &ConsumerLagMetricsConfig{IntervalSeconds: test.intervalSeconds}
+		if got := config.GetIntervalSeconds(); got != test.expected {
+			t.Error("Expected:", test.expected, "Got:", got)
+		}
+	}
+}
+
+func TestTopicDiscoveryIntervalSeconds(t *testing.T) {
+	testCases := []struct {
+		intervalSeconds int32
+		expected        int32
+	}{
+		{intervalSeconds: 0, expected: 300},
+		{intervalSeconds: 10, expected: 60},
+		{intervalSeconds: 600, expected: 600},
+		{intervalSeconds: 100000, expected: 86400},
+	}
+	for _, test := range testCases {
+		config := &TopicDiscoveryConfig{IntervalSeconds: test.intervalSeconds}
+		if got := config.GetIntervalSeconds(); got != test.expected {
+			t.Error("Expected:", test.expected, "Got:", got)
+		}
+	}
+}
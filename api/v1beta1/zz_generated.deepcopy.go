@@ -204,6 +204,16 @@ func (in *BrokerState) DeepCopyInto(out *BrokerState) {
 		*out = make(ExternalListenerConfigNames, len(*in))
 		copy(*out, *in)
 	}
+	if in.LeadershipDrainState != nil {
+		in, out := &in.LeadershipDrainState, &out.LeadershipDrainState
+		*out = new(LeadershipDrainState)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageUpgradeState != nil {
+		in, out := &in.ImageUpgradeState, &out.ImageUpgradeState
+		*out = new(ImageUpgradeState)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerState.
@@ -258,6 +268,26 @@ func (in *Config) DeepCopy() *Config {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumerLagMetricsConfig) DeepCopyInto(out *ConsumerLagMetricsConfig) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerLagMetricsConfig.
+func (in *ConsumerLagMetricsConfig) DeepCopy() *ConsumerLagMetricsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumerLagMetricsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContourIngressConfig) DeepCopyInto(out *ContourIngressConfig) {
 	*out = *in
@@ -613,6 +643,43 @@ func (in *GracefulActionState) DeepCopy() *GracefulActionState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageRollbackStatus) DeepCopyInto(out *ImageRollbackStatus) {
+	*out = *in
+	if in.BrokerImages != nil {
+		in, out := &in.BrokerImages, &out.BrokerImages
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageRollbackStatus.
+func (in *ImageRollbackStatus) DeepCopy() *ImageRollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageRollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageUpgradeState) DeepCopyInto(out *ImageUpgradeState) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageUpgradeState.
+func (in *ImageUpgradeState) DeepCopy() *ImageUpgradeState {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageUpgradeState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
 	*out = *in
@@ -896,6 +963,16 @@ func (in *KafkaClusterSpec) DeepCopyInto(out *KafkaClusterSpec) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.TopicDiscovery != nil {
+		in, out := &in.TopicDiscovery, &out.TopicDiscovery
+		*out = new(TopicDiscoveryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConsumerLagMetrics != nil {
+		in, out := &in.ConsumerLagMetrics, &out.ConsumerLagMetrics
+		*out = new(ConsumerLagMetricsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterSpec.
@@ -920,6 +997,12 @@ func (in *KafkaClusterStatus) DeepCopyInto(out *KafkaClusterStatus) {
 	}
 	out.RollingUpgrade = in.RollingUpgrade
 	in.ListenerStatuses.DeepCopyInto(&out.ListenerStatuses)
+	in.PreferredLeaderElection.DeepCopyInto(&out.PreferredLeaderElection)
+	if in.ImageRollback != nil {
+		in, out := &in.ImageRollback, &out.ImageRollback
+		*out = new(ImageRollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterStatus.
@@ -947,6 +1030,37 @@ func (in *KafkaVersion) DeepCopy() *KafkaVersion {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeadershipDrainState) DeepCopyInto(out *LeadershipDrainState) {
+	*out = *in
+	if in.DemotedPartitions != nil {
+		in, out := &in.DemotedPartitions, &out.DemotedPartitions
+		*out = make(map[string][]int32, len(*in))
+		for key, val := range *in {
+			var outVal []int32
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]int32, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeadershipDrainState.
+func (in *LeadershipDrainState) DeepCopy() *LeadershipDrainState {
+	if in == nil {
+		return nil
+	}
+	out := new(LeadershipDrainState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ListenerStatus) DeepCopyInto(out *ListenerStatus) {
 	*out = *in
@@ -1099,6 +1213,31 @@ func (in *NetworkConfig) DeepCopy() *NetworkConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreferredLeaderElectionStatus) DeepCopyInto(out *PreferredLeaderElectionStatus) {
+	*out = *in
+	if in.PendingBrokers != nil {
+		in, out := &in.PendingBrokers, &out.PendingBrokers
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastElectedBrokers != nil {
+		in, out := &in.LastElectedBrokers, &out.LastElectedBrokers
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreferredLeaderElectionStatus.
+func (in *PreferredLeaderElectionStatus) DeepCopy() *PreferredLeaderElectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PreferredLeaderElectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RackAwareness) DeepCopyInto(out *RackAwareness) {
 	*out = *in
@@ -1209,6 +1348,26 @@ func (in *TopicConfig) DeepCopy() *TopicConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicDiscoveryConfig) DeepCopyInto(out *TopicDiscoveryConfig) {
+	*out = *in
+	if in.ExcludePatterns != nil {
+		in, out := &in.ExcludePatterns, &out.ExcludePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicDiscoveryConfig.
+func (in *TopicDiscoveryConfig) DeepCopy() *TopicDiscoveryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicDiscoveryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeState) DeepCopyInto(out *VolumeState) {
 	*out = *in
@@ -0,0 +1,238 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// consumerLagCollectorTick is how often the clusters due for a collection of their consumer lag are looked for
+const consumerLagCollectorTick = 10 * time.Second
+
+var (
+	consumerGroupLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_consumergroup_lag",
+		Help: "Number of messages the consumer group is behind the end of the partitions it committed offsets for",
+	}, []string{"namespace", "kafka_cluster", "consumer_group"})
+	consumerGroupPartitionLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_consumergroup_partition_lag",
+		Help: "Number of messages the consumer group is behind the end of the partition",
+	}, []string{"namespace", "kafka_cluster", "consumer_group", "topic", "partition"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(consumerGroupLag, consumerGroupPartitionLag)
+}
+
+// ConsumerLagCollector implements Runnable, it periodically collects the lag of the allowed consumer groups
+// of the KafkaClusters with consumer lag metrics enabled
+type ConsumerLagCollector struct {
+	Client              client.Client
+	KafkaClientProvider kafkaclient.Provider
+
+	// lastCollected holds the time of the last collection by cluster
+	lastCollected map[types.NamespacedName]time.Time
+	// series holds the series of the consumer lag metrics set by the last collection by cluster
+	series map[types.NamespacedName]consumerLagSeries
+}
+
+// consumerLagSeries are the label values of the consumer lag metrics of a cluster besides the cluster labels
+type consumerLagSeries struct {
+	groups     map[string]struct{}
+	partitions map[partitionLagSeries]struct{}
+}
+
+type partitionLagSeries struct {
+	group     string
+	topic     string
+	partition string
+}
+
+// SetConsumerLagCollectorWithManager creates a new consumer lag collector and adds it to the Manager
+func SetConsumerLagCollectorWithManager(mgr manager.Manager, kafkaClientProvider kafkaclient.Provider) error {
+	return mgr.Add(&ConsumerLagCollector{
+		Client:              mgr.GetClient(),
+		KafkaClientProvider: kafkaClientProvider,
+	})
+}
+
+// NeedLeaderElection makes only the leader operator instance collect the lag
+func (c *ConsumerLagCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start collects the consumer lag until the context is done
+func (c *ConsumerLagCollector) Start(ctx context.Context) error {
+	log := logf.Log.WithName("consumer-lag-collector")
+	ctx = logr.NewContext(ctx, log)
+
+	ticker := time.NewTicker(consumerLagCollectorTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.collect(ctx, time.Now()); err != nil {
+				log.Error(err, "failed to collect consumer lag")
+			}
+		}
+	}
+}
+
+// collect collects the lag of the clusters whose collection interval elapsed since their last collection.
+// The clusters are collected one after the other to bound the number of connections of the operator.
+func (c *ConsumerLagCollector) collect(ctx context.Context, now time.Time) error {
+	log := logr.FromContextOrDiscard(ctx)
+	if c.lastCollected == nil {
+		c.lastCollected = make(map[types.NamespacedName]time.Time)
+	}
+	if c.series == nil {
+		c.series = make(map[types.NamespacedName]consumerLagSeries)
+	}
+
+	clusters := &v1beta1.KafkaClusterList{}
+	if err := c.Client.List(ctx, clusters); err != nil {
+		return errors.WrapIf(err, "failed to list KafkaClusters")
+	}
+
+	enabled := make(map[types.NamespacedName]bool, len(clusters.Items))
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+		config := cluster.Spec.ConsumerLagMetrics
+		if config == nil || !config.Enabled || k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
+			continue
+		}
+		enabled[key] = true
+		if now.Sub(c.lastCollected[key]) < time.Duration(config.GetIntervalSeconds())*time.Second {
+			continue
+		}
+		c.lastCollected[key] = now
+		if err := c.collectCluster(ctx, cluster); err != nil {
+			log.Error(err, "failed to collect consumer lag of the cluster", "namespace", cluster.Namespace, "kafkaCluster", cluster.Name)
+		}
+	}
+
+	// The metrics of the clusters removed or without consumer lag metrics are not kept
+	for key := range c.lastCollected {
+		if !enabled[key] {
+			deleteConsumerLagMetrics(key)
+			delete(c.lastCollected, key)
+			delete(c.series, key)
+		}
+	}
+	return nil
+}
+
+// collectCluster sets the consumer lag metrics of the cluster to the lag of its groups allowed by the config.
+// The series are updated in place, only the ones not set by this collection are removed.
+func (c *ConsumerLagCollector) collectCluster(ctx context.Context, cluster *v1beta1.KafkaCluster) error {
+	groupPatterns := make([]*regexp.Regexp, 0, len(cluster.Spec.ConsumerLagMetrics.Groups))
+	for _, pattern := range cluster.Spec.ConsumerLagMetrics.Groups {
+		groupPattern, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "invalid consumer group pattern", "pattern", pattern)
+		}
+		groupPatterns = append(groupPatterns, groupPattern)
+	}
+
+	broker, close, err := c.KafkaClientProvider.NewFromCluster(c.Client, cluster)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	groups, err := broker.ListConsumerGroups()
+	if err != nil {
+		return err
+	}
+	lags := make(map[string]map[string]map[int32]int64)
+	for _, group := range groups {
+		if !isConsumerGroupAllowed(group, groupPatterns) {
+			continue
+		}
+		lag, err := broker.ConsumerGroupLag(group)
+		if err != nil {
+			logr.FromContextOrDiscard(ctx).Info("failed to get the lag of the consumer group", "group", group, "error", err.Error())
+			continue
+		}
+		lags[group] = lag
+	}
+
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	series := consumerLagSeries{
+		groups:     make(map[string]struct{}, len(lags)),
+		partitions: make(map[partitionLagSeries]struct{}),
+	}
+	for group, topics := range lags {
+		var groupLag int64
+		for topic, partitions := range topics {
+			for partition, lag := range partitions {
+				groupLag += lag
+				partitionSeries := partitionLagSeries{group: group, topic: topic, partition: strconv.Itoa(int(partition))}
+				consumerGroupPartitionLag.WithLabelValues(key.Namespace, key.Name, group, topic, partitionSeries.partition).Set(float64(lag))
+				series.partitions[partitionSeries] = struct{}{}
+			}
+		}
+		consumerGroupLag.WithLabelValues(key.Namespace, key.Name, group).Set(float64(groupLag))
+		series.groups[group] = struct{}{}
+	}
+
+	// The groups and partitions without lag anymore are not kept
+	for group := range c.series[key].groups {
+		if _, ok := series.groups[group]; !ok {
+			consumerGroupLag.DeleteLabelValues(key.Namespace, key.Name, group)
+		}
+	}
+	for partitionSeries := range c.series[key].partitions {
+		if _, ok := series.partitions[partitionSeries]; !ok {
+			consumerGroupPartitionLag.DeleteLabelValues(key.Namespace, key.Name, partitionSeries.group, partitionSeries.topic, partitionSeries.partition)
+		}
+	}
+	c.series[key] = series
+	return nil
+}
+
+func isConsumerGroupAllowed(group string, groupPatterns []*regexp.Regexp) bool {
+	for _, groupPattern := range groupPatterns {
+		if groupPattern.MatchString(group) {
+			return true
+		}
+	}
+	return false
+}
+
+func deleteConsumerLagMetrics(cluster types.NamespacedName) {
+	labels := prometheus.Labels{"namespace": cluster.Namespace, "kafka_cluster": cluster.Name}
+	consumerGroupLag.DeletePartialMatch(labels)
+	consumerGroupPartitionLag.DeletePartialMatch(labels)
+}
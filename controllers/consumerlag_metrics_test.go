@@ -0,0 +1,107 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka/mocks"
+)
+
+func gaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	metric := &dto.Metric{}
+	if err := gauge.Write(metric); err != nil {
+		t.Fatal("Expected no error writing the metric, got:", err)
+	}
+	return metric.GetGauge().GetValue()
+}
+
+func seriesCount(collector prometheus.Collector) int {
+	ch := make(chan prometheus.Metric, 100)
+	collector.Collect(ch)
+	close(ch)
+	return len(ch)
+}
+
+func TestConsumerLagCollector(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "lag-cluster", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ConsumerLagMetrics: &v1beta1.ConsumerLagMetricsConfig{
+				Enabled:         true,
+				Groups:          []string{"orders-.*"},
+				IntervalSeconds: 60,
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	_ = v1beta1.AddToScheme(scheme)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+	broker := mocks.NewMockKafkaClient(gomock.NewController(t))
+	broker.EXPECT().ListConsumerGroups().Return([]string{"orders-consumer", "payments-consumer", "orders"}, nil).Times(1)
+	broker.EXPECT().ConsumerGroupLag("orders-consumer").Return(map[string]map[int32]int64{"orders": {0: 3, 1: 4}}, nil).Times(1)
+
+	provider := new(kafkaclient.MockedProvider)
+	provider.On("NewFromCluster", k8sClient, mock.Anything).Return(broker, func() {}, nil)
+
+	collector := &ConsumerLagCollector{Client: k8sClient, KafkaClientProvider: provider}
+	now := time.Now()
+	if err := collector.collect(context.Background(), now); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	assert.Equal(t, float64(7), gaugeValue(t, consumerGroupLag.WithLabelValues("kafka", "lag-cluster", "orders-consumer")))
+	assert.Equal(t, float64(4), gaugeValue(t, consumerGroupPartitionLag.WithLabelValues("kafka", "lag-cluster", "orders-consumer", "orders", "1")))
+	assert.Equal(t, 1, seriesCount(consumerGroupLag))
+
+	// The cluster is not collected again before its interval elapses
+	if err := collector.collect(context.Background(), now.Add(30*time.Second)); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+
+	// The series are updated in place, only the partitions without lag anymore are removed
+	broker.EXPECT().ListConsumerGroups().Return([]string{"orders-consumer"}, nil).Times(1)
+	broker.EXPECT().ConsumerGroupLag("orders-consumer").Return(map[string]map[int32]int64{"orders": {0: 2}}, nil).Times(1)
+	if err := collector.collect(context.Background(), now.Add(time.Minute)); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	assert.Equal(t, float64(2), gaugeValue(t, consumerGroupLag.WithLabelValues("kafka", "lag-cluster", "orders-consumer")))
+	assert.Equal(t, 1, seriesCount(consumerGroupLag))
+	assert.Equal(t, 1, seriesCount(consumerGroupPartitionLag))
+
+	// The metrics are removed once the consumer lag metrics are disabled
+	cluster.Spec.ConsumerLagMetrics.Enabled = false
+	if err := k8sClient.Update(context.Background(), cluster); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if err := collector.collect(context.Background(), now.Add(time.Hour)); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	assert.Equal(t, 0, seriesCount(consumerGroupLag))
+	assert.Equal(t, 0, seriesCount(consumerGroupPartitionLag))
+}
@@ -150,6 +150,11 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, request ctrl.Req
 				return ctrl.Result{
 					RequeueAfter: time.Duration(15) * time.Second,
 				}, nil
+			case errors.As(err, &errorfactory.ReconcileKRaftMigration{}):
+				log.Info("KRaft migration in progress", "error", err.Error())
+				return ctrl.Result{
+					RequeueAfter: time.Duration(15) * time.Second,
+				}, nil
 			case errors.As(err, &errorfactory.CruiseControlNotReady{}):
 				return ctrl.Result{
 					RequeueAfter: time.Duration(15) * time.Second,
@@ -392,7 +397,8 @@ func SetupKafkaClusterWithManager(mgr ctrl.Manager) *ctrl.Builder {
 					if !reflect.DeepEqual(oldObj.Spec, newObj.Spec) ||
 						oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
 						oldObj.GetGeneration() != newObj.GetGeneration() ||
-						!reflect.DeepEqual(oldObj.Status.BrokersState, newObj.Status.BrokersState) {
+						!reflect.DeepEqual(oldObj.Status.BrokersState, newObj.Status.BrokersState) ||
+						isRestartRequestChanged(oldObj, newObj) {
 						return true
 					}
 					return false
@@ -404,6 +410,16 @@ func SetupKafkaClusterWithManager(mgr ctrl.Manager) *ctrl.Builder {
 	return builder
 }
 
+// isRestartRequestChanged returns true if the rolling restart requested through the annotations of the KafkaCluster has changed
+func isRestartRequestChanged(oldObj, newObj *v1beta1.KafkaCluster) bool {
+	for _, key := range []string{v1beta1.RestartAnnotationKey, v1beta1.RestartBrokersAnnotationKey, v1beta1.RestartRackAnnotationKey} {
+		if oldObj.GetAnnotations()[key] != newObj.GetAnnotations()[key] {
+			return true
+		}
+	}
+	return false
+}
+
 func kafkaWatches(builder *ctrl.Builder) *ctrl.Builder {
 	return builder.
 		Owns(&corev1.Service{}).
@@ -0,0 +1,199 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// consumerGroupStatusSyncSeconds is the interval of refreshing the members and lag of the consumer groups
+const consumerGroupStatusSyncSeconds = 30
+
+// consumerGroupOffsetResetRequeueSeconds is the interval of checking whether a consumer group with a pending
+// offset reset became empty
+const consumerGroupOffsetResetRequeueSeconds = 10
+
+// SetupKafkaConsumerGroupWithManager registers kafka consumer group controller with manager
+func SetupKafkaConsumerGroupWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.KafkaConsumerGroup{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("KafkaConsumerGroup")
+}
+
+// blank assignment to verify that KafkaConsumerGroupReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &KafkaConsumerGroupReconciler{}
+
+// KafkaConsumerGroupReconciler reconciles a KafkaConsumerGroup object
+type KafkaConsumerGroupReconciler struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	Client client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaconsumergroups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaconsumergroups/status,verbs=get;update;patch
+
+// Reconcile runs the requested offset reset of the consumer group and reports its members and lag
+func (r *KafkaConsumerGroupReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := logr.FromContextOrDiscard(ctx)
+	reqLogger.Info("Reconciling KafkaConsumerGroup")
+	var err error
+
+	// Fetch the KafkaConsumerGroup instance
+	instance := &v1alpha1.KafkaConsumerGroup{}
+	if err = r.Client.Get(ctx, request.NamespacedName, instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(reqLogger, err.Error(), err)
+	}
+
+	// The consumer group is left in Kafka when the KafkaConsumerGroup is deleted
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	// Get the referenced kafkacluster
+	clusterNamespace := getClusterRefNamespace(instance.Namespace, instance.Spec.ClusterRef)
+	cluster, err := k8sutil.LookupKafkaCluster(ctx, r.Client, instance.Spec.ClusterRef.Name, clusterNamespace)
+	if err != nil {
+		return requeueWithError(reqLogger, "failed to lookup referenced cluster", err)
+	}
+
+	// Get a kafka connection
+	broker, close, err := newKafkaFromCluster(r.Client, cluster)
+	if err != nil {
+		return checkBrokerConnectionError(reqLogger, err)
+	}
+	defer close()
+
+	if instance.IsOffsetResetRequested() {
+		if err = r.resetOffsets(ctx, broker, instance); err != nil {
+			return requeueWithError(reqLogger, "failed to reset consumer group offsets", err)
+		}
+	}
+
+	if err = r.syncConsumerGroupStatus(ctx, broker, instance); err != nil {
+		return requeueWithError(reqLogger, "failed to sync kafkaconsumergroup status", err)
+	}
+
+	if instance.IsOffsetResetRequested() {
+		reqLogger.Info("Waiting for the consumer group to become empty to reset its offsets")
+		return requeueAfter(consumerGroupOffsetResetRequeueSeconds)
+	}
+
+	// The status is refreshed periodically since the members and lag change without the KafkaConsumerGroup being changed
+	return requeueAfter(consumerGroupStatusSyncSeconds)
+}
+
+// resetOffsets runs the offset reset of the spec and records its result in the status. The reset is left pending
+// while the consumer group has active members.
+func (r *KafkaConsumerGroupReconciler) resetOffsets(ctx context.Context, broker kafkaclient.KafkaClient, group *v1alpha1.KafkaConsumerGroup) error {
+	reset := group.Spec.OffsetReset
+	status := &v1alpha1.ConsumerGroupOffsetResetStatus{Reset: *reset.DeepCopy()}
+
+	if err := reset.Validate(); err != nil {
+		status.State = v1alpha1.OffsetResetStateFailed
+		status.Message = err.Error()
+	} else if offsets, err := broker.ResetConsumerGroupOffsets(group.Spec.Name, reset); err != nil {
+		if !errors.As(err, &errorfactory.ConsumerGroupNotEmpty{}) {
+			return err
+		}
+		status.State = v1alpha1.OffsetResetStatePending
+		status.Message = err.Error()
+	} else {
+		status.State = v1alpha1.OffsetResetStateCompleted
+		status.CompletedAt = time.Now().Format(time.RFC3339)
+		logr.FromContextOrDiscard(ctx).Info("Reset consumer group offsets", "strategy", reset.Strategy, "offsets", offsets)
+	}
+
+	if reflect.DeepEqual(status, group.Status.OffsetReset) {
+		return nil
+	}
+	group.Status.OffsetReset = status
+	return r.Client.Status().Update(ctx, group)
+}
+
+// syncConsumerGroupStatus updates the status with the state, members and lag of the consumer group
+func (r *KafkaConsumerGroupReconciler) syncConsumerGroupStatus(ctx context.Context, broker kafkaclient.KafkaClient, group *v1alpha1.KafkaConsumerGroup) error {
+	description, err := broker.DescribeConsumerGroup(group.Spec.Name)
+	if err != nil {
+		return err
+	}
+
+	status := group.Status.DeepCopy()
+	status.State = description.State
+	status.Members, status.Lag, status.TopicLags = consumerGroupMembersAndLag(description)
+
+	if reflect.DeepEqual(*status, group.Status) {
+		return nil
+	}
+	group.Status = *status
+	return r.Client.Status().Update(ctx, group)
+}
+
+// consumerGroupMembersAndLag converts the description of the consumer group to the members, total lag and lag by topic
+// reported in the status, sorted by member id and topic
+func consumerGroupMembersAndLag(description *kafkaclient.ConsumerGroupDescription) ([]v1alpha1.ConsumerGroupMember, int64, []v1alpha1.ConsumerGroupTopicLag) {
+	var members []v1alpha1.ConsumerGroupMember
+	for _, member := range description.Members {
+		statusMember := v1alpha1.ConsumerGroupMember{
+			MemberID:   member.MemberID,
+			ClientID:   member.ClientID,
+			ClientHost: member.ClientHost,
+		}
+		for topic, partitions := range member.Assignment {
+			sorted := append([]int32(nil), partitions...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			statusMember.Assignments = append(statusMember.Assignments, v1alpha1.ConsumerGroupTopicPartitions{Topic: topic, Partitions: sorted})
+		}
+		sort.Slice(statusMember.Assignments, func(i, j int) bool { return statusMember.Assignments[i].Topic < statusMember.Assignments[j].Topic })
+		members = append(members, statusMember)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].MemberID < members[j].MemberID })
+
+	var totalLag int64
+	var topicLags []v1alpha1.ConsumerGroupTopicLag
+	for topic, partitions := range description.Lag {
+		var lag int64
+		for _, partitionLag := range partitions {
+			lag += partitionLag
+		}
+		totalLag += lag
+		topicLags = append(topicLags, v1alpha1.ConsumerGroupTopicLag{Topic: topic, Lag: lag})
+	}
+	sort.Slice(topicLags, func(i, j int) bool { return topicLags[i].Topic < topicLags[j].Topic })
+	return members, totalLag, topicLags
+}
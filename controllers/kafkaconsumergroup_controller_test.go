@@ -0,0 +1,134 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka/mocks"
+)
+
+func TestConsumerGroupMembersAndLag(t *testing.T) {
+	description := &kafkaclient.ConsumerGroupDescription{
+		State: "Stable",
+		Members: []kafkaclient.ConsumerGroupMember{
+			{MemberID: "consumer-2", ClientID: "client", Assignment: map[string][]int32{"orders": {3, 1}}},
+			{MemberID: "consumer-1", ClientID: "client", Assignment: map[string][]int32{"payments": {0}, "orders": {0, 2}}},
+		},
+		Lag: map[string]map[int32]int64{
+			"payments": {0: 7},
+			"orders":   {0: 1, 1: 2, 2: 0, 3: 4},
+		},
+	}
+
+	members, lag, topicLags := consumerGroupMembersAndLag(description)
+	assert.Equal(t, []v1alpha1.ConsumerGroupMember{
+		{
+			MemberID: "consumer-1",
+			ClientID: "client",
+			Assignments: []v1alpha1.ConsumerGroupTopicPartitions{
+				{Topic: "orders", Partitions: []int32{0, 2}},
+				{Topic: "payments", Partitions: []int32{0}},
+			},
+		},
+		{
+			MemberID:    "consumer-2",
+			ClientID:    "client",
+			Assignments: []v1alpha1.ConsumerGroupTopicPartitions{{Topic: "orders", Partitions: []int32{1, 3}}},
+		},
+	}, members)
+	assert.Equal(t, int64(14), lag)
+	assert.Equal(t, []v1alpha1.ConsumerGroupTopicLag{{Topic: "orders", Lag: 7}, {Topic: "payments", Lag: 7}}, topicLags)
+}
+
+func TestResetConsumerGroupOffsets(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		reset         v1alpha1.ConsumerGroupOffsetReset
+		resetErr      error
+		expectedState v1alpha1.OffsetResetState
+		expectedErr   bool
+	}{
+		{
+			testName:      "offsets reset",
+			reset:         v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetToEarliest},
+			expectedState: v1alpha1.OffsetResetStateCompleted,
+		},
+		{
+			testName:      "group has active members",
+			reset:         v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetToLatest},
+			resetErr:      errorfactory.New(errorfactory.ConsumerGroupNotEmpty{}, errors.New("consumer group is Stable"), "not empty"),
+			expectedState: v1alpha1.OffsetResetStatePending,
+		},
+		{
+			testName:      "missing timestamp",
+			reset:         v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetToTimestamp},
+			expectedState: v1alpha1.OffsetResetStateFailed,
+		},
+		{
+			testName:    "broker error",
+			reset:       v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetToEarliest},
+			resetErr:    errors.New("coordinator not available"),
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			group := &v1alpha1.KafkaConsumerGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-group", Namespace: "kafka"},
+				Spec: v1alpha1.KafkaConsumerGroupSpec{
+					Name:        "test-group",
+					ClusterRef:  v1alpha1.ClusterReference{Name: "kafka"},
+					OffsetReset: test.reset.DeepCopy(),
+				},
+			}
+			scheme := runtime.NewScheme()
+			_ = v1alpha1.AddToScheme(scheme)
+			r := KafkaConsumerGroupReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(group).WithStatusSubresource(group).Build(),
+				Scheme: scheme,
+			}
+
+			broker := mocks.NewMockKafkaClient(gomock.NewController(t))
+			if test.reset.Validate() == nil {
+				broker.EXPECT().ResetConsumerGroupOffsets("test-group", gomock.Any()).
+					Return(map[string]map[int32]int64{"test-topic": {0: 0}}, test.resetErr)
+			}
+
+			err := r.resetOffsets(context.Background(), broker, group)
+			if test.expectedErr {
+				assert.Error(t, err)
+				assert.True(t, group.IsOffsetResetRequested())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedState, group.Status.OffsetReset.State)
+			assert.Equal(t, test.expectedState == v1alpha1.OffsetResetStatePending, group.IsOffsetResetRequested())
+		})
+	}
+}
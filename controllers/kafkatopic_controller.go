@@ -20,14 +20,18 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
+	"github.com/IBM/sarama"
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
@@ -40,6 +44,13 @@ import (
 
 var topicFinalizer = "finalizer.kafkatopics.kafka.banzaicloud.io"
 
+// topicStatusSyncSeconds is the interval of refreshing the status of the topics, it is kept long
+// since the size of the topic is determined by describing the log dirs of every broker
+const topicStatusSyncSeconds = 300
+
+// topicReassignmentRequeueSeconds is the interval of checking the progress of an ongoing partition reassignment
+const topicReassignmentRequeueSeconds = 15
+
 func isTopicManagedByKoperator(topic metav1.Object) bool {
 	if managedByAnnotation, hasManagedByAnnotation := topic.GetAnnotations()[webhooks.TopicManagedByAnnotationKey]; hasManagedByAnnotation {
 		return strings.ToLower(managedByAnnotation) == webhooks.TopicManagedByKoperatorAnnotationValue
@@ -51,6 +62,7 @@ func isTopicManagedByKoperator(topic metav1.Object) bool {
 func SetupKafkaTopicWithManager(mgr ctrl.Manager, maxConcurrentReconciles int) *ctrl.Builder {
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.KafkaTopic{}).
+		Watches(&v1alpha1.KafkaTopicClass{}, handler.EnqueueRequestsFromMapFunc(topicClassMapper{client: mgr.GetClient()}.mapToKafkaTopics)).
 		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
 		Named("KafkaTopic")
 	builder.WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles})
@@ -58,6 +70,29 @@ func SetupKafkaTopicWithManager(mgr ctrl.Manager, maxConcurrentReconciles int) *
 	return builder
 }
 
+type topicClassMapper struct {
+	client client.Reader
+}
+
+// mapToKafkaTopics maps KafkaTopicClass events to reconcile events of the KafkaTopics referencing the class
+func (m topicClassMapper) mapToKafkaTopics(ctx context.Context, obj client.Object) []ctrl.Request {
+	kafkaTopics := &v1alpha1.KafkaTopicList{}
+	if err := m.client.List(ctx, kafkaTopics); err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "couldn't list KafkaTopics of the topic class", "topicClass", obj.GetName())
+		return nil
+	}
+	var requests []ctrl.Request
+	for _, kafkaTopic := range kafkaTopics.Items {
+		if kafkaTopic.Spec.TopicClassName == obj.GetName() {
+			requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+				Namespace: kafkaTopic.Namespace,
+				Name:      kafkaTopic.Name,
+			}})
+		}
+	}
+	return requests
+}
+
 // blank assignment to verify that KafkaTopicReconciler implements reconcile.KafkaTopicReconciler
 var _ reconcile.Reconciler = &KafkaTopicReconciler{}
 
@@ -67,11 +102,15 @@ type KafkaTopicReconciler struct {
 	// that reads objects from the cache and writes to the apiserver
 	Client client.Client
 	Scheme *runtime.Scheme
+
+	// topicSizes are shared by the topics of a cluster
+	topicSizes topicSizeCache
 }
 
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkatopics,verbs=get;list;watch;create;update;patch;delete;deletecollection
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkatopics/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkatopics/finalizers,verbs=create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkatopicclasses,verbs=get;list;watch
 
 // Reconcile reconciles the kafka topic
 func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -129,7 +168,7 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 
 	// Check if marked for deletion and if so run finalizers
 	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
-		return r.checkFinalizers(ctx, broker, instance)
+		return r.checkFinalizers(ctx, broker, cluster, instance)
 	}
 
 	// No need to do anything when the kafka topic is not managed by Koperator
@@ -138,6 +177,12 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 		return reconciled()
 	}
 
+	// The partitions, replication factor and config not set in the KafkaTopic are taken from its topic class
+	spec, err := r.topicSpecWithClass(ctx, instance)
+	if err != nil {
+		return requeueWithError(reqLogger, "failed to get the topic class of the kafkatopic", err)
+	}
+
 	// Check if the topic already exists
 	existing, err := broker.GetTopic(instance.Spec.Name)
 	if err != nil {
@@ -151,25 +196,54 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 	}
 
 	// we got a topic back
-	if existing != nil {
+	var reassigningPartitions bool
+	if existing != nil && instance.Spec.GetDriftPolicy() == v1alpha1.TopicDriftPolicyDetect {
+		// The differences are only reported, the topic is left as is
+		config, err := broker.DescribeTopicConfig(spec.Name)
+		if err != nil {
+			return requeueWithError(reqLogger, "failed to describe topic config", err)
+		}
+		drift := topicDrift(spec, existing, config)
+		if len(drift) > 0 {
+			reqLogger.Info("Topic drifted from the KafkaTopic", "drift", drift)
+		}
+		if err = r.reportTopicDrift(ctx, instance, drift); err != nil {
+			return requeueWithError(reqLogger, "failed to report topic drift", err)
+		}
+	} else if existing != nil {
 		reqLogger.Info("Topic already exists, verifying configuration")
+		if err = r.reportTopicDrift(ctx, instance, nil); err != nil {
+			return requeueWithError(reqLogger, "failed to clear topic drift", err)
+		}
 		// Ensure partition count
-		if changed, err := broker.EnsurePartitionCount(instance.Spec.Name, instance.Spec.Partitions); err != nil {
+		if changed, err := broker.EnsurePartitionCount(spec.Name, spec.Partitions); err != nil {
 			return requeueWithError(reqLogger, "failed to ensure topic partition count", err)
 		} else if changed {
 			reqLogger.Info("Increased partition count for topic")
 		}
+		// Ensure replication factor, the new replicas are placed on the brokers allowed by the placement
+		placement := replicaPlacement(spec.Placement)
+		if reassigningPartitions, err = r.ensureReplicationFactor(ctx, broker, instance, spec.ReplicationFactor, placement, existing); err != nil {
+			return requeueWithError(reqLogger, "failed to ensure topic replication factor", err)
+		}
+		// Ensure replica placement once the replication factor is not being changed
+		if !reassigningPartitions && placement != nil {
+			if reassigningPartitions, err = ensureReplicaPlacement(ctx, broker, spec.Name, *placement); err != nil {
+				return requeueWithError(reqLogger, "failed to ensure topic replica placement", err)
+			}
+		}
 		// Ensure topic configurations
-		if err = broker.EnsureTopicConfig(instance.Spec.Name, util.MapStringStringPointer(instance.Spec.Config)); err != nil {
+		if err = broker.EnsureTopicConfig(spec.Name, util.MapStringStringPointer(spec.Config)); err != nil {
 			return requeueWithError(reqLogger, "failure to ensure topic config", err)
 		}
 		reqLogger.Info("Verified partitions and configuration for topic")
 	} else if err = broker.CreateTopic(&kafkaclient.CreateTopicOptions{
 		// Create the topic
-		Name:              instance.Spec.Name,
-		Partitions:        instance.Spec.Partitions,
-		ReplicationFactor: int16(instance.Spec.ReplicationFactor),
-		Config:            util.MapStringStringPointer(instance.Spec.Config),
+		Name:              spec.Name,
+		Partitions:        spec.Partitions,
+		ReplicationFactor: int16(spec.ReplicationFactor),
+		Config:            util.MapStringStringPointer(spec.Config),
+		Placement:         replicaPlacement(spec.Placement),
 	}); err != nil {
 		return requeueWithError(reqLogger, "failed to create kafka topic", err)
 	}
@@ -196,9 +270,127 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 		}
 	}
 
+	if err = r.syncTopicStatus(ctx, broker, cluster, instance); err != nil {
+		return requeueWithError(reqLogger, "failed to sync kafkatopic status", err)
+	}
+
+	if reassigningPartitions {
+		reqLogger.Info("Partitions of the topic are being reassigned")
+		return requeueAfter(topicReassignmentRequeueSeconds)
+	}
+
 	reqLogger.Info("Ensured topic")
 
-	return reconciled()
+	// The status is refreshed periodically since the topic can change without the KafkaTopic being changed
+	return requeueAfter(topicStatusSyncSeconds)
+}
+
+// syncTopicStatus updates the status with the actual partition count, replication factor, under-replicated and
+// offline partition counts, and size of the topic
+func (r *KafkaTopicReconciler) syncTopicStatus(ctx context.Context, broker kafkaclient.KafkaClient,
+	cluster *v1beta1.KafkaCluster, topic *v1alpha1.KafkaTopic) error {
+	meta, err := broker.DescribeTopic(topic.Spec.Name)
+	if err != nil {
+		return err
+	}
+	observed := kafkaclient.TopicMetaToStatus(meta)
+
+	status := topic.Status.DeepCopy()
+	status.Partitions = observed.Partitions
+	status.ReplicationFactor = observed.ReplicationFactor
+	status.UnderReplicatedPartitions = observed.UnderReplicatedPartitions
+	status.OfflinePartitions = observed.OfflinePartitions
+	// The size is kept from the last sync when the log dirs of the brokers cannot be described
+	clusterKey := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	if size, err := r.topicSizes.topicSize(clusterKey, topic.Spec.Name, broker, time.Now()); err != nil {
+		logr.FromContextOrDiscard(ctx).Info("failed to determine the size of the topic", "error", err.Error())
+	} else {
+		status.SizeBytes = size
+	}
+
+	if reflect.DeepEqual(*status, topic.Status) {
+		return nil
+	}
+	topic.Status = *status
+	return r.Client.Status().Update(ctx, topic)
+}
+
+// topicSpecWithClass returns the spec of the topic completed with the defaults of its topic class
+func (r *KafkaTopicReconciler) topicSpecWithClass(ctx context.Context, topic *v1alpha1.KafkaTopic) (v1alpha1.KafkaTopicSpec, error) {
+	if topic.Spec.TopicClassName == "" {
+		return topic.Spec, nil
+	}
+	topicClass := &v1alpha1.KafkaTopicClass{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: topic.Spec.TopicClassName}, topicClass); err != nil {
+		return topic.Spec, err
+	}
+	return topic.Spec.WithTopicClass(&topicClass.Spec), nil
+}
+
+// ensureReplicationFactor reassigns the partitions of the topic when its replication factor differs from the desired one,
+// and records the progress of the reassignment in the status. It returns true while the reassignment is in progress.
+func (r *KafkaTopicReconciler) ensureReplicationFactor(ctx context.Context, broker kafkaclient.KafkaClient,
+	topic *v1alpha1.KafkaTopic, desired int32, placement *kafkaclient.ReplicaPlacement, existing *sarama.TopicDetail) (bool, error) {
+	// The replication factor of topics using the broker's default is not changed
+	if desired <= 0 || (existing.ReplicationFactor == int16(desired) && topic.Status.ReplicationFactorChange == nil) {
+		return false, nil
+	}
+
+	reassignment, err := broker.EnsureReplicationFactor(topic.Spec.Name, int16(desired), placement)
+	if err != nil {
+		return false, err
+	}
+
+	var change *v1alpha1.ReplicationFactorChangeStatus
+	if reassignment.ReassigningPartitions > 0 {
+		change = topic.Status.ReplicationFactorChange.DeepCopy()
+		if change == nil || change.TargetReplicationFactor != desired {
+			change = &v1alpha1.ReplicationFactorChangeStatus{
+				TargetReplicationFactor: desired,
+				StartedAt:               time.Now().Format(time.RFC3339),
+			}
+		}
+		change.ReassigningPartitions = int32(reassignment.ReassigningPartitions)
+	}
+	if reassignment.Started {
+		logr.FromContextOrDiscard(ctx).Info("Started partition reassignment to change the replication factor of the topic",
+			"replicationFactor", desired, "partitions", reassignment.ReassigningPartitions)
+	}
+
+	if !reflect.DeepEqual(change, topic.Status.ReplicationFactorChange) {
+		topic.Status.ReplicationFactorChange = change
+		if err := r.Client.Status().Update(ctx, topic); err != nil {
+			return false, err
+		}
+	}
+	return change != nil, nil
+}
+
+// ensureReplicaPlacement reassigns the partitions of the topic whose replicas are not placed according to its placement.
+// It returns true while the reassignment is in progress.
+func ensureReplicaPlacement(ctx context.Context, broker kafkaclient.KafkaClient, topic string, placement kafkaclient.ReplicaPlacement) (bool, error) {
+	reassignment, err := broker.EnsureReplicaPlacement(topic, placement)
+	if err != nil {
+		return false, err
+	}
+	if reassignment.Started {
+		logr.FromContextOrDiscard(ctx).Info("Started partition reassignment to place the replicas of the topic",
+			"partitions", reassignment.ReassigningPartitions)
+	}
+	return reassignment.ReassigningPartitions > 0, nil
+}
+
+// replicaPlacement converts the placement of the KafkaTopic to the one of the Kafka client,
+// it returns nil when the placement does not constrain the replicas
+func replicaPlacement(placement *v1alpha1.TopicPlacement) *kafkaclient.ReplicaPlacement {
+	if !placement.IsConstrained() && !placement.HasReplicaAssignment() {
+		return nil
+	}
+	return &kafkaclient.ReplicaPlacement{
+		Brokers:    placement.Brokers,
+		Racks:      placement.Racks,
+		Assignment: placement.ReplicaAssignment,
+	}
 }
 
 func (r *KafkaTopicReconciler) ensureClusterLabel(ctx context.Context, cluster *v1beta1.KafkaCluster, topic *v1alpha1.KafkaTopic) (*v1alpha1.KafkaTopic, error) {
@@ -220,13 +412,18 @@ func (r *KafkaTopicReconciler) updateAndFetchLatest(ctx context.Context, topic *
 	return topic, nil
 }
 
-func (r *KafkaTopicReconciler) checkFinalizers(ctx context.Context, broker kafkaclient.KafkaClient, topic *v1alpha1.KafkaTopic) (reconcile.Result, error) {
+func (r *KafkaTopicReconciler) checkFinalizers(ctx context.Context, broker kafkaclient.KafkaClient,
+	cluster *v1beta1.KafkaCluster, topic *v1alpha1.KafkaTopic) (reconcile.Result, error) {
 	reqLogger := logr.FromContextOrDiscard(ctx)
 	reqLogger.Info("Kafka topic is marked for deletion")
 	var err error
 	if util.StringSliceContains(topic.GetFinalizers(), topicFinalizer) {
-		// Remove topic from Kafka cluster when it is managed by Koperator
-		if isTopicManagedByKoperator(topic) {
+		deletionPolicy := topic.Spec.GetDeletionPolicy(cluster)
+		if deletionPolicy == v1beta1.TopicDeletionPolicyRetain {
+			reqLogger.Info("Retaining topic in Kafka according to the deletion policy")
+		}
+		// Remove topic from Kafka cluster when it is managed by Koperator and it is not retained
+		if isTopicManagedByKoperator(topic) && deletionPolicy == v1beta1.TopicDeletionPolicyDelete {
 			if err = r.finalizeKafkaTopic(reqLogger, broker, topic); err != nil {
 				return requeueWithError(reqLogger, "failed to finalize kafkatopic", err)
 			}
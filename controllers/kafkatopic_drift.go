@@ -0,0 +1,113 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/IBM/sarama"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+)
+
+const (
+	topicDriftDetectedReason = "DriftDetected"
+	topicInSyncReason        = "InSync"
+)
+
+// topicDrift returns the differences of the partition count, replication factor and config of the Kafka topic
+// from the KafkaTopic spec. Partition count and replication factor left to the broker's default are not compared.
+// The config is the one set on the topic, the values inherited from the brokers are not drift.
+func topicDrift(spec v1alpha1.KafkaTopicSpec, existing *sarama.TopicDetail, config map[string]string) []v1alpha1.TopicDrift {
+	var drift []v1alpha1.TopicDrift
+	if spec.Partitions > 0 && spec.Partitions != existing.NumPartitions {
+		drift = append(drift, v1alpha1.TopicDrift{
+			Field:   "partitions",
+			Desired: strconv.Itoa(int(spec.Partitions)),
+			Actual:  strconv.Itoa(int(existing.NumPartitions)),
+		})
+	}
+	if spec.ReplicationFactor > 0 && int16(spec.ReplicationFactor) != existing.ReplicationFactor {
+		drift = append(drift, v1alpha1.TopicDrift{
+			Field:   "replicationFactor",
+			Desired: strconv.Itoa(int(spec.ReplicationFactor)),
+			Actual:  strconv.Itoa(int(existing.ReplicationFactor)),
+		})
+	}
+
+	keys := make(map[string]struct{}, len(spec.Config)+len(config))
+	for key := range spec.Config {
+		keys[key] = struct{}{}
+	}
+	for key := range config {
+		keys[key] = struct{}{}
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	for _, key := range sortedKeys {
+		desired, desiredSet := spec.Config[key]
+		actual, actualSet := config[key]
+		if desiredSet == actualSet && desired == actual {
+			continue
+		}
+		drift = append(drift, v1alpha1.TopicDrift{
+			Field:   "config." + key,
+			Desired: desired,
+			Actual:  actual,
+		})
+	}
+	return drift
+}
+
+// reportTopicDrift records the drift of the Kafka topic and the Drifted condition in the status of the KafkaTopic.
+// Both are removed when the drift policy is Enforce since the differences are corrected then.
+func (r *KafkaTopicReconciler) reportTopicDrift(ctx context.Context, topic *v1alpha1.KafkaTopic, drift []v1alpha1.TopicDrift) error {
+	status := topic.Status.DeepCopy()
+	if topic.Spec.GetDriftPolicy() == v1alpha1.TopicDriftPolicyDetect {
+		status.Drift = drift
+		condition := metav1.Condition{
+			Type:               v1alpha1.TopicConditionDrifted,
+			Status:             metav1.ConditionFalse,
+			Reason:             topicInSyncReason,
+			Message:            "the Kafka topic matches the KafkaTopic",
+			ObservedGeneration: topic.Generation,
+		}
+		if len(drift) > 0 {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = topicDriftDetectedReason
+			condition.Message = fmt.Sprintf("the Kafka topic differs from the KafkaTopic in %d field(s)", len(drift))
+		}
+		meta.SetStatusCondition(&status.Conditions, condition)
+	} else {
+		status.Drift = nil
+		meta.RemoveStatusCondition(&status.Conditions, v1alpha1.TopicConditionDrifted)
+	}
+
+	if reflect.DeepEqual(*status, topic.Status) {
+		return nil
+	}
+	topic.Status = *status
+	return r.Client.Status().Update(ctx, topic)
+}
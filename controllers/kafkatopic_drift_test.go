@@ -0,0 +1,96 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestTopicDrift(t *testing.T) {
+	testCases := []struct {
+		testName string
+		spec     v1alpha1.KafkaTopicSpec
+		existing sarama.TopicDetail
+		config   map[string]string
+		expected []v1alpha1.TopicDrift
+	}{
+		{
+			testName: "no drift",
+			spec: v1alpha1.KafkaTopicSpec{
+				Partitions:        3,
+				ReplicationFactor: 2,
+				Config:            map[string]string{"retention.ms": "1000"},
+			},
+			existing: sarama.TopicDetail{
+				NumPartitions:     3,
+				ReplicationFactor: 2,
+			},
+			config:   map[string]string{"retention.ms": "1000"},
+			expected: nil,
+		},
+		{
+			testName: "broker defaults are not compared",
+			spec: v1alpha1.KafkaTopicSpec{
+				Partitions:        -1,
+				ReplicationFactor: -1,
+			},
+			existing: sarama.TopicDetail{
+				NumPartitions:     12,
+				ReplicationFactor: 3,
+				// Inherited from the brokers, not part of the topic config
+				ConfigEntries: map[string]*string{"min.insync.replicas": util.StringPointer("2")},
+			},
+			config:   map[string]string{},
+			expected: nil,
+		},
+		{
+			testName: "partitions, replication factor and config drifted",
+			spec: v1alpha1.KafkaTopicSpec{
+				Partitions:        3,
+				ReplicationFactor: 2,
+				Config:            map[string]string{"retention.ms": "1000", "cleanup.policy": "compact"},
+			},
+			existing: sarama.TopicDetail{
+				NumPartitions:     6,
+				ReplicationFactor: 3,
+			},
+			config: map[string]string{
+				"retention.ms":  "2000",
+				"segment.bytes": "1048576",
+			},
+			expected: []v1alpha1.TopicDrift{
+				{Field: "partitions", Desired: "3", Actual: "6"},
+				{Field: "replicationFactor", Desired: "2", Actual: "3"},
+				{Field: "config.cleanup.policy", Desired: "compact", Actual: ""},
+				{Field: "config.retention.ms", Desired: "1000", Actual: "2000"},
+				{Field: "config.segment.bytes", Desired: "", Actual: "1048576"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.testName, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, testCase.expected, topicDrift(testCase.spec, &testCase.existing, testCase.config))
+		})
+	}
+}
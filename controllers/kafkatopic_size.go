@@ -0,0 +1,66 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// topicSizeCache holds the sizes of the topics by cluster, so the log dirs of the brokers of a cluster are described
+// once per status sync interval instead of once per topic
+type topicSizeCache struct {
+	mu       sync.Mutex
+	clusters map[types.NamespacedName]*clusterTopicSizes
+}
+
+// clusterTopicSizes are the sizes of the topics of a cluster described at fetchedAt
+type clusterTopicSizes struct {
+	mu        sync.Mutex
+	sizes     map[string]int64
+	err       error
+	fetchedAt time.Time
+}
+
+// topicSize returns the size of the topic of the cluster. The sizes of all the topics of the cluster are described
+// when the cached ones are older than the status sync interval, a failure is also kept until then.
+func (c *topicSizeCache) topicSize(cluster types.NamespacedName, topic string, broker kafkaclient.KafkaClient, now time.Time) (int64, error) {
+	c.mu.Lock()
+	if c.clusters == nil {
+		c.clusters = make(map[types.NamespacedName]*clusterTopicSizes)
+	}
+	sizes, ok := c.clusters[cluster]
+	if !ok {
+		sizes = &clusterTopicSizes{}
+		c.clusters[cluster] = sizes
+	}
+	c.mu.Unlock()
+
+	// The topics of other clusters are not blocked while the log dirs of this cluster are described
+	sizes.mu.Lock()
+	defer sizes.mu.Unlock()
+	if sizes.fetchedAt.IsZero() || now.Sub(sizes.fetchedAt) >= topicStatusSyncSeconds*time.Second {
+		sizes.sizes, sizes.err = broker.TopicSizes()
+		sizes.fetchedAt = now
+	}
+	if sizes.err != nil {
+		return 0, sizes.err
+	}
+	return sizes.sizes[topic], nil
+}
@@ -0,0 +1,50 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/koperator/pkg/resources/kafka/mocks"
+)
+
+func TestTopicSizeCache(t *testing.T) {
+	broker := mocks.NewMockKafkaClient(gomock.NewController(t))
+	cluster := types.NamespacedName{Namespace: "kafka", Name: "kafka"}
+	now := time.Now()
+	cache := topicSizeCache{}
+
+	// The log dirs are described once for the topics of the cluster
+	broker.EXPECT().TopicSizes().Return(map[string]int64{"topic-a": 1024, "topic-b": 2048}, nil).Times(1)
+	size, err := cache.topicSize(cluster, "topic-a", broker, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024), size)
+	size, err = cache.topicSize(cluster, "topic-b", broker, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2048), size)
+
+	// The sizes are described again after the status sync interval, a failure is kept until the next one
+	broker.EXPECT().TopicSizes().Return(nil, errors.New("bad describe log dirs")).Times(1)
+	_, err = cache.topicSize(cluster, "topic-a", broker, now.Add(topicStatusSyncSeconds*time.Second))
+	assert.Error(t, err)
+	_, err = cache.topicSize(cluster, "topic-b", broker, now.Add(topicStatusSyncSeconds*time.Second+time.Minute))
+	assert.Error(t, err)
+}
@@ -16,6 +16,8 @@ package controllers
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"reflect"
 	"strings"
@@ -27,15 +29,21 @@ import (
 
 	certv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	certsigningreqv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlBuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -44,14 +52,37 @@ import (
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/errorfactory"
 	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
 	"github.com/banzaicloud/koperator/pkg/pki"
 	"github.com/banzaicloud/koperator/pkg/util"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
 	kafkautil "github.com/banzaicloud/koperator/pkg/util/kafka"
 	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
 )
 
 var userFinalizer = "finalizer.kafkausers.kafka.banzaicloud.io"
 
+const (
+	// scramPasswordLength is the number of random bytes of the generated SCRAM passwords
+	scramPasswordLength = 32
+	// certificateRenewalCheckInterval is how often the user is reconciled while the renewal of its certificate is pending
+	certificateRenewalCheckInterval = time.Minute
+	// certificateRenewalFailedReason is the reason of the events of the failed certificate renewals
+	certificateRenewalFailedReason = "CertificateRenewalFailed"
+	// unscopedConsumerGroupReason is the reason of the events of the read grants not applied since they allow every
+	// consumer group while the cluster requires scoped consumer groups
+	unscopedConsumerGroupReason = "UnscopedConsumerGroup"
+)
+
+var userCertificateRenewalFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "koperator_kafkauser_certificate_renewal_failures_total",
+	Help: "Number of failed attempts to renew the certificate of the KafkaUser",
+}, []string{"namespace", "kafka_user"})
+
+func init() {
+	metrics.Registry.MustRegister(userCertificateRenewalFailures)
+}
+
 // SetupKafkaUserWithManager registers KafkaUser controller to the manager
 func SetupKafkaUserWithManager(mgr ctrl.Manager, certSigningEnabled bool, certManagerEnabled bool) *ctrl.Builder {
 	log := mgr.GetLogger()
@@ -72,6 +103,8 @@ func SetupKafkaUserWithManager(mgr ctrl.Manager, certSigningEnabled bool, certMa
 	if certManagerEnabled {
 		builder.Owns(&certv1.Certificate{})
 	}
+	// Recreate the SCRAM password when the secret of the user is deleted
+	builder.Owns(&corev1.Secret{})
 	return builder
 }
 
@@ -147,6 +180,8 @@ type KafkaUserReconciler struct {
 	// that reads objects from the cache and writes to the apiserver
 	Client client.Client
 	Scheme *runtime.Scheme
+	// Recorder records the events of the KafkaUsers, e.g. the failed certificate renewals
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkausers,verbs=get;list;watch;create;update;patch;delete;deletecollection
@@ -158,6 +193,7 @@ type KafkaUserReconciler struct {
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=certificatesigningrequests,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=certificatesigningrequests/approval,verbs=update
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=signers,verbs=approve
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile reads that state of the cluster for a KafkaUser object and makes changes based on the state read
 // and what is in the KafkaUser.Spec
@@ -193,8 +229,24 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 	}
 
 	var kafkaUser string
-
-	if instance.Spec.GetIfCertShouldBeCreated() {
+	var certificate *v1alpha1.UserCertificateStatus
+	var requeueAfter time.Duration
+
+	if instance.Spec.IsScramAuthentication() {
+		// The SCRAM user is named after the KafkaUser, its ACLs are bound to the User:<name> principal
+		kafkaUser = instance.Name
+		if !k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+			broker, close, err := newKafkaFromCluster(r.Client, cluster)
+			if err != nil {
+				return checkBrokerConnectionError(reqLogger, err)
+			}
+			err = r.reconcileScramCredentials(ctx, broker, instance)
+			close()
+			if err != nil {
+				return requeueWithError(reqLogger, "failed to reconcile SCRAM credentials of kafkauser", err)
+			}
+		}
+	} else if instance.Spec.GetIfCertShouldBeCreated() {
 		// Validate the KafkaUser instance annotations before creating a certificate request
 		err := instance.Spec.ValidateAnnotations()
 		if err != nil {
@@ -216,6 +268,12 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 		pkiManager := pki.GetPKIManager(r.Client, cluster, backend)
 
 		user, err := pkiManager.ReconcileUserCertificate(ctx, instance, r.Scheme, cluster.Spec.GetKubernetesClusterDomain())
+		var renewalErr error
+		if err != nil && user != nil && errors.As(err, &errorfactory.CertificateRenewalFailed{}) {
+			// The current certificate stays in use until it expires, the renewal is retried
+			reqLogger.Error(err, "failed to renew user certificate")
+			renewalErr, err = err, nil
+		}
 		if err != nil {
 			switch {
 			case errors.As(err, &errorfactory.ResourceNotReady{}):
@@ -251,6 +309,11 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 				Requeue: false,
 			}, err
 		}
+		certificate, requeueAfter = userCertificateStatus(instance, user)
+		if renewalErr != nil {
+			r.recordCertificateRenewalFailure(instance, certificate, user.RenewalFailureTime, renewalErr)
+			requeueAfter = certificateRenewalCheckInterval
+		}
 		// check if marked for deletion and remove created certs
 		if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
 			reqLogger.Info("Kafka user is marked for deletion, revoking certificates")
@@ -272,21 +335,48 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 		return requeueWithError(reqLogger, "failed to ensure kafkacluster label on user", err)
 	}
 
-	// If topic grants supplied, grab a broker connection and set ACLs
-	if len(instance.Spec.TopicGrants) > 0 {
+	// Quotas are also reconciled after they have been removed from the spec to remove them from Kafka
+	reconcileQuotas := instance.Spec.Quotas != nil || instance.Status.Quotas != nil
+	var quotas *v1alpha1.UserQuotas
+
+	topicGrants := r.scopedTopicGrants(instance, cluster)
+
+	// ACLs are also reconciled after all of them have been removed from the spec to remove them from Kafka
+	reconcileACLs := len(instance.Spec.TopicGrants) > 0 || len(instance.Spec.ACLs) > 0 || len(instance.Status.ACLs) > 0
+	aclChanges := instance.Status.ACLChanges
+
+	// If topic grants, ACLs or quotas supplied, grab a broker connection and set ACLs and quotas
+	if reconcileACLs || reconcileQuotas {
 		broker, close, err := newKafkaFromCluster(r.Client, cluster)
 		if err != nil {
 			return checkBrokerConnectionError(reqLogger, err)
 		}
 		defer close()
 
-		// TODO (tinyzimmer): Should probably take this opportunity to see if we are removing any ACLs
-		for _, grant := range instance.Spec.TopicGrants {
-			reqLogger.Info(fmt.Sprintf("Ensuring %s ACLs for User: %s -> Topic: %s", grant.AccessType, kafkaUser, grant.TopicName))
-			// CreateUserACLs returns no error if the ACLs already exist
-			if err = broker.CreateUserACLs(grant.AccessType, grant.PatternType, kafkaUser, grant.TopicName); err != nil {
+		if reconcileACLs {
+			reqLogger.Info(fmt.Sprintf("Ensuring ACLs for User: %s", kafkaUser))
+			// ReconcileUserACLs creates the missing ACLs and deletes the ones no longer needed by the user
+			added, removed, err := broker.ReconcileUserACLs(kafkaUser, topicGrants, instance.Spec.ACLs)
+			if err != nil {
 				return requeueWithError(reqLogger, "failed to ensure ACLs for kafkauser", err)
 			}
+			if len(added) > 0 || len(removed) > 0 {
+				reqLogger.Info("ACLs of kafkauser changed", "added", added, "removed", removed)
+				aclChanges = &v1alpha1.UserACLChanges{
+					Added:     added,
+					Removed:   removed,
+					ChangedAt: time.Now().Format(time.RFC3339),
+				}
+			}
+		}
+
+		if reconcileQuotas {
+			if err = broker.AlterUserQuotas(kafkaUser, instance.Spec.Quotas); err != nil {
+				return requeueWithError(reqLogger, "failed to ensure quotas for kafkauser", err)
+			}
+			if quotas, err = broker.DescribeUserQuotas(kafkaUser); err != nil {
+				return requeueWithError(reqLogger, "failed to describe quotas of kafkauser", err)
+			}
 		}
 	}
 
@@ -300,18 +390,89 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 
 	// set user status
 	instance.Status = v1alpha1.KafkaUserStatus{
-		State: v1alpha1.UserStateCreated,
+		State:       v1alpha1.UserStateCreated,
+		Quotas:      quotas,
+		ACLChanges:  aclChanges,
+		Certificate: certificate,
 	}
-	if len(instance.Spec.TopicGrants) > 0 {
-		instance.Status.ACLs = kafkautil.GrantsToACLStrings(kafkaUser, instance.Spec.TopicGrants)
+	if len(topicGrants) > 0 || len(instance.Spec.ACLs) > 0 {
+		instance.Status.ACLs = kafkautil.GrantsToACLStrings(kafkaUser, topicGrants, instance.Spec.ACLs)
 	}
 	if err := r.Client.Status().Update(ctx, instance); err != nil {
 		return requeueWithError(reqLogger, "failed to update kafkauser status", err)
 	}
 
+	if requeueAfter > 0 {
+		// reconcile again to renew the certificate of the user
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
 	return reconciled()
 }
 
+// userCertificateStatus returns the validity of the certificate of the user and the time until it is due for renewal.
+// While the renewal is pending the user is checked periodically.
+func userCertificateStatus(user *v1alpha1.KafkaUser, userCert *pkicommon.UserCertificate) (*v1alpha1.UserCertificateStatus, time.Duration) {
+	cert, err := certutil.DecodeCertificate(userCert.Certificate)
+	if err != nil {
+		// the certificate has already been validated by getting its distinguished name
+		return nil, 0
+	}
+	renewalTime := user.Spec.GetCertificateRenewalTime(cert.NotBefore, cert.NotAfter)
+	requeueAfter := time.Until(renewalTime)
+	if requeueAfter <= 0 {
+		requeueAfter = certificateRenewalCheckInterval
+	}
+	status := &v1alpha1.UserCertificateStatus{
+		NotBefore:   cert.NotBefore.UTC().Format(time.RFC3339),
+		NotAfter:    cert.NotAfter.UTC().Format(time.RFC3339),
+		RenewalTime: renewalTime.UTC().Format(time.RFC3339),
+	}
+	if user.Status.Certificate != nil {
+		status.LastRenewalFailureTime = user.Status.Certificate.LastRenewalFailureTime
+	}
+	return status, requeueAfter
+}
+
+// recordCertificateRenewalFailure emits an event and counts the failed renewal of the certificate of the user.
+// A failure is reported once, failures with the time already recorded in the status of the user are skipped.
+func (r *KafkaUserReconciler) recordCertificateRenewalFailure(user *v1alpha1.KafkaUser, certificate *v1alpha1.UserCertificateStatus,
+	failureTime time.Time, err error) {
+	if certificate == nil {
+		return
+	}
+	failure := failureTime.UTC().Format(time.RFC3339)
+	if certificate.LastRenewalFailureTime == failure {
+		return
+	}
+	certificate.LastRenewalFailureTime = failure
+	r.Recorder.Event(user, corev1.EventTypeWarning, certificateRenewalFailedReason, err.Error())
+	userCertificateRenewalFailures.WithLabelValues(user.Namespace, user.Name).Inc()
+}
+
+// scopedTopicGrants returns the topic grants of the user to be applied. The read grants allowing every consumer group
+// are left out when the cluster requires scoped consumer groups, since the users created before the requirement keep
+// such grants, a warning event is emitted for them.
+func (r *KafkaUserReconciler) scopedTopicGrants(user *v1alpha1.KafkaUser, cluster *v1beta1.KafkaCluster) []v1alpha1.UserTopicGrant {
+	if !cluster.Spec.RequireScopedConsumerGroups {
+		return user.Spec.TopicGrants
+	}
+	grants := make([]v1alpha1.UserTopicGrant, 0, len(user.Spec.TopicGrants))
+	var unscopedTopics []string
+	for _, grant := range user.Spec.TopicGrants {
+		if !grant.HasScopedConsumerGroup() {
+			unscopedTopics = append(unscopedTopics, grant.TopicName)
+			continue
+		}
+		grants = append(grants, grant)
+	}
+	if len(unscopedTopics) > 0 {
+		r.Recorder.Eventf(user, corev1.EventTypeWarning, unscopedConsumerGroupReason,
+			"the read grants of topics %s are not applied, the KafkaCluster requires them to be restricted to a consumer group",
+			strings.Join(unscopedTopics, ", "))
+	}
+	return grants
+}
+
 func (r *KafkaUserReconciler) ensureClusterLabel(ctx context.Context, cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser) (*v1alpha1.KafkaUser, error) {
 	labels := applyClusterRefLabel(cluster, user.GetLabels())
 	if !reflect.DeepEqual(labels, user.GetLabels()) {
@@ -336,11 +497,19 @@ func (r *KafkaUserReconciler) checkFinalizers(ctx context.Context, cluster *v1be
 	// run finalizers
 	var err error
 	if util.StringSliceContains(instance.GetFinalizers(), userFinalizer) {
-		if len(instance.Spec.TopicGrants) > 0 {
-			for _, topicGrant := range instance.Spec.TopicGrants {
-				if err = r.finalizeKafkaUserACLs(reqLogger, cluster, user, topicGrant.PatternType); err != nil {
-					return requeueWithError(reqLogger, "failed to finalize kafkauser", err)
-				}
+		if len(instance.Spec.TopicGrants) > 0 || len(instance.Spec.ACLs) > 0 || len(instance.Status.ACLs) > 0 {
+			if err = r.finalizeKafkaUserACLs(reqLogger, cluster, user); err != nil {
+				return requeueWithError(reqLogger, "failed to finalize kafkauser", err)
+			}
+		}
+		if instance.Spec.IsScramAuthentication() {
+			if err = r.finalizeKafkaUserScramCredentials(reqLogger, cluster, user); err != nil {
+				return requeueWithError(reqLogger, "failed to finalize SCRAM credentials of kafkauser", err)
+			}
+		}
+		if instance.Spec.Quotas != nil || instance.Status.Quotas != nil {
+			if err = r.finalizeKafkaUserQuotas(reqLogger, cluster, user); err != nil {
+				return requeueWithError(reqLogger, "failed to finalize quotas of kafkauser", err)
 			}
 		}
 		// remove finalizer
@@ -357,22 +526,130 @@ func (r *KafkaUserReconciler) removeFinalizer(ctx context.Context, user *v1alpha
 	return err
 }
 
-func (r *KafkaUserReconciler) finalizeKafkaUserACLs(reqLogger logr.Logger, cluster *v1beta1.KafkaCluster, user string, patternType v1alpha1.KafkaPatternType) error {
+func (r *KafkaUserReconciler) finalizeKafkaUserACLs(reqLogger logr.Logger, cluster *v1beta1.KafkaCluster, user string) error {
 	if k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
 		reqLogger.Info("Cluster is being deleted, skipping ACL deletion")
 		return nil
 	}
-	var err error
 	reqLogger.Info("Deleting user ACLs from kafka")
 	broker, close, err := newKafkaFromCluster(r.Client, cluster)
 	if err != nil {
 		return err
 	}
 	defer close()
-	if err = broker.DeleteUserACLs(user, patternType); err != nil {
+	// reconciling to no grants and ACLs deletes all the ACLs bound to the principal of the user
+	_, _, err = broker.ReconcileUserACLs(user, nil, nil)
+	return err
+}
+
+func (r *KafkaUserReconciler) finalizeKafkaUserScramCredentials(reqLogger logr.Logger, cluster *v1beta1.KafkaCluster, user string) error {
+	if k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
+		reqLogger.Info("Cluster is being deleted, skipping SCRAM credential deletion")
+		return nil
+	}
+	reqLogger.Info("Deleting user SCRAM credentials from kafka")
+	broker, close, err := newKafkaFromCluster(r.Client, cluster)
+	if err != nil {
+		return err
+	}
+	defer close()
+	return broker.DeleteUserScramCredentials(user)
+}
+
+func (r *KafkaUserReconciler) finalizeKafkaUserQuotas(reqLogger logr.Logger, cluster *v1beta1.KafkaCluster, user string) error {
+	if k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
+		reqLogger.Info("Cluster is being deleted, skipping quota deletion")
+		return nil
+	}
+	reqLogger.Info("Deleting user quotas from kafka")
+	broker, close, err := newKafkaFromCluster(r.Client, cluster)
+	if err != nil {
+		return err
+	}
+	defer close()
+	return broker.AlterUserQuotas(user, nil)
+}
+
+// reconcileScramCredentials ensures the secret of the user holds a SCRAM password registered in Kafka. A new password
+// is generated when the secret has none, or when a rotation is requested by changing the rotate-password annotation.
+func (r *KafkaUserReconciler) reconcileScramCredentials(ctx context.Context, broker kafkaclient.KafkaClient, user *v1alpha1.KafkaUser) error {
+	rotation := user.GetAnnotations()[v1alpha1.RotatePasswordAnnotationKey]
+	authentication := user.Spec.GetAuthentication()
+
+	secret := &corev1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: user.Spec.SecretName, Namespace: user.Namespace}, secret)
+	switch {
+	case apierrors.IsNotFound(err):
+		secret = nil
+	case err != nil:
+		return errors.WrapIfWithDetails(err, "failed to get user's secret from K8s",
+			"secretName", user.Spec.SecretName, "namespace", user.Namespace)
+	case !metav1.IsControlledBy(secret, user):
+		return errors.NewWithDetails("secret does not belong to this KafkaUser", "secretName", secret.Name)
+	}
+
+	if secret != nil && len(secret.Data[v1alpha1.PasswordKey]) > 0 && string(secret.Data[v1alpha1.UsernameKey]) == user.Name &&
+		secret.GetAnnotations()[v1alpha1.RotatePasswordAnnotationKey] == rotation {
+		// The credential is registered again if it's missing, e.g. when the mechanism of the user has been changed
+		return broker.EnsureUserScramCredentials(user.Name, authentication, secret.Data[v1alpha1.PasswordKey], false)
+	}
+
+	password, err := generateScramPassword()
+	if err != nil {
 		return err
 	}
-	return nil
+	// The password is registered before it's written to the secret, so the secret never holds a password unknown to Kafka.
+	// When the secret can't be written a new password is generated and registered on the next reconcile.
+	if err = broker.EnsureUserScramCredentials(user.Name, authentication, password, true); err != nil {
+		return err
+	}
+	logr.FromContextOrDiscard(ctx).Info("Registered new SCRAM password of user", "mechanism", authentication, "rotation", rotation)
+
+	if secret == nil {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      user.Spec.SecretName,
+				Namespace: user.Namespace,
+			},
+		}
+		if err = controllerutil.SetControllerReference(user, secret, r.Scheme); err != nil {
+			return err
+		}
+		setScramSecretPassword(secret, user.Name, password, rotation)
+		return r.Client.Create(ctx, secret)
+	}
+	setScramSecretPassword(secret, user.Name, password, rotation)
+	return r.Client.Update(ctx, secret)
+}
+
+// setScramSecretPassword sets the SCRAM credentials of the user in the secret, annotated with the rotation request they were generated for
+func setScramSecretPassword(secret *corev1.Secret, user string, password []byte, rotation string) {
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[v1alpha1.UsernameKey] = []byte(user)
+	secret.Data[v1alpha1.PasswordKey] = password
+	annotations := secret.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if rotation == "" {
+		delete(annotations, v1alpha1.RotatePasswordAnnotationKey)
+	} else {
+		annotations[v1alpha1.RotatePasswordAnnotationKey] = rotation
+	}
+	secret.SetAnnotations(annotations)
+}
+
+// generateScramPassword returns a random password of scramPasswordLength bytes encoded without padding
+func generateScramPassword() ([]byte, error) {
+	random := make([]byte, scramPasswordLength)
+	if _, err := rand.Read(random); err != nil {
+		return nil, errors.WrapIf(err, "failed to generate SCRAM password")
+	}
+	password := make([]byte, base64.RawURLEncoding.EncodedLen(len(random)))
+	base64.RawURLEncoding.Encode(password, random)
+	return password, nil
 }
 
 func (r *KafkaUserReconciler) addFinalizer(reqLogger logr.Logger, user *v1alpha1.KafkaUser) {
@@ -0,0 +1,313 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka/mocks"
+	"github.com/banzaicloud/koperator/pkg/util"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+)
+
+func TestReconcileScramCredentials(t *testing.T) {
+	newUser := func(rotation string) *v1alpha1.KafkaUser {
+		user := &v1alpha1.KafkaUser{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "KafkaUser"},
+			ObjectMeta: metav1.ObjectMeta{Name: "test-user", Namespace: "kafka", UID: "test-user-uid"},
+			Spec: v1alpha1.KafkaUserSpec{
+				SecretName:     "test-user-secret",
+				ClusterRef:     v1alpha1.ClusterReference{Name: "kafka"},
+				Authentication: v1alpha1.UserAuthenticationScramSHA512,
+			},
+		}
+		if rotation != "" {
+			user.SetAnnotations(map[string]string{v1alpha1.RotatePasswordAnnotationKey: rotation})
+		}
+		return user
+	}
+	newSecret := func(owner *v1alpha1.KafkaUser, password, rotation string) *corev1.Secret {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-user-secret", Namespace: "kafka"},
+			Data: map[string][]byte{
+				v1alpha1.UsernameKey: []byte("test-user"),
+				v1alpha1.PasswordKey: []byte(password),
+			},
+		}
+		if rotation != "" {
+			secret.SetAnnotations(map[string]string{v1alpha1.RotatePasswordAnnotationKey: rotation})
+		}
+		if owner != nil {
+			_ = controllerutil.SetControllerReference(owner, secret, newKafkaUserTestScheme())
+		}
+		return secret
+	}
+
+	testCases := []struct {
+		testName            string
+		user                *v1alpha1.KafkaUser
+		secret              *corev1.Secret
+		registerErr         error
+		expectedUpdate      bool
+		expectedPassword    string
+		expectedNewPassword bool
+		expectedErr         bool
+	}{
+		{
+			testName:            "secret is created with a new password",
+			user:                newUser(""),
+			expectedUpdate:      true,
+			expectedNewPassword: true,
+		},
+		{
+			testName:         "password of the secret is registered",
+			user:             newUser("1"),
+			secret:           newSecret(newUser("1"), "existing", "1"),
+			expectedUpdate:   false,
+			expectedPassword: "existing",
+		},
+		{
+			testName:            "password is rotated on request",
+			user:                newUser("2"),
+			secret:              newSecret(newUser("2"), "existing", "1"),
+			expectedUpdate:      true,
+			expectedNewPassword: true,
+		},
+		{
+			testName:    "secret owned by another resource",
+			user:        newUser(""),
+			secret:      newSecret(nil, "existing", ""),
+			expectedErr: true,
+		},
+		{
+			testName:            "secret is not changed when the password can not be registered",
+			user:                newUser("2"),
+			secret:              newSecret(newUser("2"), "existing", "1"),
+			registerErr:         errors.New("controller not available"),
+			expectedUpdate:      true,
+			expectedNewPassword: true,
+			expectedPassword:    "existing",
+			expectedErr:         true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			objects := []client.Object{test.user}
+			if test.secret != nil {
+				objects = append(objects, test.secret)
+			}
+			r := KafkaUserReconciler{
+				Client: fake.NewClientBuilder().WithScheme(newKafkaUserTestScheme()).WithObjects(objects...).Build(),
+				Scheme: newKafkaUserTestScheme(),
+			}
+
+			var registered []byte
+			broker := mocks.NewMockKafkaClient(gomock.NewController(t))
+			if test.secret == nil || metav1.IsControlledBy(test.secret, test.user) {
+				broker.EXPECT().EnsureUserScramCredentials("test-user", v1alpha1.UserAuthenticationScramSHA512, gomock.Any(), test.expectedUpdate).
+					DoAndReturn(func(_ string, _ v1alpha1.UserAuthenticationType, password []byte, _ bool) error {
+						registered = password
+						return test.registerErr
+					})
+			}
+
+			err := r.reconcileScramCredentials(context.Background(), broker, test.user)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if test.expectedNewPassword {
+				assert.Len(t, registered, 43)
+				assert.NotEqual(t, "existing", string(registered))
+			} else if test.expectedPassword != "" {
+				assert.Equal(t, test.expectedPassword, string(registered))
+			}
+
+			secret := &corev1.Secret{}
+			if err := r.Client.Get(context.Background(), types.NamespacedName{Name: "test-user-secret", Namespace: "kafka"}, secret); err != nil {
+				assert.Nil(t, test.secret)
+				assert.True(t, test.expectedErr)
+				return
+			}
+			switch {
+			case test.expectedPassword != "":
+				assert.Equal(t, test.expectedPassword, string(secret.Data[v1alpha1.PasswordKey]))
+			case test.expectedNewPassword:
+				assert.Equal(t, registered, secret.Data[v1alpha1.PasswordKey])
+				assert.Equal(t, "test-user", string(secret.Data[v1alpha1.UsernameKey]))
+				assert.Equal(t, test.user.GetAnnotations()[v1alpha1.RotatePasswordAnnotationKey], secret.GetAnnotations()[v1alpha1.RotatePasswordAnnotationKey])
+				assert.True(t, metav1.IsControlledBy(secret, test.user))
+			}
+		})
+	}
+}
+
+func newKafkaUserTestScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+	return s
+}
+
+func TestUserCertificateStatus(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCertificate := func(notBefore, notAfter time.Time) *pkicommon.UserCertificate {
+		template := x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "test-user"},
+			NotBefore:    notBefore,
+			NotAfter:     notAfter,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &pkicommon.UserCertificate{Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	user := &v1alpha1.KafkaUser{Spec: v1alpha1.KafkaUserSpec{CertificateRenewalPercentage: util.Int32Pointer(50)}}
+
+	tests := []struct {
+		name               string
+		certificate        *pkicommon.UserCertificate
+		expectedStatus     *v1alpha1.UserCertificateStatus
+		expectedMinRequeue time.Duration
+		expectedMaxRequeue time.Duration
+	}{
+		{
+			name:        "requeued at the renewal time",
+			certificate: newCertificate(now, now.Add(4*time.Hour)),
+			expectedStatus: &v1alpha1.UserCertificateStatus{
+				NotBefore:   now.Format(time.RFC3339),
+				NotAfter:    now.Add(4 * time.Hour).Format(time.RFC3339),
+				RenewalTime: now.Add(2 * time.Hour).Format(time.RFC3339),
+			},
+			expectedMinRequeue: 2*time.Hour - time.Minute,
+			expectedMaxRequeue: 2 * time.Hour,
+		},
+		{
+			name:        "checked periodically while the renewal is pending",
+			certificate: newCertificate(now.Add(-3*time.Hour), now.Add(time.Hour)),
+			expectedStatus: &v1alpha1.UserCertificateStatus{
+				NotBefore:   now.Add(-3 * time.Hour).Format(time.RFC3339),
+				NotAfter:    now.Add(time.Hour).Format(time.RFC3339),
+				RenewalTime: now.Add(-time.Hour).Format(time.RFC3339),
+			},
+			expectedMinRequeue: certificateRenewalCheckInterval,
+			expectedMaxRequeue: certificateRenewalCheckInterval,
+		},
+		{
+			name:        "invalid certificate",
+			certificate: &pkicommon.UserCertificate{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			status, requeueAfter := userCertificateStatus(user, test.certificate)
+			assert.Equal(t, test.expectedStatus, status)
+			assert.GreaterOrEqual(t, requeueAfter, test.expectedMinRequeue)
+			assert.LessOrEqual(t, requeueAfter, test.expectedMaxRequeue)
+		})
+	}
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	metric := &dto.Metric{}
+	if err := counter.Write(metric); err != nil {
+		t.Fatal("Expected no error writing the metric, got:", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestRecordCertificateRenewalFailure(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := KafkaUserReconciler{Recorder: recorder}
+	user := &v1alpha1.KafkaUser{ObjectMeta: metav1.ObjectMeta{Name: "test-renewal-failure", Namespace: "kafka"}}
+	counter := userCertificateRenewalFailures.WithLabelValues(user.Namespace, user.Name)
+	failureTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	certificate := &v1alpha1.UserCertificateStatus{}
+
+	r.recordCertificateRenewalFailure(user, certificate, failureTime, errors.New("issuer is not ready"))
+	assert.Equal(t, "2023-01-02T03:04:05Z", certificate.LastRenewalFailureTime)
+	assert.Len(t, recorder.Events, 1)
+	assert.Equal(t, float64(1), counterValue(t, counter))
+
+	// The failure reported again on the next reconciliation is not counted
+	r.recordCertificateRenewalFailure(user, certificate, failureTime, errors.New("issuer is not ready"))
+	assert.Len(t, recorder.Events, 1)
+	assert.Equal(t, float64(1), counterValue(t, counter))
+
+	// A new failure is counted
+	r.recordCertificateRenewalFailure(user, certificate, failureTime.Add(time.Hour), errors.New("issuer is not ready"))
+	assert.Equal(t, "2023-01-02T04:04:05Z", certificate.LastRenewalFailureTime)
+	assert.Len(t, recorder.Events, 2)
+	assert.Equal(t, float64(2), counterValue(t, counter))
+}
+
+func TestScopedTopicGrants(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := KafkaUserReconciler{Recorder: recorder}
+	scoped := v1alpha1.UserTopicGrant{TopicName: "orders", AccessType: v1alpha1.KafkaAccessTypeRead, ConsumerGroup: "orders-app"}
+	unscoped := v1alpha1.UserTopicGrant{TopicName: "payments", AccessType: v1alpha1.KafkaAccessTypeRead}
+	write := v1alpha1.UserTopicGrant{TopicName: "payments", AccessType: v1alpha1.KafkaAccessTypeWrite}
+	user := &v1alpha1.KafkaUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-scoped-grants", Namespace: "kafka"},
+		Spec:       v1alpha1.KafkaUserSpec{TopicGrants: []v1alpha1.UserTopicGrant{scoped, unscoped, write}},
+	}
+	cluster := &v1beta1.KafkaCluster{}
+
+	assert.Equal(t, user.Spec.TopicGrants, r.scopedTopicGrants(user, cluster))
+	assert.Len(t, recorder.Events, 0)
+
+	// The read grant allowing every consumer group is not applied when the cluster requires scoped consumer groups
+	cluster.Spec.RequireScopedConsumerGroups = true
+	assert.Equal(t, []v1alpha1.UserTopicGrant{scoped, write}, r.scopedTopicGrants(user, cluster))
+	if assert.Len(t, recorder.Events, 1) {
+		assert.Contains(t, <-recorder.Events, unscopedConsumerGroupReason)
+	}
+}
@@ -16,6 +16,7 @@ package tests
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/banzaicloud/koperator/pkg/util"
@@ -25,7 +26,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/kafkaclient"
 )
@@ -184,6 +187,10 @@ func resetMockKafkaClient(kafkaCluster *v1beta1.KafkaCluster) {
 		_ = mockKafkaClient.DeleteTopic(topicName, false)
 	}
 
-	// delete all acls
-	_ = mockKafkaClient.DeleteUserACLs("", "")
+	// delete the acls of all the users of the cluster
+	kafkaUsers := &v1alpha1.KafkaUserList{}
+	_ = k8sClient.List(context.Background(), kafkaUsers, client.InNamespace(kafkaCluster.Namespace))
+	for _, kafkaUser := range kafkaUsers.Items {
+		_, _, _ = mockKafkaClient.ReconcileUserACLs(fmt.Sprintf("CN=%s", kafkaUser.Name), nil, nil)
+	}
 }
@@ -133,7 +133,7 @@ var _ = Describe("KafkaTopic", func() {
 		Expect(user.Labels).To(HaveKeyWithValue("kafkaCluster", fmt.Sprintf("%s.%s", kafkaClusterCRName, namespace)))
 
 		mockKafkaClient, _ := getMockedKafkaClientForCluster(kafkaCluster)
-		acls, _ := mockKafkaClient.ListUserACLs()
+		acls, _ := mockKafkaClient.ListUserACLs("CN=kafkauser-1")
 		Expect(acls).To(ContainElements(
 			sarama.ResourceAcls{
 				Resource: sarama.Resource{
@@ -122,6 +122,26 @@ func (mr *MockCruiseControlScalerMockRecorder) BrokersWithState(ctx interface{},
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BrokersWithState", reflect.TypeOf((*MockCruiseControlScaler)(nil).BrokersWithState), varargs...)
 }
 
+// DemoteBrokers mocks base method.
+func (m *MockCruiseControlScaler) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*scale.Result, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range brokerIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DemoteBrokers", varargs...)
+	ret0, _ := ret[0].(*scale.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DemoteBrokers indicates an expected call of DemoteBrokers.
+func (mr *MockCruiseControlScalerMockRecorder) DemoteBrokers(ctx interface{}, brokerIDs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, brokerIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DemoteBrokers", reflect.TypeOf((*MockCruiseControlScaler)(nil).DemoteBrokers), varargs...)
+}
+
 // IsReady mocks base method.
 func (m *MockCruiseControlScaler) IsReady(ctx context.Context) bool {
 	m.ctrl.T.Helper()
@@ -0,0 +1,259 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/webhooks"
+)
+
+const (
+	// topicDiscovererTick is how often the clusters due for a discovery of their topics are looked for
+	topicDiscovererTick = 30 * time.Second
+	// internalTopicPrefix is the prefix of the internal topics of Kafka and Cruise Control which are never imported
+	internalTopicPrefix = "__"
+	// maxKafkaTopicNameLength is the maximum length of a KafkaTopic name
+	maxKafkaTopicNameLength = 253
+)
+
+var invalidKafkaTopicNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// TopicDiscoverer implements Runnable, it periodically creates a KafkaTopic for each topic not managed by any KafkaTopic
+// yet of the KafkaClusters with topic discovery enabled
+type TopicDiscoverer struct {
+	Client              client.Client
+	KafkaClientProvider kafkaclient.Provider
+
+	// lastDiscovered holds the time of the last discovery by cluster
+	lastDiscovered map[types.NamespacedName]time.Time
+}
+
+// SetTopicDiscovererWithManager creates a new topic discoverer and adds it to the Manager
+func SetTopicDiscovererWithManager(mgr manager.Manager, kafkaClientProvider kafkaclient.Provider) error {
+	return mgr.Add(&TopicDiscoverer{
+		Client:              mgr.GetClient(),
+		KafkaClientProvider: kafkaClientProvider,
+	})
+}
+
+// NeedLeaderElection makes only the leader operator instance discover the topics
+func (d *TopicDiscoverer) NeedLeaderElection() bool {
+	return true
+}
+
+// Start discovers the topics until the context is done
+func (d *TopicDiscoverer) Start(ctx context.Context) error {
+	log := logf.Log.WithName("topic-discoverer")
+	ctx = logr.NewContext(ctx, log)
+
+	ticker := time.NewTicker(topicDiscovererTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := d.discover(ctx, time.Now()); err != nil {
+				log.Error(err, "failed to discover topics")
+			}
+		}
+	}
+}
+
+// discover discovers the topics of the clusters whose discovery interval elapsed since their last discovery.
+// The clusters are discovered one after the other to bound the number of connections of the operator.
+func (d *TopicDiscoverer) discover(ctx context.Context, now time.Time) error {
+	log := logr.FromContextOrDiscard(ctx)
+	if d.lastDiscovered == nil {
+		d.lastDiscovered = make(map[types.NamespacedName]time.Time)
+	}
+
+	clusters := &v1beta1.KafkaClusterList{}
+	if err := d.Client.List(ctx, clusters); err != nil {
+		return errors.WrapIf(err, "failed to list KafkaClusters")
+	}
+
+	enabled := make(map[types.NamespacedName]bool, len(clusters.Items))
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+		config := cluster.Spec.TopicDiscovery
+		if config == nil || !config.Enabled || k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
+			continue
+		}
+		enabled[key] = true
+		if now.Sub(d.lastDiscovered[key]) < time.Duration(config.GetIntervalSeconds())*time.Second {
+			continue
+		}
+		d.lastDiscovered[key] = now
+		if err := d.discoverCluster(ctx, cluster); err != nil {
+			log.Error(err, "failed to discover topics of the cluster", "namespace", cluster.Namespace, "kafkaCluster", cluster.Name)
+		}
+	}
+
+	for key := range d.lastDiscovered {
+		if !enabled[key] {
+			delete(d.lastDiscovered, key)
+		}
+	}
+	return nil
+}
+
+// discoverCluster creates a KafkaTopic for each topic of the cluster which is not managed by any KafkaTopic yet.
+// The created KafkaTopics are marked as managed by the discovery, so the topics are left untouched until they are adopted
+// by setting the managedBy annotation to koperator.
+func (d *TopicDiscoverer) discoverCluster(ctx context.Context, cluster *v1beta1.KafkaCluster) error {
+	log := logr.FromContextOrDiscard(ctx)
+	excludePatterns := make([]*regexp.Regexp, 0, len(cluster.Spec.TopicDiscovery.ExcludePatterns))
+	for _, pattern := range cluster.Spec.TopicDiscovery.ExcludePatterns {
+		excludePattern, err := regexp.Compile(pattern)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "invalid topic discovery exclude pattern", "pattern", pattern)
+		}
+		excludePatterns = append(excludePatterns, excludePattern)
+	}
+
+	broker, close, err := d.KafkaClientProvider.NewFromCluster(d.Client, cluster)
+	if err != nil {
+		return errors.WrapIf(err, "could not create Kafka client, thus could not discover topics")
+	}
+	defer close()
+
+	topics, err := broker.ListTopics()
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not list topics")
+	}
+
+	kafkaTopics := &v1alpha1.KafkaTopicList{}
+	if err := d.Client.List(ctx, kafkaTopics); err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "could not list KafkaTopics")
+	}
+	managedTopics := make(map[string]struct{}, len(kafkaTopics.Items))
+	for _, kafkaTopic := range kafkaTopics.Items {
+		clusterNamespace := kafkaTopic.Spec.ClusterRef.Namespace
+		if clusterNamespace == "" {
+			clusterNamespace = kafkaTopic.Namespace
+		}
+		if kafkaTopic.Spec.ClusterRef.Name == cluster.Name && clusterNamespace == cluster.Namespace {
+			managedTopics[kafkaTopic.Spec.Name] = struct{}{}
+		}
+	}
+
+	topicNames := make([]string, 0, len(topics))
+	for topicName := range topics {
+		topicNames = append(topicNames, topicName)
+	}
+	sort.Strings(topicNames)
+
+	for _, topicName := range topicNames {
+		if _, ok := managedTopics[topicName]; ok || isTopicExcludedFromDiscovery(topicName, excludePatterns) {
+			continue
+		}
+		// Only the config set on the topic is imported, the values inherited from the brokers are left to them
+		config, err := broker.DescribeTopicConfig(topicName)
+		if err != nil {
+			return err
+		}
+		kafkaTopic := discoveredKafkaTopic(cluster, topicName, topics[topicName].NumPartitions,
+			topics[topicName].ReplicationFactor, config)
+		if err := d.Client.Create(ctx, kafkaTopic); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				log.Info("could not import topic, a KafkaTopic with the same name already exists",
+					"topic", topicName, "kafkaTopic", kafkaTopic.Name)
+				continue
+			}
+			return errorfactory.New(errorfactory.APIFailure{}, err, "could not create KafkaTopic for discovered topic", "topic", topicName)
+		}
+		log.Info("imported unmanaged topic", "topic", topicName, "kafkaTopic", kafkaTopic.Name)
+	}
+	return nil
+}
+
+func isTopicExcludedFromDiscovery(topicName string, excludePatterns []*regexp.Regexp) bool {
+	if strings.HasPrefix(topicName, internalTopicPrefix) {
+		return true
+	}
+	for _, excludePattern := range excludePatterns {
+		if excludePattern.MatchString(topicName) {
+			return true
+		}
+	}
+	return false
+}
+
+func discoveredKafkaTopic(cluster *v1beta1.KafkaCluster, topicName string, partitions int32, replicationFactor int16, config map[string]string) *v1alpha1.KafkaTopic {
+	if len(config) == 0 {
+		config = nil
+	}
+
+	return &v1alpha1.KafkaTopic{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      discoveredKafkaTopicName(cluster.Name, topicName),
+			Namespace: cluster.Namespace,
+			Labels:    applyClusterRefLabel(cluster, nil),
+			Annotations: map[string]string{
+				webhooks.TopicManagedByAnnotationKey: webhooks.TopicManagedByDiscoveryAnnotationValue,
+			},
+		},
+		Spec: v1alpha1.KafkaTopicSpec{
+			Name:              topicName,
+			Partitions:        partitions,
+			ReplicationFactor: int32(replicationFactor),
+			Config:            config,
+			ClusterRef: v1alpha1.ClusterReference{
+				Name:      cluster.Name,
+				Namespace: cluster.Namespace,
+			},
+		},
+	}
+}
+
+// discoveredKafkaTopicName returns a valid resource name for the KafkaTopic of the topic. A hash of the topic name is appended
+// when the topic name has to be changed to be a valid resource name, so different topics do not get the same name.
+func discoveredKafkaTopicName(clusterName, topicName string) string {
+	name := clusterName + "-" + topicName
+	sanitized := strings.Trim(invalidKafkaTopicNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-.")
+	if sanitized == name && len(sanitized) <= maxKafkaTopicNameLength {
+		return sanitized
+	}
+
+	hash := sha256.Sum256([]byte(topicName))
+	suffix := hex.EncodeToString(hash[:])[:8]
+	if len(sanitized) > maxKafkaTopicNameLength-len(suffix)-1 {
+		sanitized = strings.TrimRight(sanitized[:maxKafkaTopicNameLength-len(suffix)-1], "-.")
+	}
+	return sanitized + "-" + suffix
+}
@@ -0,0 +1,129 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka/mocks"
+	"github.com/banzaicloud/koperator/pkg/util"
+	"github.com/banzaicloud/koperator/pkg/webhooks"
+)
+
+func TestTopicDiscoverer(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			TopicDiscovery: &v1beta1.TopicDiscoveryConfig{
+				Enabled:         true,
+				ExcludePatterns: []string{"^tmp-"},
+				IntervalSeconds: 300,
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	_ = v1beta1.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		cluster,
+		&v1alpha1.KafkaTopic{
+			ObjectMeta: metav1.ObjectMeta{Name: "managed", Namespace: "kafka"},
+			Spec:       v1alpha1.KafkaTopicSpec{Name: "managed", ClusterRef: v1alpha1.ClusterReference{Name: "kafka"}},
+		},
+		&v1alpha1.KafkaTopic{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "other"},
+			Spec:       v1alpha1.KafkaTopicSpec{Name: "orders", ClusterRef: v1alpha1.ClusterReference{Name: "kafka"}},
+		},
+	).Build()
+
+	broker := mocks.NewMockKafkaClient(gomock.NewController(t))
+	broker.EXPECT().ListTopics().Return(map[string]sarama.TopicDetail{
+		"__consumer_offsets": {NumPartitions: 50, ReplicationFactor: 3},
+		"tmp-test":           {NumPartitions: 1, ReplicationFactor: 1},
+		"managed":            {NumPartitions: 3, ReplicationFactor: 3},
+		"orders": {NumPartitions: 6, ReplicationFactor: 3, ConfigEntries: map[string]*string{
+			"retention.ms":        util.StringPointer("1000"),
+			"min.insync.replicas": util.StringPointer("2"),
+		}},
+	}, nil).Times(1)
+	// min.insync.replicas is inherited from the brokers
+	broker.EXPECT().DescribeTopicConfig("orders").Return(map[string]string{"retention.ms": "1000"}, nil).Times(1)
+
+	provider := new(kafkaclient.MockedProvider)
+	provider.On("NewFromCluster", k8sClient, mock.Anything).Return(broker, func() {}, nil)
+
+	discoverer := &TopicDiscoverer{Client: k8sClient, KafkaClientProvider: provider}
+	now := time.Now()
+	if err := discoverer.discover(context.Background(), now); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+
+	created := &v1alpha1.KafkaTopic{}
+	if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "kafka", Name: "kafka-orders"}, created); err != nil {
+		t.Fatal("Expected the KafkaTopic of the discovered topic, got:", err)
+	}
+	assert.Equal(t, webhooks.TopicManagedByDiscoveryAnnotationValue, created.Annotations[webhooks.TopicManagedByAnnotationKey])
+	assert.Equal(t, v1alpha1.KafkaTopicSpec{
+		Name:              "orders",
+		Partitions:        6,
+		ReplicationFactor: 3,
+		Config:            map[string]string{"retention.ms": "1000"},
+		ClusterRef:        v1alpha1.ClusterReference{Name: "kafka", Namespace: "kafka"},
+	}, created.Spec)
+
+	topics := &v1alpha1.KafkaTopicList{}
+	if err := k8sClient.List(context.Background(), topics); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	assert.Len(t, topics.Items, 3)
+
+	// The topics are not listed again before the interval elapses
+	if err := discoverer.discover(context.Background(), now.Add(time.Minute)); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+}
+
+func TestDiscoveredKafkaTopicName(t *testing.T) {
+	testCases := []struct {
+		topicName string
+		expected  string
+	}{
+		{topicName: "orders.v1", expected: "kafka-orders.v1"},
+		{topicName: "Payments_V1", expected: "kafka-payments-v1-"},
+		{topicName: strings.Repeat("a", 300), expected: "kafka-" + strings.Repeat("a", 238) + "-"},
+	}
+
+	for _, testCase := range testCases {
+		name := discoveredKafkaTopicName("kafka", testCase.topicName)
+		assert.True(t, strings.HasPrefix(name, testCase.expected), name)
+		assert.LessOrEqual(t, len(name), 253)
+	}
+	assert.NotEqual(t, discoveredKafkaTopicName("kafka", "payments_v1"), discoveredKafkaTopicName("kafka", "payments-v1"))
+}
@@ -175,6 +175,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = controllers.SetConsumerLagCollectorWithManager(mgr, kafkaclient.NewDefaultProvider()); err != nil {
+		setupLog.Error(err, "unable to create consumer lag collector")
+		os.Exit(1)
+	}
+
+	if err = controllers.SetTopicDiscovererWithManager(mgr, kafkaclient.NewDefaultProvider()); err != nil {
+		setupLog.Error(err, "unable to create topic discoverer")
+		os.Exit(1)
+	}
+
 	if err = controllers.SetAlertManagerWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AlertManagerForKafka")
 		os.Exit(1)
@@ -202,12 +212,23 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create a new  kafka user reconciler
-	kafkaUserReconciler := &controllers.KafkaUserReconciler{
+	kafkaConsumerGroupReconciler := &controllers.KafkaConsumerGroupReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}
 
+	if err = controllers.SetupKafkaConsumerGroupWithManager(mgr).Complete(kafkaConsumerGroupReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaConsumerGroup")
+		os.Exit(1)
+	}
+
+	// Create a new  kafka user reconciler
+	kafkaUserReconciler := &controllers.KafkaUserReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("kafkauser-controller"),
+	}
+
 	if err = controllers.SetupKafkaUserWithManager(mgr, !certSigningDisabled, certManagerEnabled).Complete(kafkaUserReconciler); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KafkaUser")
 		os.Exit(1)
@@ -268,6 +289,27 @@ func main() {
 			setupLog.Error(err, "unable to create validating webhook", "Kind", "KafkaTopic")
 			os.Exit(1)
 		}
+		err = ctrl.NewWebhookManagedBy(mgr).For(&banzaicloudv1alpha1.KafkaTopicClass{}).
+			WithValidator(webhooks.KafkaTopicClassValidator{
+				Client:              mgr.GetClient(),
+				NewKafkaFromCluster: kafkaclient.NewFromCluster,
+				Log:                 mgr.GetLogger().WithName("webhooks").WithName("KafkaTopicClass"),
+			}).
+			Complete()
+		if err != nil {
+			setupLog.Error(err, "unable to create validating webhook", "Kind", "KafkaTopicClass")
+			os.Exit(1)
+		}
+		err = ctrl.NewWebhookManagedBy(mgr).For(&banzaicloudv1alpha1.KafkaUser{}).
+			WithValidator(webhooks.KafkaUserValidator{
+				Client: mgr.GetClient(),
+				Log:    mgr.GetLogger().WithName("webhooks").WithName("KafkaUser"),
+			}).
+			Complete()
+		if err != nil {
+			setupLog.Error(err, "unable to create validating webhook", "Kind", "KafkaUser")
+			os.Exit(1)
+		}
 	}
 
 	// +kubebuilder:scaffold:builder
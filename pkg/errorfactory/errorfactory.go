@@ -57,6 +57,11 @@ type TopicNotFound struct{ error }
 
 func (e TopicNotFound) Unwrap() error { return e.error }
 
+// ConsumerGroupNotEmpty states that the consumer group has active members
+type ConsumerGroupNotEmpty struct{ error }
+
+func (e ConsumerGroupNotEmpty) Unwrap() error { return e.error }
+
 // GracefulUpscaleFailed states that the operator failed to update the cluster gracefully
 type GracefulUpscaleFailed struct{ error }
 
@@ -82,6 +87,11 @@ type ReconcileRollingUpgrade struct{ error }
 
 func (e ReconcileRollingUpgrade) Unwrap() error { return e.error }
 
+// ReconcileKRaftMigration states that the migration from ZooKeeper to KRaft is reconciling
+type ReconcileKRaftMigration struct{ error }
+
+func (e ReconcileKRaftMigration) Unwrap() error { return e.error }
+
 // CruiseControlNotReady states that CC is not ready to receive connection
 type CruiseControlNotReady struct{ error }
 
@@ -112,6 +122,11 @@ type LoadBalancerIPNotReady struct{ error }
 
 func (e LoadBalancerIPNotReady) Unwrap() error { return e.error }
 
+// CertificateRenewalFailed states that the renewal of an issued certificate failed
+type CertificateRenewalFailed struct{ error }
+
+func (e CertificateRenewalFailed) Unwrap() error { return e.error }
+
 // New creates a new error factory error
 func New(t interface{}, err error, msg string, wrapArgs ...interface{}) error {
 	wrapped := errors.WrapIfWithDetails(err, msg, wrapArgs...)
@@ -128,6 +143,8 @@ func New(t interface{}, err error, msg string, wrapArgs ...interface{}) error {
 		return BrokersNotReady{wrapped}
 	case BrokersRequestError:
 		return BrokersRequestError{wrapped}
+	case ConsumerGroupNotEmpty:
+		return ConsumerGroupNotEmpty{wrapped}
 	case GracefulUpscaleFailed:
 		return GracefulUpscaleFailed{wrapped}
 	case TopicNotFound:
@@ -142,6 +159,8 @@ func New(t interface{}, err error, msg string, wrapArgs ...interface{}) error {
 		return FatalReconcileError{wrapped}
 	case ReconcileRollingUpgrade:
 		return ReconcileRollingUpgrade{wrapped}
+	case ReconcileKRaftMigration:
+		return ReconcileKRaftMigration{wrapped}
 	case CruiseControlNotReady:
 		return CruiseControlNotReady{wrapped}
 	case CruiseControlTaskRunning:
@@ -154,6 +173,8 @@ func New(t interface{}, err error, msg string, wrapArgs ...interface{}) error {
 		return PerBrokerConfigNotReady{wrapped}
 	case LoadBalancerIPNotReady:
 		return LoadBalancerIPNotReady{wrapped}
+	case CertificateRenewalFailed:
+		return CertificateRenewalFailed{wrapped}
 	}
 	return wrapped
 }
@@ -31,12 +31,15 @@ var errorTypes = []error{
 	BrokersRequestError{},
 	CreateTopicError{},
 	TopicNotFound{},
+	ConsumerGroupNotEmpty{},
 	GracefulUpscaleFailed{},
 	TooManyResources{},
 	InternalError{},
 	FatalReconcileError{},
+	ReconcileKRaftMigration{},
 	CruiseControlNotReady{},
 	CruiseControlTaskRunning{},
+	CertificateRenewalFailed{},
 }
 
 func TestNew(t *testing.T) {
@@ -173,6 +173,16 @@ func generateBrokerState(brokerIDs []string, cluster *banzaicloudv1beta1.KafkaCl
 		case banzaicloudv1beta1.KafkaVersion:
 			brokerState.Image = s.Image
 			brokerState.Version = s.Version
+		case banzaicloudv1beta1.LeadershipDrainState:
+			drainState := s
+			brokerState.LeadershipDrainState = &drainState
+		case banzaicloudv1beta1.ImageUpgradeState:
+			imageUpgradeState := s
+			brokerState.ImageUpgradeState = &imageUpgradeState
+		case map[string]banzaicloudv1beta1.ImageUpgradeState:
+			if imageUpgradeState, ok := s[brokerID]; ok {
+				brokerState.ImageUpgradeState = &imageUpgradeState
+			}
 		}
 		brokersState[brokerID] = brokerState
 	}
@@ -326,6 +336,7 @@ func UpdateRollingUpgradeState(c client.Client, cluster *banzaicloudv1beta1.Kafk
 
 	timeStamp := time.Format("2006-01-02 15:04:05")
 	cluster.Status.RollingUpgrade.LastSuccess = timeStamp
+	cluster.Status.RollingUpgrade.BlockedReason = ""
 
 	err := c.Status().Update(context.Background(), cluster)
 	if apierrors.IsNotFound(err) {
@@ -344,6 +355,7 @@ func UpdateRollingUpgradeState(c client.Client, cluster *banzaicloudv1beta1.Kafk
 		}
 
 		cluster.Status.RollingUpgrade.LastSuccess = timeStamp
+		cluster.Status.RollingUpgrade.BlockedReason = ""
 
 		err = c.Status().Update(context.Background(), cluster)
 		if apierrors.IsNotFound(err) {
@@ -359,6 +371,234 @@ func UpdateRollingUpgradeState(c client.Client, cluster *banzaicloudv1beta1.Kafk
 	return nil
 }
 
+// UpdateLastRestartRequest records the restart request whose requested brokers have all been restarted
+func UpdateLastRestartRequest(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, restartRequest string, logger logr.Logger) error {
+	typeMeta := cluster.TypeMeta
+
+	cluster.Status.RollingUpgrade.LastRestartRequest = restartRequest
+
+	err := c.Status().Update(context.Background(), cluster)
+	if apierrors.IsNotFound(err) {
+		err = c.Update(context.Background(), cluster)
+	}
+	if err != nil {
+		if !apierrors.IsConflict(err) {
+			return errors.WrapIf(err, "could not update last restart request")
+		}
+		err := c.Get(context.TODO(), types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+		}, cluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not get config for updating status")
+		}
+
+		cluster.Status.RollingUpgrade.LastRestartRequest = restartRequest
+
+		err = c.Status().Update(context.Background(), cluster)
+		if apierrors.IsNotFound(err) {
+			err = c.Update(context.Background(), cluster)
+		}
+		if err != nil {
+			return errors.WrapIf(err, "could not update last restart request")
+		}
+	}
+	// update loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	logger.Info("last restart request updated", "restartRequest", restartRequest)
+	return nil
+}
+
+// UpdateRollingUpgradeBlockedReason records why the rolling upgrade cannot restart the next broker
+func UpdateRollingUpgradeBlockedReason(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, reason string, logger logr.Logger) error {
+	typeMeta := cluster.TypeMeta
+
+	cluster.Status.RollingUpgrade.BlockedReason = reason
+
+	err := c.Status().Update(context.Background(), cluster)
+	if apierrors.IsNotFound(err) {
+		err = c.Update(context.Background(), cluster)
+	}
+	if err != nil {
+		if !apierrors.IsConflict(err) {
+			return errors.WrapIf(err, "could not update rolling upgrade blocked reason")
+		}
+		err := c.Get(context.TODO(), types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+		}, cluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not get config for updating status")
+		}
+
+		cluster.Status.RollingUpgrade.BlockedReason = reason
+
+		err = c.Status().Update(context.Background(), cluster)
+		if apierrors.IsNotFound(err) {
+			err = c.Update(context.Background(), cluster)
+		}
+		if err != nil {
+			return errors.WrapIf(err, "could not update rolling upgrade blocked reason")
+		}
+	}
+	// update loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	logger.Info("rolling upgrade blocked reason updated", "reason", reason)
+	return nil
+}
+
+// UpdatePreferredLeaderElectionStatus updates the preferred leader election status of the KafkaCluster
+func UpdatePreferredLeaderElectionStatus(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, status banzaicloudv1beta1.PreferredLeaderElectionStatus, logger logr.Logger) error {
+	typeMeta := cluster.TypeMeta
+
+	cluster.Status.PreferredLeaderElection = status
+
+	err := c.Status().Update(context.Background(), cluster)
+	if apierrors.IsNotFound(err) {
+		err = c.Update(context.Background(), cluster)
+	}
+	if err != nil {
+		if !apierrors.IsConflict(err) {
+			return errors.WrapIf(err, "could not update preferred leader election status")
+		}
+		err := c.Get(context.TODO(), types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+		}, cluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not get config for updating status")
+		}
+
+		cluster.Status.PreferredLeaderElection = status
+
+		err = c.Status().Update(context.Background(), cluster)
+		if apierrors.IsNotFound(err) {
+			err = c.Update(context.Background(), cluster)
+		}
+		if err != nil {
+			return errors.WrapIf(err, "could not update preferred leader election status")
+		}
+	}
+	// update loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	logger.Info("preferred leader election status updated", "pendingBrokers", status.PendingBrokers)
+	return nil
+}
+
+// UpdateImageRollbackStatus updates the status of the rolled back broker image upgrade, a nil status clears it
+func UpdateImageRollbackStatus(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, status *banzaicloudv1beta1.ImageRollbackStatus, logger logr.Logger) error {
+	typeMeta := cluster.TypeMeta
+
+	cluster.Status.ImageRollback = status
+
+	err := c.Status().Update(context.Background(), cluster)
+	if apierrors.IsNotFound(err) {
+		err = c.Update(context.Background(), cluster)
+	}
+	if err != nil {
+		if !apierrors.IsConflict(err) {
+			return errors.WrapIf(err, "could not update image rollback status")
+		}
+		err := c.Get(context.TODO(), types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+		}, cluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not get config for updating status")
+		}
+
+		cluster.Status.ImageRollback = status
+
+		err = c.Status().Update(context.Background(), cluster)
+		if apierrors.IsNotFound(err) {
+			err = c.Update(context.Background(), cluster)
+		}
+		if err != nil {
+			return errors.WrapIf(err, "could not update image rollback status")
+		}
+	}
+	// update loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	logger.Info("image rollback status updated")
+	return nil
+}
+
+// UpdateKRaftMigrationState updates the phase of the ZooKeeper to KRaft migration in the status of the KafkaCluster
+func UpdateKRaftMigrationState(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, state banzaicloudv1beta1.ClusterState, logger logr.Logger) error {
+	typeMeta := cluster.TypeMeta
+
+	cluster.Status.KRaftMigrationState = state
+
+	err := c.Status().Update(context.Background(), cluster)
+	if apierrors.IsNotFound(err) {
+		err = c.Update(context.Background(), cluster)
+	}
+	if err != nil {
+		if !apierrors.IsConflict(err) {
+			return errors.WrapIf(err, "could not update KRaft migration state")
+		}
+		err := c.Get(context.TODO(), types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+		}, cluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not get config for updating status")
+		}
+
+		cluster.Status.KRaftMigrationState = state
+
+		err = c.Status().Update(context.Background(), cluster)
+		if apierrors.IsNotFound(err) {
+			err = c.Update(context.Background(), cluster)
+		}
+		if err != nil {
+			return errors.WrapIf(err, "could not update KRaft migration state")
+		}
+	}
+	// update loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	logger.Info("KRaft migration state updated", "state", state)
+	return nil
+}
+
+// UpdateProtocolVersion updates the protocol version the brokers of the cluster are pinned to
+func UpdateProtocolVersion(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, protocolVersion string, logger logr.Logger) error {
+	typeMeta := cluster.TypeMeta
+
+	cluster.Status.ProtocolVersion = protocolVersion
+
+	err := c.Status().Update(context.Background(), cluster)
+	if apierrors.IsNotFound(err) {
+		err = c.Update(context.Background(), cluster)
+	}
+	if err != nil {
+		if !apierrors.IsConflict(err) {
+			return errors.WrapIf(err, "could not update protocol version")
+		}
+		err := c.Get(context.TODO(), types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+		}, cluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not get config for updating status")
+		}
+
+		cluster.Status.ProtocolVersion = protocolVersion
+
+		err = c.Status().Update(context.Background(), cluster)
+		if apierrors.IsNotFound(err) {
+			err = c.Update(context.Background(), cluster)
+		}
+		if err != nil {
+			return errors.WrapIf(err, "could not update protocol version")
+		}
+	}
+	// update loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	logger.Info("protocol version updated", "protocolVersion", protocolVersion)
+	return nil
+}
+
 func UpdateListenerStatuses(ctx context.Context, c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, intListenerStatuses, extListenerStatuses map[string]banzaicloudv1beta1.ListenerStatusList) error {
 	logger := logr.FromContextOrDiscard(ctx)
 
@@ -404,6 +644,51 @@ func UpdateListenerStatuses(ctx context.Context, c client.Client, cluster *banza
 	return nil
 }
 
+// UpdateClusterID persists the KRaft cluster id of the KafkaCluster into its status
+func UpdateClusterID(ctx context.Context, c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, clusterID string) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	typeMeta := cluster.TypeMeta
+
+	cluster.Status.ClusterID = clusterID
+
+	err := c.Status().Update(ctx, cluster)
+	if apierrors.IsNotFound(err) {
+		err = c.Update(ctx, cluster)
+	}
+	if err != nil {
+		if !apierrors.IsConflict(err) {
+			return errors.WrapIf(err, "could not update cluster id")
+		}
+		err := c.Get(ctx, types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+		}, cluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not get config for updating cluster id")
+		}
+
+		// the cluster id must never change once it has been set
+		if cluster.Status.ClusterID != "" {
+			cluster.TypeMeta = typeMeta
+			return nil
+		}
+		cluster.Status.ClusterID = clusterID
+
+		err = c.Status().Update(ctx, cluster)
+		if apierrors.IsNotFound(err) {
+			err = c.Update(ctx, cluster)
+		}
+		if err != nil {
+			return errors.WrapIf(err, "could not update cluster id")
+		}
+	}
+	// update loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	logger.Info("cluster id updated", "clusterID", clusterID)
+	return nil
+}
+
 func CreateInternalListenerStatuses(kafkaCluster *banzaicloudv1beta1.KafkaCluster, externalListenerStatus map[string]banzaicloudv1beta1.ListenerStatusList) (map[string]banzaicloudv1beta1.ListenerStatusList, map[string]banzaicloudv1beta1.ListenerStatusList) {
 	intListenerStatuses := make(map[string]banzaicloudv1beta1.ListenerStatusList, len(kafkaCluster.Spec.ListenersConfig.InternalListeners))
 	controllerIntListenerStatuses := make(map[string]banzaicloudv1beta1.ListenerStatusList)
@@ -27,7 +27,11 @@ import (
 )
 
 var log = logf.Log.WithName("kafka_util")
+
 var apiVersion = sarama.V2_6_0_0
+
+// scramAPIVersion is the version the SCRAM credential requests are sent with, they are only supported from Kafka 2.7
+var scramAPIVersion = sarama.V2_7_0_0
 var clientId = "koperator"
 
 // KafkaClient is the exported interface for kafka operations
@@ -37,21 +41,61 @@ type KafkaClient interface {
 	CreateTopic(*CreateTopicOptions) error
 	EnsurePartitionCount(string, int32) (bool, error)
 	EnsureTopicConfig(string, map[string]*string) error
+	// EnsureReplicationFactor reassigns the partitions of the topic to match the desired replication factor,
+	// the replicas are placed according to the placement when it is not nil
+	EnsureReplicationFactor(topic string, desired int16, placement *ReplicaPlacement) (PartitionReassignment, error)
+	// EnsureReplicaPlacement reassigns the partitions of the topic whose replicas are not placed according to the placement
+	EnsureReplicaPlacement(topic string, placement ReplicaPlacement) (PartitionReassignment, error)
 	DeleteTopic(string, bool) error
 	GetTopic(string) (*sarama.TopicDetail, error)
 	DescribeTopic(string) (*sarama.TopicMetadata, error)
-	CreateUserACLs(v1alpha1.KafkaAccessType, v1alpha1.KafkaPatternType, string, string) error
-	ListUserACLs() ([]sarama.ResourceAcls, error)
-	DeleteUserACLs(string, v1alpha1.KafkaPatternType) error
+	// DescribeTopicConfig returns the config set on the topic without the values inherited from the brokers
+	DescribeTopicConfig(topic string) (map[string]string, error)
+	// ListUserACLs returns the ACLs bound to the principal of the user
+	ListUserACLs(dn string) ([]sarama.ResourceAcls, error)
+	// ReconcileUserACLs creates the ACLs needed by the topic grants and ACLs of the user and deletes the other ACLs
+	// bound to its principal, it returns the created and deleted ACLs
+	ReconcileUserACLs(dn string, grants []v1alpha1.UserTopicGrant, acls []v1alpha1.UserACL) ([]string, []string, error)
+	// EnsureUserScramCredentials registers the SCRAM password of the user, replacing the registered one only when update is set
+	EnsureUserScramCredentials(user string, authentication v1alpha1.UserAuthenticationType, password []byte, update bool) error
+	// DeleteUserScramCredentials removes the SCRAM credentials of the user
+	DeleteUserScramCredentials(user string) error
+	// AlterUserQuotas sets the client quotas of the user, removing the ones not set
+	AlterUserQuotas(user string, quotas *v1alpha1.UserQuotas) error
+	// DescribeUserQuotas returns the effective client quotas of the user
+	DescribeUserQuotas(user string) (*v1alpha1.UserQuotas, error)
 
 	Brokers() map[int32]string
 	DescribeCluster() ([]*sarama.Broker, int32, error)
+	// ClusterID returns the id of the Kafka cluster the brokers belong to
+	ClusterID() (string, error)
+	// MetadataVersion returns the finalized metadata.version feature level of the KRaft cluster
+	// and the highest level every broker supports
+	MetadataVersion() (finalized int16, supported int16, err error)
+	// UpdateMetadataVersion raises the metadata.version feature of the KRaft cluster to the given level
+	UpdateMetadataVersion(level int16) error
 
 	// AllOfflineReplicas returns the list of unique offline replica (broker) ids
 	AllOfflineReplicas() ([]int32, error)
 
 	// OutOfSyncReplicas returns the list of unique out of sync replica (broker) ids
 	OutOfSyncReplicas() ([]int32, error)
+	// UnsafePartitionsForRestart returns the partitions which would become under-min-ISR or leaderless if the given broker was restarted
+	UnsafePartitionsForRestart(brokerID int32) ([]UnsafePartition, error)
+	// LeaderPartitionCount returns the number of partitions the given broker is the leader of
+	LeaderPartitionCount(brokerID int32) (int, error)
+	// PreferredLeaderPartitions returns the partitions the given broker is the preferred leader of but not the current leader,
+	// while it is in sync
+	PreferredLeaderPartitions(brokerID int32) (map[string][]int32, error)
+	// ElectLeaders runs preferred leader election for the given partitions and returns the number of partitions
+	// whose leader has been changed. Partitions already led by their preferred leader are not reported as errors.
+	ElectLeaders(partitions map[string][]int32) (int, error)
+	// PreferredReplicaPartitions returns the partitions the given broker is the first, preferred replica of
+	PreferredReplicaPartitions(brokerID int32) (map[string][]int32, error)
+	// RestorePreferredReplica moves the given broker back to the front of the replicas of the given partitions
+	// without moving any data, it returns the number of partitions whose replica order has been changed and the
+	// partitions of the topics with a reassignment in progress, whose order is left to be restored later
+	RestorePreferredReplica(brokerID int32, partitions map[string][]int32) (int, map[string][]int32, error)
 
 	AlterPerBrokerConfig(int32, map[string]*string, bool) error
 	DescribePerBrokerConfig(int32, []string) ([]*sarama.ConfigEntry, error)
@@ -59,7 +103,16 @@ type KafkaClient interface {
 	AlterClusterWideConfig(map[string]*string, bool) error
 	DescribeClusterWideConfig() ([]sarama.ConfigEntry, error)
 
-	TopicMetaToStatus(meta *sarama.TopicMetadata) *v1alpha1.KafkaTopicStatus
+	// ListConsumerGroups returns the ids of the consumer groups of the cluster
+	ListConsumerGroups() ([]string, error)
+	// ConsumerGroupLag returns the lag of the consumer group by topic and partition
+	ConsumerGroupLag(group string) (map[string]map[int32]int64, error)
+	// DescribeConsumerGroup returns the members of the consumer group and the lag of its partitions
+	DescribeConsumerGroup(group string) (*ConsumerGroupDescription, error)
+	// ResetConsumerGroupOffsets resets the committed offsets of the consumer group while it has no active members
+	ResetConsumerGroupOffsets(group string, reset *v1alpha1.ConsumerGroupOffsetReset) (map[string]map[int32]int64, error)
+	// TopicSizes returns the total size on disk of all the replicas of the topics in bytes by topic
+	TopicSizes() (map[string]int64, error)
 
 	Open() error
 	Close() error
@@ -67,15 +120,19 @@ type KafkaClient interface {
 
 type kafkaClient struct {
 	KafkaClient
-	opts    *KafkaConfig
-	admin   sarama.ClusterAdmin
-	client  sarama.Client
-	timeout time.Duration
-	brokers []*sarama.Broker
+	opts   *KafkaConfig
+	admin  sarama.ClusterAdmin
+	client sarama.Client
+	// scramAdmin is the cluster admin sending the SCRAM credential requests, it's only opened when they are used
+	scramAdmin sarama.ClusterAdmin
+	timeout    time.Duration
+	brokers    []*sarama.Broker
 
 	// client funcs for mocking
 	newClusterAdmin func([]string, *sarama.Config) (sarama.ClusterAdmin, error)
 	newClient       func([]string, *sarama.Config) (sarama.Client, error)
+	listOffsets     func(*sarama.Broker, *sarama.OffsetRequest) (*sarama.OffsetResponse, error)
+	requestBroker   func(addr string, apiKey, apiVersion int16, body []byte) ([]byte, error)
 }
 
 func New(opts *KafkaConfig) KafkaClient {
@@ -85,6 +142,8 @@ func New(opts *KafkaConfig) KafkaClient {
 	}
 	kclient.newClusterAdmin = sarama.NewClusterAdmin
 	kclient.newClient = sarama.NewClient
+	kclient.listOffsets = (*sarama.Broker).GetAvailableOffsets
+	kclient.requestBroker = kclient.sendRequest
 	return kclient
 }
 
@@ -111,6 +170,9 @@ func (k *kafkaClient) Open() error {
 
 func (k *kafkaClient) Close() error {
 	k.client.Close()
+	if k.scramAdmin != nil {
+		k.scramAdmin.Close()
+	}
 	return k.admin.Close()
 }
 
@@ -160,6 +222,22 @@ func (k *kafkaClient) DescribeCluster() (brokers []*sarama.Broker, controllerID
 	return
 }
 
+func (k *kafkaClient) ClusterID() (string, error) {
+	controller, err := k.client.Controller()
+	if err != nil {
+		return "", errorfactory.New(errorfactory.BrokersNotReady{}, err, "could not find the controller broker")
+	}
+	// request no topics since only the cluster id is needed
+	response, err := controller.GetMetadata(sarama.NewMetadataRequest(apiVersion, []string{}))
+	if err != nil {
+		return "", errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not get cluster metadata")
+	}
+	if response.ClusterID == nil || *response.ClusterID == "" {
+		return "", errorfactory.New(errorfactory.BrokersRequestError{}, fmt.Errorf("empty cluster id"), "could not get cluster id")
+	}
+	return *response.ClusterID, nil
+}
+
 func (k *kafkaClient) getSaramaConfig() (config *sarama.Config) {
 	config = sarama.NewConfig()
 	if k.opts.UseSSL {
@@ -0,0 +1,345 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/IBM/sarama"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+const (
+	// consumerGroupStateEmpty is the state of a consumer group without active members
+	consumerGroupStateEmpty = "Empty"
+	// consumerGroupStateDead is the state of a consumer group without members and metadata
+	consumerGroupStateDead = "Dead"
+)
+
+// ConsumerGroupDescription describes the members and the committed offsets of a consumer group
+type ConsumerGroupDescription struct {
+	// State is the state of the group, e.g. Empty, Stable or Dead
+	State string
+	// Members are the active members of the group
+	Members []ConsumerGroupMember
+	// Lag is the number of messages the group is behind the end of the partitions by topic and partition
+	Lag map[string]map[int32]int64
+}
+
+// ConsumerGroupMember describes an active member of a consumer group
+type ConsumerGroupMember struct {
+	MemberID   string
+	ClientID   string
+	ClientHost string
+	// Assignment holds the partitions assigned to the member by topic
+	Assignment map[string][]int32
+}
+
+// DescribeConsumerGroup returns the members of the consumer group, and the lag of the partitions it has committed offsets for
+func (k *kafkaClient) DescribeConsumerGroup(group string) (*ConsumerGroupDescription, error) {
+	groups, err := k.admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing consumer group", "group", group)
+	}
+	if len(groups) != 1 {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, errors.New("unexpected number of consumer groups"),
+			"error describing consumer group", "group", group)
+	}
+	if !errors.Is(groups[0].Err, sarama.ErrNoError) {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, groups[0].Err, "error describing consumer group", "group", group)
+	}
+
+	description := &ConsumerGroupDescription{
+		State: groups[0].State,
+	}
+	memberIDs := make([]string, 0, len(groups[0].Members))
+	for memberID := range groups[0].Members {
+		memberIDs = append(memberIDs, memberID)
+	}
+	sort.Strings(memberIDs)
+	for _, memberID := range memberIDs {
+		member := groups[0].Members[memberID]
+		consumerGroupMember := ConsumerGroupMember{
+			MemberID:   member.MemberId,
+			ClientID:   member.ClientId,
+			ClientHost: member.ClientHost,
+		}
+		// Members of groups not using the consumer protocol have no partition assignment
+		if assignment, err := member.GetMemberAssignment(); err == nil && assignment != nil {
+			consumerGroupMember.Assignment = assignment.Topics
+		}
+		description.Members = append(description.Members, consumerGroupMember)
+	}
+
+	if description.Lag, err = k.ConsumerGroupLag(group); err != nil {
+		return nil, err
+	}
+	return description, nil
+}
+
+// ListConsumerGroups returns the ids of the consumer groups of the cluster
+func (k *kafkaClient) ListConsumerGroups() ([]string, error) {
+	groups, err := k.admin.ListConsumerGroups()
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error listing consumer groups")
+	}
+	groupIDs := make([]string, 0, len(groups))
+	for group := range groups {
+		groupIDs = append(groupIDs, group)
+	}
+	sort.Strings(groupIDs)
+	return groupIDs, nil
+}
+
+// ConsumerGroupLag returns the number of messages the consumer group is behind the end of the partitions
+// it has committed offsets for by topic and partition
+func (k *kafkaClient) ConsumerGroupLag(group string) (map[string]map[int32]int64, error) {
+	committed, err := k.committedOffsets(group)
+	if err != nil {
+		return nil, err
+	}
+	newest, err := k.endOffsets(committed)
+	if err != nil {
+		return nil, err
+	}
+	lag := make(map[string]map[int32]int64, len(committed))
+	for topic, partitions := range committed {
+		lag[topic] = make(map[int32]int64, len(partitions))
+		for partition, offset := range partitions {
+			lag[topic][partition] = partitionLag(offset, newest[topic][partition])
+		}
+	}
+	return lag, nil
+}
+
+// endOffsets returns the end offsets of the partitions by topic and partition. The offsets are listed with a single
+// request to the leader of each partition instead of one request per partition.
+func (k *kafkaClient) endOffsets(partitions map[string]map[int32]int64) (map[string]map[int32]int64, error) {
+	leaders := make(map[int32]*sarama.Broker)
+	requests := make(map[int32]*sarama.OffsetRequest)
+	for topic, topicPartitions := range partitions {
+		for partition := range topicPartitions {
+			leader, err := k.client.Leader(topic, partition)
+			if err != nil {
+				return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error finding the leader of partition",
+					"topic", topic, "partition", partition)
+			}
+			request, ok := requests[leader.ID()]
+			if !ok {
+				// Version 1 returns a single offset for each partition
+				request = &sarama.OffsetRequest{Version: 1}
+				leaders[leader.ID()] = leader
+				requests[leader.ID()] = request
+			}
+			request.AddBlock(topic, partition, sarama.OffsetNewest, 1)
+		}
+	}
+
+	offsets := make(map[string]map[int32]int64, len(partitions))
+	for brokerID, request := range requests {
+		response, err := k.listOffsets(leaders[brokerID], request)
+		if err != nil {
+			return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error listing the end offsets of partitions",
+				"broker", brokerID)
+		}
+		for topic, blocks := range response.Blocks {
+			for partition, block := range blocks {
+				if !errors.Is(block.Err, sarama.ErrNoError) {
+					return nil, errorfactory.New(errorfactory.BrokersRequestError{}, block.Err, "error getting the end offset of partition",
+						"topic", topic, "partition", partition)
+				}
+				if offsets[topic] == nil {
+					offsets[topic] = make(map[int32]int64, len(blocks))
+				}
+				offsets[topic][partition] = block.Offset
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// ResetConsumerGroupOffsets commits the offsets the reset strategy gives for the partitions of the consumer group,
+// and returns the new offsets by topic and partition. The offsets are only reset while the group has no active members.
+func (k *kafkaClient) ResetConsumerGroupOffsets(group string, reset *v1alpha1.ConsumerGroupOffsetReset) (map[string]map[int32]int64, error) {
+	if err := reset.Validate(); err != nil {
+		return nil, errorfactory.New(errorfactory.InternalError{}, err, "invalid offset reset", "group", group)
+	}
+
+	groups, err := k.admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing consumer group", "group", group)
+	}
+	for _, description := range groups {
+		if description.State != consumerGroupStateEmpty && description.State != consumerGroupStateDead {
+			return nil, errorfactory.New(errorfactory.ConsumerGroupNotEmpty{},
+				fmt.Errorf("consumer group is %s with %d members", description.State, len(description.Members)),
+				"offsets can only be reset while the consumer group is empty", "group", group)
+		}
+	}
+
+	committed, err := k.committedOffsets(group)
+	if err != nil {
+		return nil, err
+	}
+	topics := reset.Topics
+	if len(topics) == 0 {
+		for topic := range committed {
+			topics = append(topics, topic)
+		}
+		sort.Strings(topics)
+	}
+
+	offsets := make(map[string]map[int32]int64, len(topics))
+	for _, topic := range topics {
+		partitions, err := k.client.Partitions(topic)
+		if err != nil {
+			return nil, errorfactory.New(errorfactory.TopicNotFound{}, err, "error listing the partitions of topic", "topic", topic)
+		}
+		for _, partition := range partitions {
+			current, hasCommitted := committed[topic][partition]
+			if reset.Strategy == v1alpha1.OffsetResetShiftBy && !hasCommitted {
+				// There is no committed offset to shift
+				continue
+			}
+			oldest, newest, atTimestamp, err := k.partitionOffsets(topic, partition, reset)
+			if err != nil {
+				return nil, err
+			}
+			if offsets[topic] == nil {
+				offsets[topic] = make(map[int32]int64, len(partitions))
+			}
+			offsets[topic][partition] = resetOffset(reset, current, oldest, newest, atTimestamp)
+		}
+	}
+
+	if err = k.commitOffsets(group, offsets); err != nil {
+		return nil, err
+	}
+	return offsets, nil
+}
+
+// committedOffsets returns the committed offsets of the consumer group by topic and partition
+func (k *kafkaClient) committedOffsets(group string) (map[string]map[int32]int64, error) {
+	response, err := k.admin.ListConsumerGroupOffsets(group, nil)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error listing consumer group offsets", "group", group)
+	}
+	committed := make(map[string]map[int32]int64, len(response.Blocks))
+	for topic, partitions := range response.Blocks {
+		for partition, block := range partitions {
+			// Partitions without committed offset are reported with offset -1
+			if block == nil || block.Offset < 0 || !errors.Is(block.Err, sarama.ErrNoError) {
+				continue
+			}
+			if committed[topic] == nil {
+				committed[topic] = make(map[int32]int64, len(partitions))
+			}
+			committed[topic][partition] = block.Offset
+		}
+	}
+	return committed, nil
+}
+
+// partitionOffsets returns the earliest and end offsets of the partition, and the offset of the first message produced
+// at or after the timestamp of the reset when the to-timestamp strategy is used
+func (k *kafkaClient) partitionOffsets(topic string, partition int32, reset *v1alpha1.ConsumerGroupOffsetReset) (int64, int64, int64, error) {
+	oldest, err := k.client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return 0, 0, 0, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error getting the earliest offset of partition",
+			"topic", topic, "partition", partition)
+	}
+	newest, err := k.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, 0, 0, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error getting the end offset of partition",
+			"topic", topic, "partition", partition)
+	}
+	atTimestamp := int64(-1)
+	if reset.Strategy == v1alpha1.OffsetResetToTimestamp {
+		if atTimestamp, err = k.client.GetOffset(topic, partition, reset.Timestamp.UnixMilli()); err != nil {
+			return 0, 0, 0, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error getting the offset of timestamp",
+				"topic", topic, "partition", partition)
+		}
+	}
+	return oldest, newest, atTimestamp, nil
+}
+
+// commitOffsets commits the offsets of the consumer group as a client outside of the group generation
+func (k *kafkaClient) commitOffsets(group string, offsets map[string]map[int32]int64) error {
+	coordinator, err := k.client.Coordinator(group)
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersRequestError{}, err, "error finding the coordinator of consumer group", "group", group)
+	}
+	request := &sarama.OffsetCommitRequest{
+		Version:                 2,
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: -1,
+		RetentionTime:           -1,
+	}
+	for topic, partitions := range offsets {
+		for partition, offset := range partitions {
+			request.AddBlock(topic, partition, offset, 0, "")
+		}
+	}
+	response, err := coordinator.CommitOffset(request)
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersRequestError{}, err, "error committing consumer group offsets", "group", group)
+	}
+	for topic, partitions := range response.Errors {
+		for partition, kerr := range partitions {
+			if !errors.Is(kerr, sarama.ErrNoError) {
+				return errorfactory.New(errorfactory.BrokersRequestError{}, kerr, "error committing consumer group offset",
+					"group", group, "topic", topic, "partition", partition)
+			}
+		}
+	}
+	return nil
+}
+
+// resetOffset returns the offset the partition is reset to, given the committed offset, the earliest and end offsets of
+// the partition, and the offset of the reset timestamp which is -1 when no message was produced after the timestamp
+func resetOffset(reset *v1alpha1.ConsumerGroupOffsetReset, current, oldest, newest, atTimestamp int64) int64 {
+	switch reset.Strategy {
+	case v1alpha1.OffsetResetToEarliest:
+		return oldest
+	case v1alpha1.OffsetResetToTimestamp:
+		if atTimestamp < 0 {
+			return newest
+		}
+		return atTimestamp
+	case v1alpha1.OffsetResetShiftBy:
+		shifted := current + *reset.Shift
+		if shifted < oldest {
+			return oldest
+		}
+		if shifted > newest {
+			return newest
+		}
+		return shifted
+	default:
+		return newest
+	}
+}
+
+// partitionLag returns the number of messages between the committed offset and the end of the partition
+func partitionLag(committed, newest int64) int64 {
+	if committed > newest {
+		return 0
+	}
+	return newest - committed
+}
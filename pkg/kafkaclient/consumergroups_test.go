@@ -0,0 +1,179 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+func TestDescribeConsumerGroup(t *testing.T) {
+	client := newOpenedMockClient()
+
+	description, err := client.DescribeConsumerGroup("test-group")
+	if err != nil {
+		t.Fatal("Expected no error on DescribeConsumerGroup, got:", err)
+	}
+	assert.Equal(t, &ConsumerGroupDescription{
+		State: "Stable",
+		Members: []ConsumerGroupMember{
+			{
+				MemberID:   "consumer-1",
+				ClientID:   "test-client",
+				ClientHost: "/10.0.0.1",
+				Assignment: map[string][]int32{"test-topic": {0}},
+			},
+		},
+		Lag: map[string]map[int32]int64{"test-topic": {0: 5}},
+	}, description)
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err = client.DescribeConsumerGroup("test-group"); err == nil {
+		t.Error("Expected error on DescribeConsumerGroup, got nil")
+	}
+}
+
+func TestListConsumerGroups(t *testing.T) {
+	client := newOpenedMockClient()
+
+	groups, err := client.ListConsumerGroups()
+	if err != nil {
+		t.Fatal("Expected no error on ListConsumerGroups, got:", err)
+	}
+	assert.Equal(t, []string{"empty-group", "test-group"}, groups)
+
+	lag, err := client.ConsumerGroupLag("empty-group")
+	if err != nil {
+		t.Fatal("Expected no error on ConsumerGroupLag, got:", err)
+	}
+	assert.Equal(t, map[string]map[int32]int64{"test-topic": {0: 5}}, lag)
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err = client.ListConsumerGroups(); err == nil {
+		t.Error("Expected error on ListConsumerGroups, got nil")
+	}
+}
+
+func TestConsumerGroupLagEndOffsets(t *testing.T) {
+	client := newOpenedMockClient()
+
+	// The end offsets of the partitions led by a broker are listed with one request
+	var requests int
+	client.listOffsets = func(broker *sarama.Broker, request *sarama.OffsetRequest) (*sarama.OffsetResponse, error) {
+		requests++
+		return mockListOffsets(broker, request)
+	}
+	lag, err := client.ConsumerGroupLag("test-group")
+	if err != nil {
+		t.Fatal("Expected no error on ConsumerGroupLag, got:", err)
+	}
+	assert.Equal(t, map[string]map[int32]int64{"test-topic": {0: 5}}, lag)
+	assert.Equal(t, 1, requests)
+
+	client.listOffsets = func(broker *sarama.Broker, request *sarama.OffsetRequest) (*sarama.OffsetResponse, error) {
+		response := &sarama.OffsetResponse{Version: request.Version}
+		response.AddTopicPartition("test-topic", 0, 10)
+		response.Blocks["test-topic"][0].Err = sarama.ErrNotLeaderForPartition
+		return response, nil
+	}
+	if _, err = client.ConsumerGroupLag("test-group"); err == nil {
+		t.Error("Expected error on ConsumerGroupLag, got nil")
+	}
+}
+
+func TestResetConsumerGroupOffsetsNotEmpty(t *testing.T) {
+	client := newOpenedMockClient()
+
+	_, err := client.ResetConsumerGroupOffsets("test-group", &v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetToEarliest})
+	var notEmpty errorfactory.ConsumerGroupNotEmpty
+	if !errors.As(err, &notEmpty) {
+		t.Error("Expected consumer group not empty error, got:", err)
+	}
+
+	if _, err = client.ResetConsumerGroupOffsets("empty-group", &v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetShiftBy}); err == nil {
+		t.Error("Expected error on shift-by reset without shift, got nil")
+	}
+}
+
+func TestResetOffset(t *testing.T) {
+	t.Parallel()
+	shift := func(n int64) *int64 { return &n }
+	testCases := []struct {
+		testName       string
+		reset          v1alpha1.ConsumerGroupOffsetReset
+		current        int64
+		atTimestamp    int64
+		expectedOffset int64
+	}{
+		{
+			testName:       "to-earliest",
+			reset:          v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetToEarliest},
+			current:        50,
+			expectedOffset: 10,
+		},
+		{
+			testName:       "to-latest",
+			reset:          v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetToLatest},
+			current:        50,
+			expectedOffset: 100,
+		},
+		{
+			testName:       "to-timestamp",
+			reset:          v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetToTimestamp},
+			current:        50,
+			atTimestamp:    30,
+			expectedOffset: 30,
+		},
+		{
+			testName:       "to-timestamp after the last message",
+			reset:          v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetToTimestamp},
+			current:        50,
+			atTimestamp:    -1,
+			expectedOffset: 100,
+		},
+		{
+			testName:       "shift-by backward",
+			reset:          v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetShiftBy, Shift: shift(-20)},
+			current:        50,
+			expectedOffset: 30,
+		},
+		{
+			testName:       "shift-by beyond the earliest offset",
+			reset:          v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetShiftBy, Shift: shift(-60)},
+			current:        50,
+			expectedOffset: 10,
+		},
+		{
+			testName:       "shift-by beyond the end offset",
+			reset:          v1alpha1.ConsumerGroupOffsetReset{Strategy: v1alpha1.OffsetResetShiftBy, Shift: shift(60)},
+			current:        50,
+			expectedOffset: 100,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.expectedOffset, resetOffset(&test.reset, test.current, 10, 100, test.atTimestamp))
+		})
+	}
+}
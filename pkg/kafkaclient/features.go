@@ -0,0 +1,361 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/IBM/sarama"
+
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// The feature requests are not supported by sarama, they are encoded here following the Kafka protocol
+const (
+	apiVersionsKey        int16 = 18
+	apiVersionsVersion    int16 = 3
+	updateFeaturesKey     int16 = 57
+	updateFeaturesVersion int16 = 1
+
+	// supportedFeaturesTag and finalizedFeaturesTag are the tags of the feature fields of the ApiVersions response
+	supportedFeaturesTag = 0
+	finalizedFeaturesTag = 2
+
+	metadataVersionFeature = "metadata.version"
+	// upgradeTypeUpgrade only allows raising the level of the feature
+	upgradeTypeUpgrade int8 = 1
+)
+
+// MetadataVersion returns the finalized metadata.version feature level of the KRaft cluster
+// and the highest level every broker supports
+func (k *kafkaClient) MetadataVersion() (int16, int16, error) {
+	if len(k.brokers) == 0 {
+		return 0, 0, errorfactory.New(errorfactory.BrokersNotReady{}, errors.New("no brokers"), "could not get metadata version")
+	}
+	var finalized, supported int16 = 0, -1
+	for _, broker := range k.brokers {
+		response, err := k.requestBroker(broker.Addr(), apiVersionsKey, apiVersionsVersion, encodeApiVersionsRequest())
+		if err != nil {
+			return 0, 0, errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not get api versions", "brokerId", broker.ID())
+		}
+		brokerFinalized, brokerSupported, err := decodeMetadataVersion(response)
+		if err != nil {
+			return 0, 0, errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not get metadata version", "brokerId", broker.ID())
+		}
+		if brokerFinalized > finalized {
+			finalized = brokerFinalized
+		}
+		if supported < 0 || brokerSupported < supported {
+			supported = brokerSupported
+		}
+	}
+	return finalized, supported, nil
+}
+
+// UpdateMetadataVersion raises the metadata.version feature of the KRaft cluster to the given level,
+// the request is forwarded to the active controller by the broker
+func (k *kafkaClient) UpdateMetadataVersion(level int16) error {
+	if len(k.brokers) == 0 {
+		return errorfactory.New(errorfactory.BrokersNotReady{}, errors.New("no brokers"), "could not update metadata version")
+	}
+	response, err := k.requestBroker(k.brokers[0].Addr(), updateFeaturesKey, updateFeaturesVersion,
+		encodeUpdateFeaturesRequest(metadataVersionFeature, level, k.timeout))
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not update metadata version")
+	}
+	if err := decodeUpdateFeaturesResponse(response); err != nil {
+		return errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not update metadata version", "level", level)
+	}
+	return nil
+}
+
+// sendRequest sends a request to the broker on a new connection and returns the body of the response
+func (k *kafkaClient) sendRequest(addr string, apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	dialer := &net.Dialer{Timeout: k.timeout}
+	var conn net.Conn
+	var err error
+	if k.opts.UseSSL {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, k.opts.TLSConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not open connection to broker", "address", addr)
+	}
+	defer conn.Close()
+
+	// The broker may wait for the timeout of the request before it responds
+	if err := conn.SetDeadline(time.Now().Add(2 * k.timeout)); err != nil {
+		return nil, err
+	}
+	return roundTrip(conn, apiKey, apiVersion, body)
+}
+
+// roundTrip writes the request with a v2 request header and reads its response, the flexible v1 response header
+// is expected for every request but ApiVersions
+func roundTrip(conn io.ReadWriter, apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	const correlationID int32 = 1
+	request := &protocolEncoder{}
+	request.putInt16(apiKey)
+	request.putInt16(apiVersion)
+	request.putInt32(correlationID)
+	request.putInt16(int16(len(clientId)))
+	request.WriteString(clientId)
+	request.putEmptyTaggedFields()
+	request.Write(body)
+
+	frame := &protocolEncoder{}
+	frame.putInt32(int32(request.Len()))
+	frame.Write(request.Bytes())
+	if _, err := conn.Write(frame.Bytes()); err != nil {
+		return nil, errors.WrapIf(err, "could not send request")
+	}
+
+	size := make([]byte, 4)
+	if _, err := io.ReadFull(conn, size); err != nil {
+		return nil, errors.WrapIf(err, "could not read response")
+	}
+	response := make([]byte, binary.BigEndian.Uint32(size))
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return nil, errors.WrapIf(err, "could not read response")
+	}
+
+	d := &protocolDecoder{data: response}
+	if id := d.int32(); d.err == nil && id != correlationID {
+		return nil, errors.NewWithDetails("unexpected correlation id", "correlationId", id)
+	}
+	if apiKey != apiVersionsKey {
+		d.skipTaggedFields()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.data, nil
+}
+
+func encodeApiVersionsRequest() []byte {
+	e := &protocolEncoder{}
+	e.putCompactString(clientId)
+	e.putCompactString("unknown")
+	e.putEmptyTaggedFields()
+	return e.Bytes()
+}
+
+// decodeMetadataVersion returns the finalized and the highest supported metadata.version level of the ApiVersions response
+func decodeMetadataVersion(data []byte) (int16, int16, error) {
+	d := &protocolDecoder{data: data}
+	if code := d.int16(); code != 0 {
+		return 0, 0, sarama.KError(code)
+	}
+	for i := d.compactArrayLength(); i > 0 && d.err == nil; i-- {
+		// api key, min and max version
+		d.int16()
+		d.int16()
+		d.int16()
+		d.skipTaggedFields()
+	}
+	// throttle time
+	d.int32()
+
+	var finalized, supported int16
+	for i := d.uvarint(); i > 0 && d.err == nil; i-- {
+		tag := d.uvarint()
+		field := &protocolDecoder{data: d.bytes(int(d.uvarint()))}
+		switch tag {
+		case supportedFeaturesTag:
+			for j := field.compactArrayLength(); j > 0 && field.err == nil; j-- {
+				name := field.compactString()
+				field.int16()
+				maxVersion := field.int16()
+				field.skipTaggedFields()
+				if name == metadataVersionFeature {
+					supported = maxVersion
+				}
+			}
+		case finalizedFeaturesTag:
+			for j := field.compactArrayLength(); j > 0 && field.err == nil; j-- {
+				name := field.compactString()
+				maxVersionLevel := field.int16()
+				field.int16()
+				field.skipTaggedFields()
+				if name == metadataVersionFeature {
+					finalized = maxVersionLevel
+				}
+			}
+		}
+		if field.err != nil {
+			d.err = field.err
+		}
+	}
+	if d.err != nil {
+		return 0, 0, d.err
+	}
+	if supported == 0 {
+		return 0, 0, errors.New("metadata.version is not supported by the broker")
+	}
+	return finalized, supported, nil
+}
+
+func encodeUpdateFeaturesRequest(feature string, level int16, timeout time.Duration) []byte {
+	e := &protocolEncoder{}
+	e.putInt32(int32(timeout.Milliseconds()))
+	e.putCompactArrayLength(1)
+	e.putCompactString(feature)
+	e.putInt16(level)
+	e.putInt8(upgradeTypeUpgrade)
+	e.putEmptyTaggedFields()
+	// validate only
+	e.putInt8(0)
+	e.putEmptyTaggedFields()
+	return e.Bytes()
+}
+
+func decodeUpdateFeaturesResponse(data []byte) error {
+	d := &protocolDecoder{data: data}
+	// throttle time
+	d.int32()
+	if err := featureError(d.int16(), d.compactString()); err != nil {
+		return err
+	}
+	for i := d.compactArrayLength(); i > 0 && d.err == nil; i-- {
+		feature := d.compactString()
+		if err := featureError(d.int16(), d.compactString()); err != nil {
+			return errors.WrapIfWithDetails(err, "could not update feature", "feature", feature)
+		}
+		d.skipTaggedFields()
+	}
+	return d.err
+}
+
+func featureError(code int16, message string) error {
+	if code == 0 {
+		return nil
+	}
+	if message == "" {
+		return sarama.KError(code)
+	}
+	return fmt.Errorf("%w: %s", sarama.KError(code), message)
+}
+
+// protocolEncoder encodes the fields of the flexible versions of the Kafka protocol
+type protocolEncoder struct {
+	bytes.Buffer
+}
+
+func (e *protocolEncoder) putInt8(v int8) {
+	e.WriteByte(byte(v))
+}
+
+func (e *protocolEncoder) putInt16(v int16) {
+	e.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+}
+
+func (e *protocolEncoder) putInt32(v int32) {
+	e.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+}
+
+func (e *protocolEncoder) putUvarint(v uint64) {
+	e.Write(binary.AppendUvarint(nil, v))
+}
+
+func (e *protocolEncoder) putCompactString(s string) {
+	e.putUvarint(uint64(len(s) + 1))
+	e.WriteString(s)
+}
+
+func (e *protocolEncoder) putCompactArrayLength(n int) {
+	e.putUvarint(uint64(n + 1))
+}
+
+func (e *protocolEncoder) putEmptyTaggedFields() {
+	e.putUvarint(0)
+}
+
+// protocolDecoder decodes the fields of the flexible versions of the Kafka protocol, the first error is kept in err
+type protocolDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *protocolDecoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.data) < n {
+		d.err = errors.New("malformed response")
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *protocolDecoder) int16() int16 {
+	if b := d.bytes(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *protocolDecoder) int32() int32 {
+	if b := d.bytes(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *protocolDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = errors.New("malformed response")
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// compactString decodes a compact string, a null string is returned as empty
+func (d *protocolDecoder) compactString() string {
+	n := d.uvarint()
+	if n == 0 {
+		return ""
+	}
+	return string(d.bytes(int(n - 1)))
+}
+
+func (d *protocolDecoder) compactArrayLength() int {
+	n := d.uvarint()
+	if n == 0 {
+		return 0
+	}
+	return int(n - 1)
+}
+
+func (d *protocolDecoder) skipTaggedFields() {
+	for i := d.uvarint(); i > 0 && d.err == nil; i-- {
+		d.uvarint()
+		d.bytes(int(d.uvarint()))
+	}
+}
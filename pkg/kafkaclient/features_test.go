@@ -0,0 +1,121 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestMetadataVersion(t *testing.T) {
+	client := newOpenedMockClient()
+
+	finalized, supported, err := client.MetadataVersion()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if finalized != 7 || supported != 9 {
+		t.Errorf("Expected finalized level 7 and supported level 9, got: %d and %d", finalized, supported)
+	}
+
+	client.brokers = nil
+	if _, _, err := client.MetadataVersion(); err == nil {
+		t.Error("Expected error without brokers, got nil")
+	}
+}
+
+func TestUpdateMetadataVersion(t *testing.T) {
+	client := newOpenedMockClient()
+
+	if err := client.UpdateMetadataVersion(9); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if err := client.UpdateMetadataVersion(10); err == nil {
+		t.Error("Expected error for unsupported level, got nil")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	requests := make(chan []byte, 1)
+	go func() {
+		defer server.Close()
+		size := make([]byte, 4)
+		if _, err := io.ReadFull(server, size); err != nil {
+			return
+		}
+		request := make([]byte, binary.BigEndian.Uint32(size))
+		if _, err := io.ReadFull(server, request); err != nil {
+			return
+		}
+		requests <- request
+
+		// correlation id, empty tagged fields and the body
+		response := &protocolEncoder{}
+		response.putInt32(1)
+		response.putEmptyTaggedFields()
+		response.WriteString("body")
+		frame := &protocolEncoder{}
+		frame.putInt32(int32(response.Len()))
+		frame.Write(response.Bytes())
+		_, _ = server.Write(frame.Bytes())
+	}()
+
+	body, err := roundTrip(client, updateFeaturesKey, updateFeaturesVersion, []byte("request"))
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if string(body) != "body" {
+		t.Error("Expected the body of the response, got:", string(body))
+	}
+
+	expected := &protocolEncoder{}
+	expected.putInt16(updateFeaturesKey)
+	expected.putInt16(updateFeaturesVersion)
+	expected.putInt32(1)
+	expected.putInt16(int16(len(clientId)))
+	expected.WriteString(clientId)
+	expected.putEmptyTaggedFields()
+	expected.WriteString("request")
+	if request := <-requests; !bytes.Equal(request, expected.Bytes()) {
+		t.Errorf("Expected request %v, got: %v", expected.Bytes(), request)
+	}
+}
+
+func TestDecodeMetadataVersion(t *testing.T) {
+	// ZooKeeper based brokers do not support the metadata.version feature
+	response := &protocolEncoder{}
+	response.putInt16(0)
+	response.putCompactArrayLength(0)
+	response.putInt32(0)
+	response.putEmptyTaggedFields()
+	if _, _, err := decodeMetadataVersion(response.Bytes()); err == nil {
+		t.Error("Expected error without the metadata.version feature, got nil")
+	}
+
+	if _, _, err := decodeMetadataVersion([]byte{0, 0, 2}); err == nil {
+		t.Error("Expected error for malformed response, got nil")
+	}
+
+	// unsupported version error
+	if _, _, err := decodeMetadataVersion([]byte{0, 35}); err == nil {
+		t.Error("Expected error for error code, got nil")
+	}
+}
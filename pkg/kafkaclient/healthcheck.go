@@ -15,9 +15,16 @@
 package kafkaclient
 
 import (
+	"fmt"
+	"strconv"
+
 	"emperror.dev/errors"
+
+	"github.com/IBM/sarama"
 )
 
+const minInSyncReplicasConfigName = "min.insync.replicas"
+
 func (k *kafkaClient) AllOfflineReplicas() ([]int32, error) {
 	availableTopics, err := k.client.Topics()
 	if err != nil {
@@ -91,3 +98,139 @@ func (k *kafkaClient) OutOfSyncReplicas() ([]int32, error) {
 	}
 	return brokerIDs, nil
 }
+
+// UnsafePartition describes a partition that would become under-min-ISR or leaderless if one of its in sync replicas was restarted
+type UnsafePartition struct {
+	Topic             string
+	Partition         int32
+	InSyncReplicas    int
+	MinInSyncReplicas int
+	Leaderless        bool
+}
+
+func (p UnsafePartition) String() string {
+	if p.Leaderless {
+		return fmt.Sprintf("%s-%d (leaderless)", p.Topic, p.Partition)
+	}
+	return fmt.Sprintf("%s-%d (isr: %d, min.insync.replicas: %d)", p.Topic, p.Partition, p.InSyncReplicas, p.MinInSyncReplicas)
+}
+
+func (k *kafkaClient) UnsafePartitionsForRestart(brokerID int32) ([]UnsafePartition, error) {
+	availableTopics, err := k.client.Topics()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not fetch topics")
+	}
+	var unsafePartitions []UnsafePartition
+	for _, topic := range availableTopics {
+		partitions, err := k.client.Partitions(topic)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not fetch partition", "topic", topic)
+		}
+		// min.insync.replicas is only fetched for topics the broker is an in sync replica of
+		minISR := 0
+		for _, partition := range partitions {
+			replicas, err := k.client.Replicas(topic, partition)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not fetch replicas", "topic", topic, "partition", partition)
+			}
+			isrReplicas, err := k.client.InSyncReplicas(topic, partition)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not fetch isr replicas", "topic", topic, "partition", partition)
+			}
+			if !containsBroker(isrReplicas, brokerID) {
+				continue
+			}
+			if minISR == 0 {
+				if minISR, err = k.topicMinInSyncReplicas(topic); err != nil {
+					return nil, err
+				}
+			}
+			if unsafePartition, unsafe := checkPartitionRestartSafety(replicas, isrReplicas, minISR); unsafe {
+				unsafePartition.Topic = topic
+				unsafePartition.Partition = partition
+				unsafePartitions = append(unsafePartitions, unsafePartition)
+			}
+		}
+	}
+	return unsafePartitions, nil
+}
+
+// checkPartitionRestartSafety checks whether restarting one of the in sync replicas of a partition would leave it
+// leaderless or under-min-ISR. Partitions which would become unsafe by restarting any of their replicas even when every
+// replica is in sync (e.g. topics with a replication factor of 1) are not reported since waiting would not make them safe.
+func checkPartitionRestartSafety(replicas, isrReplicas []int32, minISR int) (UnsafePartition, bool) {
+	remainingISR := len(isrReplicas) - 1
+	unsafePartition := UnsafePartition{
+		InSyncReplicas:    len(isrReplicas),
+		MinInSyncReplicas: minISR,
+	}
+	switch {
+	case remainingISR == 0:
+		unsafePartition.Leaderless = true
+		return unsafePartition, len(replicas) > 1
+	case remainingISR < minISR:
+		return unsafePartition, len(replicas)-1 >= minISR
+	default:
+		return unsafePartition, false
+	}
+}
+
+func (k *kafkaClient) topicMinInSyncReplicas(topic string) (int, error) {
+	configs, err := k.admin.DescribeConfig(sarama.ConfigResource{
+		Type:        sarama.TopicResource,
+		Name:        topic,
+		ConfigNames: []string{minInSyncReplicasConfigName},
+	})
+	if err != nil {
+		return 0, errors.WrapIfWithDetails(err, "could not describe topic config", "topic", topic)
+	}
+	for _, config := range configs {
+		if config.Name != minInSyncReplicasConfigName {
+			continue
+		}
+		minISR, err := strconv.Atoi(config.Value)
+		if err != nil {
+			return 0, errors.WrapIfWithDetails(err, "invalid min.insync.replicas", "topic", topic, "value", config.Value)
+		}
+		return minISR, nil
+	}
+	// Kafka's default
+	return 1, nil
+}
+
+func containsBroker(brokerIDs []int32, brokerID int32) bool {
+	for _, id := range brokerIDs {
+		if id == brokerID {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *kafkaClient) LeaderPartitionCount(brokerID int32) (int, error) {
+	availableTopics, err := k.client.Topics()
+	if err != nil {
+		return 0, errors.WrapIf(err, "could not fetch topics")
+	}
+	leaderCount := 0
+	for _, topic := range availableTopics {
+		partitions, err := k.client.Partitions(topic)
+		if err != nil {
+			return 0, errors.WrapIfWithDetails(err, "could not fetch partition", "topic", topic)
+		}
+		for _, partition := range partitions {
+			leader, err := k.client.Leader(topic, partition)
+			if err != nil {
+				// Leaderless partitions are not led by the broker either
+				if errors.Is(err, sarama.ErrLeaderNotAvailable) {
+					continue
+				}
+				return 0, errors.WrapIfWithDetails(err, "could not fetch leader", "topic", topic, "partition", partition)
+			}
+			if leader.ID() == brokerID {
+				leaderCount++
+			}
+		}
+	}
+	return leaderCount, nil
+}
@@ -0,0 +1,46 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"testing"
+)
+
+func TestCheckPartitionRestartSafety(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Replicas   []int32
+		ISR        []int32
+		MinISR     int
+		Unsafe     bool
+		Leaderless bool
+	}{
+		{Name: "fully in sync", Replicas: []int32{1, 2, 3}, ISR: []int32{1, 2, 3}, MinISR: 2},
+		{Name: "under-min-ISR", Replicas: []int32{1, 2, 3}, ISR: []int32{1, 2}, MinISR: 2, Unsafe: true},
+		{Name: "leaderless", Replicas: []int32{1, 2, 3}, ISR: []int32{1}, MinISR: 1, Unsafe: true, Leaderless: true},
+		{Name: "single replica", Replicas: []int32{1}, ISR: []int32{1}, MinISR: 1, Leaderless: true},
+		{Name: "replication factor equals min.insync.replicas", Replicas: []int32{1, 2}, ISR: []int32{1, 2}, MinISR: 2},
+	}
+
+	for _, test := range testCases {
+		partition, unsafe := checkPartitionRestartSafety(test.Replicas, test.ISR, test.MinISR)
+		if unsafe != test.Unsafe {
+			t.Errorf("%s: expected unsafe: %v, got: %v", test.Name, test.Unsafe, unsafe)
+		}
+		if partition.Leaderless != test.Leaderless {
+			t.Errorf("%s: expected leaderless: %v, got: %v", test.Name, test.Leaderless, partition.Leaderless)
+		}
+	}
+}
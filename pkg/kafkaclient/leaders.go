@@ -0,0 +1,174 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"emperror.dev/errors"
+	"github.com/IBM/sarama"
+
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+func (k *kafkaClient) PreferredLeaderPartitions(brokerID int32) (map[string][]int32, error) {
+	availableTopics, err := k.client.Topics()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not fetch topics")
+	}
+	preferredLeaderPartitions := make(map[string][]int32)
+	for _, topic := range availableTopics {
+		partitions, err := k.client.Partitions(topic)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not fetch partition", "topic", topic)
+		}
+		for _, partition := range partitions {
+			replicas, err := k.client.Replicas(topic, partition)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not fetch replicas", "topic", topic, "partition", partition)
+			}
+			// The preferred leader is the first replica of the partition
+			if len(replicas) == 0 || replicas[0] != brokerID {
+				continue
+			}
+			isrReplicas, err := k.client.InSyncReplicas(topic, partition)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not fetch isr replicas", "topic", topic, "partition", partition)
+			}
+			if !containsBroker(isrReplicas, brokerID) {
+				continue
+			}
+			leader, err := k.client.Leader(topic, partition)
+			if err != nil && !errors.Is(err, sarama.ErrLeaderNotAvailable) {
+				return nil, errors.WrapIfWithDetails(err, "could not fetch leader", "topic", topic, "partition", partition)
+			}
+			if leader != nil && leader.ID() == brokerID {
+				continue
+			}
+			preferredLeaderPartitions[topic] = append(preferredLeaderPartitions[topic], partition)
+		}
+	}
+	return preferredLeaderPartitions, nil
+}
+
+func (k *kafkaClient) ElectLeaders(partitions map[string][]int32) (int, error) {
+	results, err := k.admin.ElectLeaders(sarama.PreferredElection, partitions)
+	if err != nil {
+		return 0, errors.WrapIf(err, "could not elect preferred leaders")
+	}
+
+	var combinedErr error
+	elected := 0
+	for topic, partitionResults := range results {
+		for partition, result := range partitionResults {
+			switch {
+			case errors.Is(result.ErrorCode, sarama.ErrNoError):
+				elected++
+			case errors.Is(result.ErrorCode, sarama.ErrElectionNotNeeded):
+			default:
+				combinedErr = errors.Append(combinedErr, errors.WrapIfWithDetails(result.ErrorCode, "could not elect preferred leader",
+					"topic", topic, "partition", partition))
+			}
+		}
+	}
+	return elected, combinedErr
+}
+
+func (k *kafkaClient) PreferredReplicaPartitions(brokerID int32) (map[string][]int32, error) {
+	availableTopics, err := k.client.Topics()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not fetch topics")
+	}
+	preferredReplicaPartitions := make(map[string][]int32)
+	for _, topic := range availableTopics {
+		partitions, err := k.client.Partitions(topic)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not fetch partition", "topic", topic)
+		}
+		for _, partition := range partitions {
+			replicas, err := k.client.Replicas(topic, partition)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not fetch replicas", "topic", topic, "partition", partition)
+			}
+			if len(replicas) > 0 && replicas[0] == brokerID {
+				preferredReplicaPartitions[topic] = append(preferredReplicaPartitions[topic], partition)
+			}
+		}
+	}
+	return preferredReplicaPartitions, nil
+}
+
+func (k *kafkaClient) RestorePreferredReplica(brokerID int32, partitions map[string][]int32) (int, map[string][]int32, error) {
+	restored := 0
+	pending := make(map[string][]int32)
+	for topic, topicPartitions := range partitions {
+		meta, err := k.DescribeTopic(topic)
+		// The topic has been deleted since
+		if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+			continue
+		}
+		if err != nil {
+			return restored, nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing topic", "topic", topic)
+		}
+		assignment, changed := preferredReplicaAssignment(meta.Partitions, brokerID, topicPartitions)
+		if changed == 0 {
+			continue
+		}
+
+		// Submitting the assignment would cancel the reassignment in progress, the order is restored once it is completed
+		partitionIDs := make([]int32, 0, len(meta.Partitions))
+		for _, partition := range meta.Partitions {
+			partitionIDs = append(partitionIDs, partition.ID)
+		}
+		ongoing, err := k.admin.ListPartitionReassignments(topic, partitionIDs)
+		if err != nil {
+			return restored, nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error listing partition reassignments", "topic", topic)
+		}
+		if len(ongoing[topic]) > 0 {
+			pending[topic] = topicPartitions
+			continue
+		}
+
+		if err = k.admin.AlterPartitionReassignments(topic, assignment); err != nil {
+			return restored, nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error reassigning partitions", "topic", topic)
+		}
+		restored += changed
+	}
+	return restored, pending, nil
+}
+
+// preferredReplicaAssignment returns the replica assignment of the partitions indexed by partition id which moves the broker
+// to the front of the replicas of the given partitions, and the number of partitions whose replica order is changed.
+// The current replicas of the other partitions are kept, as a partition without replicas in the request would cancel
+// its reassignment. Partitions the broker is not a replica of anymore are left as is.
+func preferredReplicaAssignment(partitions []*sarama.PartitionMetadata, brokerID int32, preferred []int32) ([][]int32, int) {
+	assignment := make([][]int32, partitionCount(partitions))
+	changed := 0
+	for _, partition := range partitions {
+		if len(partition.Replicas) == 0 || partition.Replicas[0] == brokerID ||
+			!containsBroker(preferred, partition.ID) || !containsBroker(partition.Replicas, brokerID) {
+			assignment[partition.ID] = append([]int32(nil), partition.Replicas...)
+			continue
+		}
+		replicas := make([]int32, 0, len(partition.Replicas))
+		replicas = append(replicas, brokerID)
+		for _, replica := range partition.Replicas {
+			if replica != brokerID {
+				replicas = append(replicas, replica)
+			}
+		}
+		assignment[partition.ID] = replicas
+		changed++
+	}
+	return assignment, changed
+}
@@ -0,0 +1,107 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestElectLeaders(t *testing.T) {
+	client := newOpenedMockClient()
+
+	if elected, err := client.ElectLeaders(map[string][]int32{"test-topic": {0, 1, 3}}); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if elected != 2 {
+		t.Error("Expected 2 elected partitions, got:", elected)
+	}
+
+	if elected, err := client.ElectLeaders(map[string][]int32{"test-topic": {0, 2}}); err == nil {
+		t.Error("Expected error for partition with unavailable preferred leader, got nil")
+	} else if elected != 1 {
+		t.Error("Expected 1 elected partition, got:", elected)
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err := client.ElectLeaders(map[string][]int32{"test-topic": {0}}); err == nil {
+		t.Error("Expected error on ElectLeaders, got nil")
+	}
+}
+
+func TestRestorePreferredReplica(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := client.admin.(*mockClusterAdmin)
+
+	restored, pending, err := client.RestorePreferredReplica(0, map[string][]int32{"demoted-topic": {0}})
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if restored != 1 || len(pending) != 0 {
+		t.Errorf("Expected 1 restored and no pending partition, got: %d and %v", restored, pending)
+	}
+	expected := [][]int32{{0, 1, 2}, {2, 0, 1}}
+	if assignment := admin.mockReassignments["demoted-topic"]; !reflect.DeepEqual(assignment, expected) {
+		t.Errorf("Expected assignment %v, got: %v", expected, assignment)
+	}
+
+	// the reassignment in progress is not cancelled, the partitions are left to be restored later
+	restored, pending, err = client.RestorePreferredReplica(0, map[string][]int32{"reassigning-topic": {0}})
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if restored != 0 || !reflect.DeepEqual(pending, map[string][]int32{"reassigning-topic": {0}}) {
+		t.Errorf("Expected no restored and 1 pending partition, got: %d and %v", restored, pending)
+	}
+	if _, ok := admin.mockReassignments["reassigning-topic"]; ok {
+		t.Error("Expected no reassignment while another one is in progress")
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, _, err := client.RestorePreferredReplica(0, map[string][]int32{"demoted-topic": {0}}); err == nil {
+		t.Error("Expected error on RestorePreferredReplica, got nil")
+	}
+}
+
+func TestPreferredReplicaAssignment(t *testing.T) {
+	partitions := []*sarama.PartitionMetadata{
+		// demoted partition
+		{ID: 0, Replicas: []int32{1, 2, 0}},
+		// partition the broker was not the preferred replica of
+		{ID: 1, Replicas: []int32{2, 0, 1}},
+		// partition already restored
+		{ID: 2, Replicas: []int32{0, 1, 2}},
+		// partition moved away from the broker since
+		{ID: 3, Replicas: []int32{1, 2, 3}},
+	}
+
+	assignment, changed := preferredReplicaAssignment(partitions, 0, []int32{0, 2, 3})
+	if changed != 1 {
+		t.Error("Expected 1 changed partition, got:", changed)
+	}
+	// the current replicas of the other partitions are kept so their reassignment is not cancelled
+	expected := [][]int32{{0, 1, 2}, {2, 0, 1}, {0, 1, 2}, {1, 2, 3}}
+	for i := range expected {
+		if len(assignment[i]) != len(expected[i]) {
+			t.Fatalf("Expected assignment %v, got: %v", expected, assignment)
+		}
+		for j := range expected[i] {
+			if assignment[i][j] != expected[i][j] {
+				t.Fatalf("Expected assignment %v, got: %v", expected, assignment)
+			}
+		}
+	}
+}
@@ -31,6 +31,12 @@ type mockClusterAdmin struct {
 	failOps    bool
 	mockTopics map[string]sarama.TopicDetail
 	mockACLs   map[sarama.Resource]*sarama.ResourceAcls
+	// mockScramCredentials holds the registered SCRAM passwords by user and mechanism
+	mockScramCredentials map[string]map[sarama.ScramMechanismType][]byte
+	// mockQuotas holds the client quotas by user, the default quotas of the users are held with the empty name
+	mockQuotas map[string]map[string]float64
+	// mockReassignments holds the last replica assignment submitted by topic
+	mockReassignments map[string][][]int32
 }
 
 func NewMockFromCluster(client client.Client, cluster *v1beta1.KafkaCluster) (KafkaClient, func(), error) {
@@ -43,6 +49,10 @@ func newEmptyMockClusterAdmin(failOps bool) *mockClusterAdmin {
 		mockTopics: make(map[string]sarama.TopicDetail, 0),
 		mockACLs:   make(map[sarama.Resource]*sarama.ResourceAcls, 0),
 		failOps:    failOps,
+
+		mockScramCredentials: make(map[string]map[sarama.ScramMechanismType][]byte),
+		mockQuotas:           make(map[string]map[string]float64),
+		mockReassignments:    make(map[string][][]int32),
 	}
 }
 
@@ -74,6 +84,8 @@ func newMockClient() *kafkaClient {
 		timeout:         time.Duration(kafkaDefaultTimeout) * time.Second,
 		newClusterAdmin: newMockClusterAdmin,
 		newClient:       newMockKafkaClient,
+		listOffsets:     mockListOffsets,
+		requestBroker:   mockRequestBroker,
 	}
 }
 
@@ -129,6 +141,17 @@ func (m *mockClusterAdmin) DescribeTopics(topics []string) ([]*sarama.TopicMetad
 				Err:        sarama.ErrNoError,
 			},
 		}, nil
+	case "demoted-topic", "reassigning-topic":
+		return []*sarama.TopicMetadata{
+			{
+				Name: topics[0],
+				Partitions: []*sarama.PartitionMetadata{
+					{ID: 0, Replicas: []int32{1, 2, 0}},
+					{ID: 1, Replicas: []int32{2, 0, 1}},
+				},
+				Err: sarama.ErrNoError,
+			},
+		}, nil
 	case "not-exists":
 		return []*sarama.TopicMetadata{}, nil
 	case "with-error":
@@ -209,13 +232,32 @@ func (m *mockClusterAdmin) CreateACL(resource sarama.Resource, acl sarama.Acl) e
 	return nil
 }
 
+func (m *mockClusterAdmin) CreateACLs(resourceACLs []*sarama.ResourceAcls) error {
+	for _, resourceACL := range resourceACLs {
+		for _, acl := range resourceACL.Acls {
+			if err := m.CreateACL(resourceACL.Resource, *acl); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (m *mockClusterAdmin) ListAcls(filter sarama.AclFilter) ([]sarama.ResourceAcls, error) {
 	m.Lock()
 	defer m.Unlock()
 
-	acls := make([]sarama.ResourceAcls, len(m.mockACLs))
-	for _, acl := range m.mockACLs {
-		acls = append(acls, *acl)
+	acls := make([]sarama.ResourceAcls, 0, len(m.mockACLs))
+	for _, resourceAcls := range m.mockACLs {
+		matching := sarama.ResourceAcls{Resource: resourceAcls.Resource}
+		for _, acl := range resourceAcls.Acls {
+			if filter.Principal == nil || *filter.Principal == acl.Principal {
+				matching.Acls = append(matching.Acls, acl)
+			}
+		}
+		if len(matching.Acls) > 0 {
+			acls = append(acls, matching)
+		}
 	}
 	return acls, nil
 }
@@ -227,26 +269,383 @@ func (m *mockClusterAdmin) DeleteACL(filter sarama.AclFilter, validateOnly bool)
 	if m.failOps {
 		return []sarama.MatchingAcl{}, errors.New("bad create acl")
 	}
-	switch *filter.Principal {
-	case "test-user":
-		return []sarama.MatchingAcl{{}}, nil
-	case "with-error":
-		return []sarama.MatchingAcl{{Err: sarama.ErrUnknown}}, nil
-	default:
-		// for mock it's enough to erase the whole map
-		m.mockACLs = make(map[sarama.Resource]*sarama.ResourceAcls, 0)
-		return []sarama.MatchingAcl{{}}, nil
+	var matches []sarama.MatchingAcl
+	for resource, resourceAcls := range m.mockACLs {
+		if !aclFilterMatchesResource(filter, resource) {
+			continue
+		}
+		kept := make([]*sarama.Acl, 0, len(resourceAcls.Acls))
+		for _, acl := range resourceAcls.Acls {
+			if aclFilterMatchesAcl(filter, acl) {
+				matches = append(matches, sarama.MatchingAcl{Err: sarama.ErrNoError, Resource: resource, Acl: *acl})
+				continue
+			}
+			kept = append(kept, acl)
+		}
+		if len(kept) == 0 {
+			delete(m.mockACLs, resource)
+		} else {
+			resourceAcls.Acls = kept
+		}
 	}
+	return matches, nil
+}
+
+func aclFilterMatchesResource(filter sarama.AclFilter, resource sarama.Resource) bool {
+	return (filter.ResourceType == sarama.AclResourceAny || filter.ResourceType == resource.ResourceType) &&
+		(filter.ResourceName == nil || *filter.ResourceName == resource.ResourceName) &&
+		(filter.ResourcePatternTypeFilter == sarama.AclPatternAny || filter.ResourcePatternTypeFilter == resource.ResourcePatternType)
+}
+
+func aclFilterMatchesAcl(filter sarama.AclFilter, acl *sarama.Acl) bool {
+	return *filter.Principal == acl.Principal &&
+		(filter.Host == nil || *filter.Host == acl.Host) &&
+		(filter.Operation == sarama.AclOperationAny || filter.Operation == acl.Operation) &&
+		(filter.PermissionType == sarama.AclPermissionAny || filter.PermissionType == acl.PermissionType)
 }
 
 func (m *mockClusterAdmin) DescribeConfig(resource sarama.ConfigResource) ([]sarama.ConfigEntry, error) {
-	return []sarama.ConfigEntry{}, nil
+	m.Lock()
+	defer m.Unlock()
+
+	detail, ok := m.mockTopics[resource.Name]
+	if resource.Type != sarama.TopicResource || !ok {
+		return []sarama.ConfigEntry{}, nil
+	}
+	// The topics inherit a config set on the brokers
+	entries := []sarama.ConfigEntry{{Name: "compression.type", Value: "producer", Source: sarama.SourceStaticBroker}}
+	for name, value := range detail.ConfigEntries {
+		if value != nil {
+			entries = append(entries, sarama.ConfigEntry{Name: name, Value: *value, Source: sarama.SourceTopic})
+		}
+	}
+	if len(resource.ConfigNames) == 0 {
+		return entries, nil
+	}
+	named := make([]sarama.ConfigEntry, 0, len(entries))
+	for _, entry := range entries {
+		for _, name := range resource.ConfigNames {
+			if entry.Name == name {
+				named = append(named, entry)
+			}
+		}
+	}
+	return named, nil
 }
 
 func (m *mockClusterAdmin) Controller() (*sarama.Broker, error) {
 	return &sarama.Broker{}, nil
 }
 
+func (m *mockClusterAdmin) Coordinator(group string) (*sarama.Broker, error) {
+	return &sarama.Broker{}, nil
+}
+
+func (m *mockClusterAdmin) Leader(topic string, partitionID int32) (*sarama.Broker, error) {
+	if m.failOps {
+		return nil, errors.New("bad leader")
+	}
+	return &sarama.Broker{}, nil
+}
+
+// mockListOffsets returns the end offsets of the partitions of test-topic
+func mockListOffsets(broker *sarama.Broker, request *sarama.OffsetRequest) (*sarama.OffsetResponse, error) {
+	response := &sarama.OffsetResponse{Version: request.Version}
+	response.AddTopicPartition("test-topic", 0, 10)
+	response.AddTopicPartition("test-topic", 1, 10)
+	return response, nil
+}
+
+// mockRequestBroker answers the feature requests of a KRaft cluster whose finalized metadata.version is 7
+// while the brokers support up to level 9
+func mockRequestBroker(addr string, apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	e := &protocolEncoder{}
+	switch apiKey {
+	case apiVersionsKey:
+		// error code, no api keys and throttle time
+		e.putInt16(0)
+		e.putCompactArrayLength(0)
+		e.putInt32(0)
+		supported := &protocolEncoder{}
+		supported.putCompactArrayLength(1)
+		supported.putCompactString(metadataVersionFeature)
+		supported.putInt16(1)
+		supported.putInt16(9)
+		supported.putEmptyTaggedFields()
+		finalized := &protocolEncoder{}
+		finalized.putCompactArrayLength(1)
+		finalized.putCompactString(metadataVersionFeature)
+		finalized.putInt16(7)
+		finalized.putInt16(7)
+		finalized.putEmptyTaggedFields()
+		e.putUvarint(2)
+		for tag, field := range []*protocolEncoder{supported, nil, finalized} {
+			if field == nil {
+				continue
+			}
+			e.putUvarint(uint64(tag))
+			e.putUvarint(uint64(field.Len()))
+			e.Write(field.Bytes())
+		}
+	case updateFeaturesKey:
+		d := &protocolDecoder{data: body}
+		d.int32()
+		d.compactArrayLength()
+		d.compactString()
+		level := d.int16()
+		// throttle time, error code and message
+		e.putInt32(0)
+		e.putInt16(0)
+		e.putUvarint(0)
+		e.putCompactArrayLength(1)
+		e.putCompactString(metadataVersionFeature)
+		if level > 9 {
+			e.putInt16(int16(sarama.ErrInvalidRequest))
+			e.putCompactString("level is not supported")
+		} else {
+			e.putInt16(0)
+			e.putUvarint(0)
+		}
+		e.putEmptyTaggedFields()
+		e.putEmptyTaggedFields()
+	default:
+		return nil, errors.New("unexpected request")
+	}
+	return e.Bytes(), nil
+}
+
+func (m *mockClusterAdmin) ElectLeaders(electionType sarama.ElectionType, partitions map[string][]int32) (map[string]map[int32]*sarama.PartitionResult, error) {
+	if m.failOps {
+		return nil, errors.New("bad elect leaders")
+	}
+	results := make(map[string]map[int32]*sarama.PartitionResult, len(partitions))
+	for topic, topicPartitions := range partitions {
+		results[topic] = make(map[int32]*sarama.PartitionResult, len(topicPartitions))
+		for _, partition := range topicPartitions {
+			switch partition {
+			case 1:
+				results[topic][partition] = &sarama.PartitionResult{ErrorCode: sarama.ErrElectionNotNeeded}
+			case 2:
+				results[topic][partition] = &sarama.PartitionResult{ErrorCode: sarama.ErrPreferredLeaderNotAvailable}
+			default:
+				results[topic][partition] = &sarama.PartitionResult{ErrorCode: sarama.ErrNoError}
+			}
+		}
+	}
+	return results, nil
+}
+
+func (m *mockClusterAdmin) DescribeLogDirs(brokers []int32) (map[int32][]sarama.DescribeLogDirsResponseDirMetadata, error) {
+	if m.failOps {
+		return nil, errors.New("bad describe log dirs")
+	}
+	logDirs := make(map[int32][]sarama.DescribeLogDirsResponseDirMetadata, len(brokers))
+	for _, broker := range brokers {
+		logDirs[broker] = []sarama.DescribeLogDirsResponseDirMetadata{
+			{
+				ErrorCode: sarama.ErrNoError,
+				Path:      "/kafka-logs",
+				Topics: []sarama.DescribeLogDirsResponseTopic{
+					{Topic: "test-topic", Partitions: []sarama.DescribeLogDirsResponsePartition{{PartitionID: 0, Size: 1024}}},
+					{Topic: "other-topic", Partitions: []sarama.DescribeLogDirsResponsePartition{{PartitionID: 0, Size: 2048}}},
+				},
+			},
+		}
+	}
+	return logDirs, nil
+}
+
+func (m *mockClusterAdmin) ListPartitionReassignments(topic string, partitions []int32) (map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus, error) {
+	if m.failOps {
+		return nil, errors.New("bad list partition reassignments")
+	}
+	if topic == "reassigning-topic" {
+		return map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus{
+			topic: {1: {Replicas: []int32{2, 0, 1}, AddingReplicas: []int32{1}}},
+		}, nil
+	}
+	return map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus{}, nil
+}
+
+func (m *mockClusterAdmin) AlterPartitionReassignments(topic string, assignment [][]int32) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return errors.New("bad alter partition reassignments")
+	}
+	m.mockReassignments[topic] = assignment
+	if detail, ok := m.mockTopics[topic]; ok && len(assignment) > 0 {
+		detail.ReplicationFactor = int16(len(assignment[0]))
+		m.mockTopics[topic] = detail
+	}
+	return nil
+}
+
+func (m *mockClusterAdmin) ListConsumerGroups() (map[string]string, error) {
+	if m.failOps {
+		return nil, errors.New("bad list consumer groups")
+	}
+	return map[string]string{"test-group": "consumer", "empty-group": "consumer"}, nil
+}
+
+func (m *mockClusterAdmin) DescribeConsumerGroups(groups []string) ([]*sarama.GroupDescription, error) {
+	if m.failOps {
+		return nil, errors.New("bad describe consumer groups")
+	}
+	descriptions := make([]*sarama.GroupDescription, 0, len(groups))
+	for _, group := range groups {
+		description := &sarama.GroupDescription{GroupId: group, State: "Dead", Err: sarama.ErrNoError}
+		switch group {
+		case "test-group":
+			description.State = "Stable"
+			description.Members = map[string]*sarama.GroupMemberDescription{
+				"consumer-1": {
+					MemberId:   "consumer-1",
+					ClientId:   "test-client",
+					ClientHost: "/10.0.0.1",
+					// version 0 assignment of partition 0 of test-topic without user data
+					MemberAssignment: append(append([]byte{0, 0, 0, 0, 0, 1, 0, 10}, "test-topic"...), 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0),
+				},
+			}
+		case "empty-group":
+			description.State = "Empty"
+		}
+		descriptions = append(descriptions, description)
+	}
+	return descriptions, nil
+}
+
+func (m *mockClusterAdmin) ListConsumerGroupOffsets(group string, topicPartitions map[string][]int32) (*sarama.OffsetFetchResponse, error) {
+	if m.failOps {
+		return nil, errors.New("bad list consumer group offsets")
+	}
+	response := &sarama.OffsetFetchResponse{Blocks: map[string]map[int32]*sarama.OffsetFetchResponseBlock{}}
+	if group == "test-group" || group == "empty-group" {
+		response.Blocks["test-topic"] = map[int32]*sarama.OffsetFetchResponseBlock{
+			0: {Offset: 5, Err: sarama.ErrNoError},
+			1: {Offset: -1, Err: sarama.ErrNoError},
+		}
+	}
+	return response, nil
+}
+
+func (m *mockClusterAdmin) GetOffset(topic string, partitionID int32, time int64) (int64, error) {
+	if m.failOps {
+		return 0, errors.New("bad get offset")
+	}
+	if time == sarama.OffsetOldest {
+		return 0, nil
+	}
+	return 10, nil
+}
+
+func (m *mockClusterAdmin) DescribeUserScramCredentials(users []string) ([]*sarama.DescribeUserScramCredentialsResult, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad describe user scram credentials")
+	}
+	results := make([]*sarama.DescribeUserScramCredentialsResult, 0, len(users))
+	for _, user := range users {
+		result := &sarama.DescribeUserScramCredentialsResult{User: user, ErrorCode: sarama.ErrNoError}
+		if len(m.mockScramCredentials[user]) == 0 {
+			result.ErrorCode = errResourceNotFound
+		}
+		for mechanism := range m.mockScramCredentials[user] {
+			result.CredentialInfos = append(result.CredentialInfos, &sarama.UserScramCredentialsResponseInfo{Mechanism: mechanism, Iterations: scramIterations})
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (m *mockClusterAdmin) UpsertUserScramCredentials(upsert []sarama.AlterUserScramCredentialsUpsert) ([]*sarama.AlterUserScramCredentialsResult, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad upsert user scram credentials")
+	}
+	results := make([]*sarama.AlterUserScramCredentialsResult, 0, len(upsert))
+	for _, u := range upsert {
+		if m.mockScramCredentials[u.Name] == nil {
+			m.mockScramCredentials[u.Name] = make(map[sarama.ScramMechanismType][]byte)
+		}
+		m.mockScramCredentials[u.Name][u.Mechanism] = u.Password
+		results = append(results, &sarama.AlterUserScramCredentialsResult{User: u.Name, ErrorCode: sarama.ErrNoError})
+	}
+	return results, nil
+}
+
+func (m *mockClusterAdmin) DeleteUserScramCredentials(deletions []sarama.AlterUserScramCredentialsDelete) ([]*sarama.AlterUserScramCredentialsResult, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad delete user scram credentials")
+	}
+	results := make([]*sarama.AlterUserScramCredentialsResult, 0, len(deletions))
+	for _, d := range deletions {
+		result := &sarama.AlterUserScramCredentialsResult{User: d.Name, ErrorCode: sarama.ErrNoError}
+		if _, ok := m.mockScramCredentials[d.Name][d.Mechanism]; !ok {
+			result.ErrorCode = errResourceNotFound
+		}
+		delete(m.mockScramCredentials[d.Name], d.Mechanism)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (m *mockClusterAdmin) DescribeClientQuotas(components []sarama.QuotaFilterComponent, strict bool) ([]sarama.DescribeClientQuotasEntry, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad describe client quotas")
+	}
+	var entries []sarama.DescribeClientQuotasEntry
+	for _, component := range components {
+		name := component.Match
+		if component.MatchType == sarama.QuotaMatchDefault {
+			name = ""
+		}
+		if len(m.mockQuotas[name]) == 0 {
+			continue
+		}
+		values := make(map[string]float64, len(m.mockQuotas[name]))
+		for key, value := range m.mockQuotas[name] {
+			values[key] = value
+		}
+		entries = append(entries, sarama.DescribeClientQuotasEntry{
+			Entity: []sarama.QuotaEntityComponent{{EntityType: sarama.QuotaEntityUser, MatchType: component.MatchType, Name: name}},
+			Values: values,
+		})
+	}
+	return entries, nil
+}
+
+func (m *mockClusterAdmin) AlterClientQuotas(entity []sarama.QuotaEntityComponent, op sarama.ClientQuotasOp, validateOnly bool) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return errors.New("bad alter client quotas")
+	}
+	for _, component := range entity {
+		if m.mockQuotas[component.Name] == nil {
+			m.mockQuotas[component.Name] = make(map[string]float64)
+		}
+		if op.Remove {
+			delete(m.mockQuotas[component.Name], op.Key)
+		} else {
+			m.mockQuotas[component.Name][op.Key] = op.Value
+		}
+	}
+	return nil
+}
+
 func shallowCopy(original map[string]sarama.TopicDetail) map[string]sarama.TopicDetail {
 	returnMap := make(map[string]sarama.TopicDetail, len(original))
 	for k, v := range original {
@@ -0,0 +1,227 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/IBM/sarama"
+
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// ReplicaPlacement constrains the brokers hosting the replicas of a topic
+type ReplicaPlacement struct {
+	// Brokers the replicas can be placed on, all brokers are allowed when empty
+	Brokers []int32
+	// Racks the replicas can be placed in, all racks are allowed when empty
+	Racks []string
+	// Assignment is the explicit list of replicas of every partition indexed by the partition id,
+	// it takes precedence over the brokers and racks
+	Assignment [][]int32
+}
+
+// allowedBrokerRacks returns the racks of the brokers of the cluster the placement allows replicas on
+func (p *ReplicaPlacement) allowedBrokerRacks(brokers []*sarama.Broker) map[int32]string {
+	brokerRacks := make(map[int32]string, len(brokers))
+	for _, broker := range brokers {
+		if len(p.Brokers) > 0 && !containsBroker(p.Brokers, broker.ID()) {
+			continue
+		}
+		if len(p.Racks) > 0 && !containsRack(p.Racks, broker.Rack()) {
+			continue
+		}
+		brokerRacks[broker.ID()] = broker.Rack()
+	}
+	return brokerRacks
+}
+
+// newReplicaAssignment returns the replica assignment of a new topic according to the placement
+func (k *kafkaClient) newReplicaAssignment(partitions int32, replicationFactor int16, placement *ReplicaPlacement) (map[int32][]int32, error) {
+	assignment := make(map[int32][]int32)
+	if len(placement.Assignment) > 0 {
+		for partition, replicas := range placement.Assignment {
+			assignment[int32(partition)] = append([]int32(nil), replicas...)
+		}
+		return assignment, nil
+	}
+
+	if partitions <= 0 || replicationFactor <= 0 {
+		return nil, errors.New("partitions and replication factor must be set to place the replicas of the topic")
+	}
+	brokerRacks := placement.allowedBrokerRacks(k.brokers)
+	if int(replicationFactor) > len(brokerRacks) {
+		return nil, fmt.Errorf("replication factor %d is larger than the number of brokers %d allowed by the placement",
+			replicationFactor, len(brokerRacks))
+	}
+	brokerIDs := sortedBrokerIDs(brokerRacks)
+	load := make(map[int32]int, len(brokerIDs))
+	for partition := int32(0); partition < partitions; partition++ {
+		// The brokers are rotated so the preferred leaders of the partitions are spread evenly
+		offset := int(partition) % len(brokerIDs)
+		rotated := append(append(make([]int32, 0, len(brokerIDs)), brokerIDs[offset:]...), brokerIDs[:offset]...)
+		assignment[partition] = addReplicas(nil, int(replicationFactor), rotated, brokerRacks, load)
+	}
+	return assignment, nil
+}
+
+// EnsureReplicaPlacement reassigns the partitions of the topic whose replicas are not placed according to the placement.
+// With an explicit assignment the partitions are moved to the listed replicas, otherwise the replicas on brokers
+// not allowed by the placement are replaced by the least loaded allowed brokers of the racks the partition is
+// not present in yet. A new reassignment is not started while another one is in progress for the topic.
+func (k *kafkaClient) EnsureReplicaPlacement(topic string, placement ReplicaPlacement) (PartitionReassignment, error) {
+	var reassignment PartitionReassignment
+
+	meta, err := k.DescribeTopic(topic)
+	if err != nil {
+		return reassignment, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing topic", "topic", topic)
+	}
+
+	partitionIDs := make([]int32, 0, len(meta.Partitions))
+	for _, partition := range meta.Partitions {
+		partitionIDs = append(partitionIDs, partition.ID)
+	}
+	ongoing, err := k.admin.ListPartitionReassignments(topic, partitionIDs)
+	if err != nil {
+		return reassignment, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error listing partition reassignments", "topic", topic)
+	}
+	if len(ongoing[topic]) > 0 {
+		reassignment.ReassigningPartitions = len(ongoing[topic])
+		return reassignment, nil
+	}
+
+	var assignment [][]int32
+	var changed int
+	if len(placement.Assignment) > 0 {
+		assignment, changed = explicitReplicaAssignment(meta.Partitions, placement.Assignment)
+	} else {
+		assignment, changed, err = constrainedReplicaAssignment(meta.Partitions, placement.allowedBrokerRacks(k.brokers))
+		if err != nil {
+			return reassignment, errorfactory.New(errorfactory.InternalError{}, err, "error placing replicas", "topic", topic)
+		}
+	}
+	if changed == 0 {
+		return reassignment, nil
+	}
+
+	if err = k.admin.AlterPartitionReassignments(topic, assignment); err != nil {
+		return reassignment, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error reassigning partitions", "topic", topic)
+	}
+	reassignment.ReassigningPartitions = changed
+	reassignment.Started = true
+	return reassignment, nil
+}
+
+// explicitReplicaAssignment returns the replica assignment of the partitions indexed by partition id taking the replicas
+// from the desired assignment, and the number of partitions whose replicas are changed. Partitions missing from the
+// desired assignment keep their replicas.
+func explicitReplicaAssignment(partitions []*sarama.PartitionMetadata, desired [][]int32) ([][]int32, int) {
+	assignment := make([][]int32, partitionCount(partitions))
+	changed := 0
+	for _, partition := range partitions {
+		replicas := partition.Replicas
+		if int(partition.ID) < len(desired) && !equalReplicas(replicas, desired[partition.ID]) {
+			replicas = desired[partition.ID]
+			changed++
+		}
+		assignment[partition.ID] = append([]int32(nil), replicas...)
+	}
+	return assignment, changed
+}
+
+// constrainedReplicaAssignment returns the replica assignment of the partitions indexed by partition id keeping the replicas
+// on the allowed brokers, and the number of partitions whose replicas are changed. The replicas on other brokers are replaced
+// by allowed brokers in racks the partition is not present in, then by the ones with fewer replicas of the topic.
+func constrainedReplicaAssignment(partitions []*sarama.PartitionMetadata, brokerRacks map[int32]string) ([][]int32, int, error) {
+	brokerIDs := sortedBrokerIDs(brokerRacks)
+
+	sorted := make([]*sarama.PartitionMetadata, len(partitions))
+	copy(sorted, partitions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	load := make(map[int32]int, len(brokerIDs))
+	for _, partition := range sorted {
+		for _, replica := range partition.Replicas {
+			if _, ok := brokerRacks[replica]; ok {
+				load[replica]++
+			}
+		}
+	}
+
+	assignment := make([][]int32, partitionCount(partitions))
+	changed := 0
+	for _, partition := range sorted {
+		kept := make([]int32, 0, len(partition.Replicas))
+		for _, replica := range partition.Replicas {
+			if _, ok := brokerRacks[replica]; ok {
+				kept = append(kept, replica)
+			}
+		}
+		if len(kept) == len(partition.Replicas) {
+			assignment[partition.ID] = kept
+			continue
+		}
+		replicas := addReplicas(kept, len(partition.Replicas), brokerIDs, brokerRacks, load)
+		if len(replicas) < len(partition.Replicas) {
+			return nil, 0, fmt.Errorf("replication factor %d is larger than the number of brokers %d allowed by the placement",
+				len(partition.Replicas), len(brokerIDs))
+		}
+		assignment[partition.ID] = replicas
+		changed++
+	}
+	return assignment, changed, nil
+}
+
+func partitionCount(partitions []*sarama.PartitionMetadata) int32 {
+	var size int32
+	for _, partition := range partitions {
+		if partition.ID+1 > size {
+			size = partition.ID + 1
+		}
+	}
+	return size
+}
+
+func sortedBrokerIDs(brokerRacks map[int32]string) []int32 {
+	brokerIDs := make([]int32, 0, len(brokerRacks))
+	for brokerID := range brokerRacks {
+		brokerIDs = append(brokerIDs, brokerID)
+	}
+	sort.Slice(brokerIDs, func(i, j int) bool { return brokerIDs[i] < brokerIDs[j] })
+	return brokerIDs
+}
+
+func equalReplicas(replicas, other []int32) bool {
+	if len(replicas) != len(other) {
+		return false
+	}
+	for i := range replicas {
+		if replicas[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsRack(racks []string, rack string) bool {
+	for _, r := range racks {
+		if r == rack {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstrainedReplicaAssignment(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		testName           string
+		partitions         []*sarama.PartitionMetadata
+		brokerRacks        map[int32]string
+		expectedAssignment [][]int32
+		expectedChanged    int
+		expectedErr        bool
+	}{
+		{
+			testName: "replicas already on allowed brokers",
+			partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Replicas: []int32{0, 1}},
+				{ID: 1, Replicas: []int32{1, 0}},
+			},
+			brokerRacks:        map[int32]string{0: "a", 1: "b"},
+			expectedAssignment: [][]int32{{0, 1}, {1, 0}},
+			expectedChanged:    0,
+		},
+		{
+			testName: "replicas on disallowed brokers are moved to the least loaded brokers of unused racks",
+			partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Replicas: []int32{0, 2}},
+				{ID: 1, Replicas: []int32{2, 1}},
+			},
+			brokerRacks:        map[int32]string{0: "a", 1: "b", 3: "a"},
+			expectedAssignment: [][]int32{{0, 1}, {1, 3}},
+			expectedChanged:    2,
+		},
+		{
+			testName: "not enough allowed brokers",
+			partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Replicas: []int32{0, 1, 2}},
+			},
+			brokerRacks: map[int32]string{0: "a", 1: "b"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			t.Parallel()
+			assignment, changed, err := constrainedReplicaAssignment(test.partitions, test.brokerRacks)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedAssignment, assignment)
+			assert.Equal(t, test.expectedChanged, changed)
+		})
+	}
+}
+
+func TestExplicitReplicaAssignment(t *testing.T) {
+	partitions := []*sarama.PartitionMetadata{
+		{ID: 0, Replicas: []int32{0, 1}},
+		{ID: 1, Replicas: []int32{1, 2}},
+		{ID: 2, Replicas: []int32{2, 0}},
+	}
+	assignment, changed := explicitReplicaAssignment(partitions, [][]int32{{0, 1}, {2, 1}})
+	assert.Equal(t, [][]int32{{0, 1}, {2, 1}, {2, 0}}, assignment)
+	assert.Equal(t, 1, changed)
+}
+
+func TestNewReplicaAssignment(t *testing.T) {
+	client := newOpenedMockClient()
+	client.brokers = []*sarama.Broker{sarama.NewBroker("broker-0:9092"), sarama.NewBroker("broker-1:9092")}
+
+	assignment, err := client.newReplicaAssignment(1, 1, &ReplicaPlacement{Assignment: [][]int32{{1, 0}, {0, 1}}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int32][]int32{0: {1, 0}, 1: {0, 1}}, assignment)
+
+	if _, err = client.newReplicaAssignment(-1, 1, &ReplicaPlacement{Brokers: []int32{0}}); err == nil {
+		t.Error("Expected error on placing replicas with the broker's default partitions, got nil")
+	}
+	if _, err = client.newReplicaAssignment(1, 2, &ReplicaPlacement{Racks: []string{"a"}}); err == nil {
+		t.Error("Expected error on placing replicas without enough brokers in the racks, got nil")
+	}
+}
+
+func TestEnsureReplicaPlacement(t *testing.T) {
+	client := newOpenedMockClient()
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+
+	if _, err := client.EnsureReplicaPlacement("test-topic", ReplicaPlacement{Brokers: []int32{0}}); err == nil {
+		t.Error("Expected error on EnsureReplicaPlacement, got nil")
+	}
+}
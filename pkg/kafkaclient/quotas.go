@@ -0,0 +1,142 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"github.com/IBM/sarama"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// Client quota configuration keys of Kafka
+const (
+	quotaProducerByteRate       = "producer_byte_rate"
+	quotaConsumerByteRate       = "consumer_byte_rate"
+	quotaRequestPercentage      = "request_percentage"
+	quotaControllerMutationRate = "controller_mutation_rate"
+)
+
+// userQuotaKeys are the client quota configuration keys managed for the users
+var userQuotaKeys = []string{quotaProducerByteRate, quotaConsumerByteRate, quotaRequestPercentage, quotaControllerMutationRate}
+
+// AlterUserQuotas sets the client quotas of the user to the given ones, the quotas not set are removed from the user
+func (k *kafkaClient) AlterUserQuotas(user string, quotas *v1alpha1.UserQuotas) error {
+	current, err := k.describeQuotas(sarama.QuotaFilterComponent{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchExact, Match: user})
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing client quotas", "user", user)
+	}
+	desired := userQuotasToValues(quotas)
+
+	entity := []sarama.QuotaEntityComponent{{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchExact, Name: user}}
+	for _, key := range userQuotaKeys {
+		value, set := desired[key]
+		currentValue, present := current[key]
+		var op sarama.ClientQuotasOp
+		switch {
+		case set && (!present || currentValue != value):
+			op = sarama.ClientQuotasOp{Key: key, Value: value}
+		case !set && present:
+			op = sarama.ClientQuotasOp{Key: key, Remove: true}
+		default:
+			continue
+		}
+		if err = k.admin.AlterClientQuotas(entity, op, false); err != nil {
+			return errorfactory.New(errorfactory.BrokersRequestError{}, err, "error altering client quota", "user", user, "quota", key)
+		}
+	}
+	return nil
+}
+
+// DescribeUserQuotas returns the effective client quotas of the user. The quotas not set for the user are taken
+// from the default quotas of the users.
+func (k *kafkaClient) DescribeUserQuotas(user string) (*v1alpha1.UserQuotas, error) {
+	values, err := k.describeQuotas(sarama.QuotaFilterComponent{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchExact, Match: user})
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing client quotas", "user", user)
+	}
+	defaults, err := k.describeQuotas(sarama.QuotaFilterComponent{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchDefault})
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing default client quotas")
+	}
+	for key, value := range defaults {
+		if _, ok := values[key]; !ok {
+			values[key] = value
+		}
+	}
+	return valuesToUserQuotas(values), nil
+}
+
+// describeQuotas returns the quota values of the entity matching only the user filter, entities combining the user
+// with client ids are not considered
+func (k *kafkaClient) describeQuotas(filter sarama.QuotaFilterComponent) (map[string]float64, error) {
+	entries, err := k.admin.DescribeClientQuotas([]sarama.QuotaFilterComponent{filter}, true)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64)
+	for _, entry := range entries {
+		for key, value := range entry.Values {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func userQuotasToValues(quotas *v1alpha1.UserQuotas) map[string]float64 {
+	values := make(map[string]float64)
+	if quotas == nil {
+		return values
+	}
+	if quotas.ProducerByteRate != nil {
+		values[quotaProducerByteRate] = float64(*quotas.ProducerByteRate)
+	}
+	if quotas.ConsumerByteRate != nil {
+		values[quotaConsumerByteRate] = float64(*quotas.ConsumerByteRate)
+	}
+	if quotas.RequestPercentage != nil {
+		values[quotaRequestPercentage] = float64(*quotas.RequestPercentage)
+	}
+	if quotas.ControllerMutationRate != nil {
+		values[quotaControllerMutationRate] = float64(*quotas.ControllerMutationRate)
+	}
+	return values
+}
+
+// valuesToUserQuotas converts the quota values to UserQuotas, nil is returned when none of the user quotas is set
+func valuesToUserQuotas(values map[string]float64) *v1alpha1.UserQuotas {
+	quotas := &v1alpha1.UserQuotas{}
+	set := false
+	if value, ok := values[quotaProducerByteRate]; ok {
+		rate := int64(value)
+		quotas.ProducerByteRate, set = &rate, true
+	}
+	if value, ok := values[quotaConsumerByteRate]; ok {
+		rate := int64(value)
+		quotas.ConsumerByteRate, set = &rate, true
+	}
+	if value, ok := values[quotaRequestPercentage]; ok {
+		percentage := int32(value)
+		quotas.RequestPercentage, set = &percentage, true
+	}
+	if value, ok := values[quotaControllerMutationRate]; ok {
+		rate := int32(value)
+		quotas.ControllerMutationRate, set = &rate, true
+	}
+	if !set {
+		return nil
+	}
+	return quotas
+}
@@ -0,0 +1,84 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestAlterUserQuotas(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := client.admin.(*mockClusterAdmin)
+
+	err := client.AlterUserQuotas("test-user", &v1alpha1.UserQuotas{
+		ProducerByteRate:  util.Int64Pointer(1048576),
+		RequestPercentage: util.Int32Pointer(50),
+	})
+	if err != nil {
+		t.Fatal("Expected no error on AlterUserQuotas, got:", err)
+	}
+	assert.Equal(t, map[string]float64{quotaProducerByteRate: 1048576, quotaRequestPercentage: 50}, admin.mockQuotas["test-user"])
+
+	// The quotas removed from the user are removed from Kafka
+	if err = client.AlterUserQuotas("test-user", &v1alpha1.UserQuotas{ConsumerByteRate: util.Int64Pointer(2048)}); err != nil {
+		t.Fatal("Expected no error on AlterUserQuotas, got:", err)
+	}
+	assert.Equal(t, map[string]float64{quotaConsumerByteRate: 2048}, admin.mockQuotas["test-user"])
+
+	if err = client.AlterUserQuotas("test-user", nil); err != nil {
+		t.Fatal("Expected no error on AlterUserQuotas, got:", err)
+	}
+	assert.Empty(t, admin.mockQuotas["test-user"])
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if err = client.AlterUserQuotas("test-user", nil); err == nil {
+		t.Error("Expected error on AlterUserQuotas, got nil")
+	}
+}
+
+func TestDescribeUserQuotas(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := client.admin.(*mockClusterAdmin)
+
+	quotas, err := client.DescribeUserQuotas("test-user")
+	if err != nil {
+		t.Fatal("Expected no error on DescribeUserQuotas, got:", err)
+	}
+	assert.Nil(t, quotas)
+
+	// The quotas set for the user take precedence over the default ones
+	admin.mockQuotas[""] = map[string]float64{quotaProducerByteRate: 1024, quotaControllerMutationRate: 10}
+	admin.mockQuotas["test-user"] = map[string]float64{quotaProducerByteRate: 4096, quotaConsumerByteRate: 8192}
+	quotas, err = client.DescribeUserQuotas("test-user")
+	if err != nil {
+		t.Fatal("Expected no error on DescribeUserQuotas, got:", err)
+	}
+	assert.Equal(t, &v1alpha1.UserQuotas{
+		ProducerByteRate:       util.Int64Pointer(4096),
+		ConsumerByteRate:       util.Int64Pointer(8192),
+		ControllerMutationRate: util.Int32Pointer(10),
+	}, quotas)
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err = client.DescribeUserQuotas("test-user"); err == nil {
+		t.Error("Expected error on DescribeUserQuotas, got nil")
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/IBM/sarama"
+
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// PartitionReassignment describes the reassignment of the partitions of a topic
+type PartitionReassignment struct {
+	// ReassigningPartitions is the number of partitions of the topic being reassigned
+	ReassigningPartitions int
+	// Started is true when the reassignment has been submitted by the call
+	Started bool
+}
+
+// EnsureReplicationFactor reassigns the partitions of the topic whose number of replicas differs from the desired
+// replication factor. New replicas are placed on the least loaded brokers of the racks the partition is not present in
+// yet, while the preferred leader of the partitions is kept. When the topic has a placement, the new replicas are only
+// placed on the brokers it allows and the replicas on other brokers are removed first, so the replicas are not moved
+// again to satisfy the placement. A new reassignment is not started while another one is in progress for the topic.
+func (k *kafkaClient) EnsureReplicationFactor(topic string, desired int16, placement *ReplicaPlacement) (PartitionReassignment, error) {
+	var reassignment PartitionReassignment
+
+	meta, err := k.DescribeTopic(topic)
+	if err != nil {
+		return reassignment, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing topic", "topic", topic)
+	}
+
+	partitionIDs := make([]int32, 0, len(meta.Partitions))
+	for _, partition := range meta.Partitions {
+		partitionIDs = append(partitionIDs, partition.ID)
+	}
+	ongoing, err := k.admin.ListPartitionReassignments(topic, partitionIDs)
+	if err != nil {
+		return reassignment, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error listing partition reassignments", "topic", topic)
+	}
+	if len(ongoing[topic]) > 0 {
+		reassignment.ReassigningPartitions = len(ongoing[topic])
+		return reassignment, nil
+	}
+
+	brokerRacks := make(map[int32]string, len(k.brokers))
+	for _, broker := range k.brokers {
+		brokerRacks[broker.ID()] = broker.Rack()
+	}
+	allowedBrokerRacks := brokerRacks
+	if placement != nil {
+		allowedBrokerRacks = placement.allowedBrokerRacks(k.brokers)
+	}
+
+	var assignment [][]int32
+	var changed int
+	if placement != nil && len(placement.Assignment) > 0 {
+		// The explicit assignment lists the replicas of the desired replication factor
+		assignment, changed = explicitReplicaAssignment(meta.Partitions, placement.Assignment)
+	} else {
+		if int(desired) > len(allowedBrokerRacks) {
+			return reassignment, errorfactory.New(errorfactory.InternalError{},
+				errors.New("not enough brokers"), fmt.Sprintf("replication factor %d is larger than the number of brokers %d", desired, len(allowedBrokerRacks)))
+		}
+		assignment, changed = replicaAssignment(meta.Partitions, int(desired), brokerRacks, allowedBrokerRacks)
+	}
+	if changed == 0 {
+		return reassignment, nil
+	}
+
+	if err = k.admin.AlterPartitionReassignments(topic, assignment); err != nil {
+		return reassignment, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error reassigning partitions", "topic", topic)
+	}
+	reassignment.ReassigningPartitions = changed
+	reassignment.Started = true
+	return reassignment, nil
+}
+
+// replicaAssignment returns the replica assignment of the partitions indexed by partition id with the given
+// replication factor, and the number of partitions whose replicas are changed.
+// When replicas are removed, the ones on brokers not allowed are dropped first, then the preferred leader is kept and
+// the replicas sharing a rack with the remaining ones are dropped. When replicas are added, allowed brokers in racks
+// the partition is not present in are preferred, then the ones with fewer replicas of the topic.
+func replicaAssignment(partitions []*sarama.PartitionMetadata, replicationFactor int, brokerRacks, allowedBrokerRacks map[int32]string) ([][]int32, int) {
+	brokerIDs := sortedBrokerIDs(allowedBrokerRacks)
+
+	sorted := make([]*sarama.PartitionMetadata, len(partitions))
+	copy(sorted, partitions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	load := make(map[int32]int, len(brokerIDs))
+	for _, partition := range sorted {
+		for _, replica := range partition.Replicas {
+			load[replica]++
+		}
+	}
+
+	assignment := make([][]int32, partitionCount(partitions))
+	changed := 0
+	for _, partition := range sorted {
+		replicas := partition.Replicas
+		switch {
+		case len(replicas) > replicationFactor:
+			replicas = removeReplicas(replicas, replicationFactor, brokerRacks, allowedBrokerRacks)
+			for _, replica := range partition.Replicas {
+				if !containsBroker(replicas, replica) {
+					load[replica]--
+				}
+			}
+		case len(replicas) < replicationFactor:
+			replicas = addReplicas(replicas, replicationFactor, brokerIDs, brokerRacks, load)
+		default:
+			assignment[partition.ID] = append([]int32(nil), replicas...)
+			continue
+		}
+		assignment[partition.ID] = replicas
+		changed++
+	}
+	return assignment, changed
+}
+
+func removeReplicas(replicas []int32, replicationFactor int, brokerRacks, allowedBrokerRacks map[int32]string) []int32 {
+	kept := make([]int32, 0, replicationFactor)
+	if replicationFactor == 0 || len(replicas) == 0 {
+		return kept
+	}
+	allowed := make([]int32, 0, len(replicas))
+	var other []int32
+	for _, replica := range replicas {
+		if _, ok := allowedBrokerRacks[replica]; ok {
+			allowed = append(allowed, replica)
+		} else {
+			other = append(other, replica)
+		}
+	}
+	if len(allowed) < replicationFactor {
+		// All the replicas on allowed brokers are kept, the placement moves the remaining ones
+		return append(allowed, other[:replicationFactor-len(allowed)]...)
+	}
+	replicas = allowed
+
+	// The preferred leader is always kept
+	kept = append(kept, replicas[0])
+	usedRacks := map[string]bool{brokerRacks[replicas[0]]: true}
+	for _, replica := range replicas[1:] {
+		if len(kept) < replicationFactor && !usedRacks[brokerRacks[replica]] {
+			kept = append(kept, replica)
+			usedRacks[brokerRacks[replica]] = true
+		}
+	}
+	for _, replica := range replicas[1:] {
+		if len(kept) < replicationFactor && !containsBroker(kept, replica) {
+			kept = append(kept, replica)
+		}
+	}
+	return kept
+}
+
+func addReplicas(replicas []int32, replicationFactor int, brokerIDs []int32, brokerRacks map[int32]string, load map[int32]int) []int32 {
+	added := append(make([]int32, 0, replicationFactor), replicas...)
+	usedRacks := make(map[string]bool, replicationFactor)
+	for _, replica := range replicas {
+		usedRacks[brokerRacks[replica]] = true
+	}
+	for len(added) < replicationFactor {
+		best := int32(-1)
+		for _, brokerID := range brokerIDs {
+			if containsBroker(added, brokerID) {
+				continue
+			}
+			if best == -1 || isBetterReplica(brokerID, best, brokerRacks, usedRacks, load) {
+				best = brokerID
+			}
+		}
+		if best == -1 {
+			break
+		}
+		added = append(added, best)
+		usedRacks[brokerRacks[best]] = true
+		load[best]++
+	}
+	return added
+}
+
+// isBetterReplica returns true if the broker should rather host a new replica than the other one;
+// brokers in unused racks are preferred, then the ones with fewer replicas
+func isBetterReplica(brokerID, other int32, brokerRacks map[int32]string, usedRacks map[string]bool, load map[int32]int) bool {
+	brokerRackUsed, otherRackUsed := usedRacks[brokerRacks[brokerID]], usedRacks[brokerRacks[other]]
+	if brokerRackUsed != otherRackUsed {
+		return !brokerRackUsed
+	}
+	return load[brokerID] < load[other]
+}
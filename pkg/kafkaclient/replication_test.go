@@ -0,0 +1,126 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaAssignment(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		testName           string
+		partitions         []*sarama.PartitionMetadata
+		replicationFactor  int
+		brokerRacks        map[int32]string
+		allowedBrokerRacks map[int32]string
+		expectedAssignment [][]int32
+		expectedChanged    int
+	}{
+		{
+			testName: "replication factor unchanged",
+			partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Replicas: []int32{0, 1}},
+				{ID: 1, Replicas: []int32{1, 2}},
+			},
+			replicationFactor:  2,
+			brokerRacks:        map[int32]string{0: "a", 1: "b", 2: "c"},
+			expectedAssignment: [][]int32{{0, 1}, {1, 2}},
+			expectedChanged:    0,
+		},
+		{
+			testName: "replicas are added to unused racks first",
+			partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Replicas: []int32{0}},
+				{ID: 1, Replicas: []int32{1}},
+			},
+			replicationFactor:  2,
+			brokerRacks:        map[int32]string{0: "a", 1: "a", 2: "b", 3: "b"},
+			expectedAssignment: [][]int32{{0, 2}, {1, 3}},
+			expectedChanged:    2,
+		},
+		{
+			testName: "replicas are added to the least loaded brokers without racks",
+			partitions: []*sarama.PartitionMetadata{
+				{ID: 1, Replicas: []int32{1, 2}},
+				{ID: 0, Replicas: []int32{0, 1}},
+			},
+			replicationFactor:  3,
+			brokerRacks:        map[int32]string{0: "", 1: "", 2: "", 3: ""},
+			expectedAssignment: [][]int32{{0, 1, 3}, {1, 2, 0}},
+			expectedChanged:    2,
+		},
+		{
+			testName: "replicas sharing a rack are removed first and the preferred leader is kept",
+			partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Replicas: []int32{0, 1, 2}},
+			},
+			replicationFactor:  2,
+			brokerRacks:        map[int32]string{0: "a", 1: "a", 2: "b"},
+			expectedAssignment: [][]int32{{0, 2}},
+			expectedChanged:    1,
+		},
+		{
+			testName: "replicas are only added to the brokers allowed by the placement",
+			partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Replicas: []int32{0}},
+				{ID: 1, Replicas: []int32{1}},
+			},
+			replicationFactor:  2,
+			brokerRacks:        map[int32]string{0: "a", 1: "a", 2: "b", 3: "b"},
+			allowedBrokerRacks: map[int32]string{0: "a", 1: "a", 3: "b"},
+			expectedAssignment: [][]int32{{0, 3}, {1, 3}},
+			expectedChanged:    2,
+		},
+		{
+			testName: "replicas on the brokers not allowed by the placement are removed first",
+			partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Replicas: []int32{0, 1, 2}},
+				{ID: 1, Replicas: []int32{2, 3, 0}},
+			},
+			replicationFactor:  2,
+			brokerRacks:        map[int32]string{0: "a", 1: "a", 2: "b", 3: "b"},
+			allowedBrokerRacks: map[int32]string{0: "a", 1: "a"},
+			expectedAssignment: [][]int32{{0, 1}, {0, 2}},
+			expectedChanged:    2,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			t.Parallel()
+			allowedBrokerRacks := test.allowedBrokerRacks
+			if allowedBrokerRacks == nil {
+				allowedBrokerRacks = test.brokerRacks
+			}
+			assignment, changed := replicaAssignment(test.partitions, test.replicationFactor, test.brokerRacks, allowedBrokerRacks)
+			assert.Equal(t, test.expectedAssignment, assignment)
+			assert.Equal(t, test.expectedChanged, changed)
+		})
+	}
+}
+
+func TestEnsureReplicationFactor(t *testing.T) {
+	client := newOpenedMockClient()
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+
+	if _, err := client.EnsureReplicationFactor("test-topic", 3, nil); err == nil {
+		t.Error("Expected error on EnsureReplicationFactor, got nil")
+	}
+}
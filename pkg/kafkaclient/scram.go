@@ -0,0 +1,183 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+const (
+	// scramIterations is the number of iterations used to salt the SCRAM passwords, the minimum accepted by Kafka
+	scramIterations = 4096
+	// scramSaltLength is the length of the random salt of the SCRAM credentials in bytes
+	scramSaltLength = 32
+	// errResourceNotFound is reported for the users without SCRAM credentials, it's not defined by sarama
+	errResourceNotFound sarama.KError = 91
+)
+
+// ScramMechanismMapping maps the authentication type of a KafkaUser to sarama.ScramMechanismType
+func ScramMechanismMapping(authentication v1alpha1.UserAuthenticationType) sarama.ScramMechanismType {
+	switch authentication {
+	case v1alpha1.UserAuthenticationScramSHA256:
+		return sarama.SCRAM_MECHANISM_SHA_256
+	case v1alpha1.UserAuthenticationScramSHA512:
+		return sarama.SCRAM_MECHANISM_SHA_512
+	default:
+		return sarama.SCRAM_MECHANISM_UNKNOWN
+	}
+}
+
+// EnsureUserScramCredentials registers the password of the user for the mechanism of the authentication type, and removes
+// the credentials of the user for the other mechanisms. An already registered credential is only replaced when update
+// is set, since the password it was registered with can not be read back from Kafka.
+func (k *kafkaClient) EnsureUserScramCredentials(user string, authentication v1alpha1.UserAuthenticationType, password []byte, update bool) error {
+	mechanism := ScramMechanismMapping(authentication)
+	if mechanism == sarama.SCRAM_MECHANISM_UNKNOWN {
+		return errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", authentication), "unrecognized authentication type")
+	}
+
+	admin, err := k.scramClusterAdmin()
+	if err != nil {
+		return err
+	}
+	mechanisms, err := userScramMechanisms(admin, user)
+	if err != nil {
+		return err
+	}
+
+	var deletions []sarama.AlterUserScramCredentialsDelete
+	registered := false
+	for _, m := range mechanisms {
+		if m == mechanism {
+			registered = true
+			continue
+		}
+		deletions = append(deletions, sarama.AlterUserScramCredentialsDelete{Name: user, Mechanism: m})
+	}
+
+	if !registered || update {
+		salt := make([]byte, scramSaltLength)
+		if _, err = rand.Read(salt); err != nil {
+			return errorfactory.New(errorfactory.InternalError{}, err, "error generating salt of SCRAM credential", "user", user)
+		}
+		results, err := admin.UpsertUserScramCredentials([]sarama.AlterUserScramCredentialsUpsert{{
+			Name:       user,
+			Mechanism:  mechanism,
+			Iterations: scramIterations,
+			Salt:       salt,
+			Password:   password,
+		}})
+		if err = scramCredentialsError(results, err); err != nil {
+			return errorfactory.New(errorfactory.BrokersRequestError{}, err, "error registering SCRAM credential", "user", user, "mechanism", mechanism.String())
+		}
+	}
+
+	if len(deletions) > 0 {
+		results, err := admin.DeleteUserScramCredentials(deletions)
+		if err = scramCredentialsError(results, err); err != nil {
+			return errorfactory.New(errorfactory.BrokersRequestError{}, err, "error deleting SCRAM credentials", "user", user)
+		}
+	}
+	return nil
+}
+
+// DeleteUserScramCredentials removes the SCRAM credentials of the user for all mechanisms
+func (k *kafkaClient) DeleteUserScramCredentials(user string) error {
+	admin, err := k.scramClusterAdmin()
+	if err != nil {
+		return err
+	}
+	mechanisms, err := userScramMechanisms(admin, user)
+	if err != nil {
+		return err
+	}
+	if len(mechanisms) == 0 {
+		return nil
+	}
+	deletions := make([]sarama.AlterUserScramCredentialsDelete, 0, len(mechanisms))
+	for _, mechanism := range mechanisms {
+		deletions = append(deletions, sarama.AlterUserScramCredentialsDelete{Name: user, Mechanism: mechanism})
+	}
+	results, err := admin.DeleteUserScramCredentials(deletions)
+	if err = scramCredentialsError(results, err); err != nil {
+		return errorfactory.New(errorfactory.BrokersRequestError{}, err, "error deleting SCRAM credentials", "user", user)
+	}
+	return nil
+}
+
+// scramClusterAdmin returns the cluster admin the SCRAM credential requests are sent with. It's opened on first use
+// with scramAPIVersion since sarama rejects these requests below Kafka 2.7, the other requests keep using apiVersion.
+func (k *kafkaClient) scramClusterAdmin() (sarama.ClusterAdmin, error) {
+	if k.scramAdmin != nil {
+		return k.scramAdmin, nil
+	}
+	config := k.getSaramaConfig()
+	config.Version = scramAPIVersion
+	admin, err := k.newClusterAdmin([]string{k.opts.BrokerURI}, config)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersUnreachable{}, err, fmt.Sprintf("could not connect to kafka brokers: %s", k.opts.BrokerURI))
+	}
+	k.scramAdmin = admin
+	return admin, nil
+}
+
+// userScramMechanisms returns the mechanisms the user has SCRAM credentials for
+func userScramMechanisms(admin sarama.ClusterAdmin, user string) ([]sarama.ScramMechanismType, error) {
+	results, err := admin.DescribeUserScramCredentials([]string{user})
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing SCRAM credentials", "user", user)
+	}
+	var mechanisms []sarama.ScramMechanismType
+	for _, result := range results {
+		if result.User != user || errors.Is(result.ErrorCode, errResourceNotFound) {
+			continue
+		}
+		if !errors.Is(result.ErrorCode, sarama.ErrNoError) {
+			return nil, errorfactory.New(errorfactory.BrokersRequestError{}, scramResultError(result.ErrorCode, result.ErrorMessage),
+				"error describing SCRAM credentials", "user", user)
+		}
+		for _, info := range result.CredentialInfos {
+			mechanisms = append(mechanisms, info.Mechanism)
+		}
+	}
+	return mechanisms, nil
+}
+
+// scramCredentialsError returns the error of the request altering SCRAM credentials, or the first error of its results
+func scramCredentialsError(results []*sarama.AlterUserScramCredentialsResult, err error) error {
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if !errors.Is(result.ErrorCode, sarama.ErrNoError) {
+			return scramResultError(result.ErrorCode, result.ErrorMessage)
+		}
+	}
+	return nil
+}
+
+func scramResultError(kerr sarama.KError, message *string) error {
+	if message != nil && *message != "" {
+		return fmt.Errorf("%w: %s", kerr, *message)
+	}
+	return kerr
+}
@@ -0,0 +1,110 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+)
+
+func TestEnsureUserScramCredentials(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := newEmptyMockClusterAdmin(false)
+	client.scramAdmin = admin
+
+	if err := client.EnsureUserScramCredentials("test-user", v1alpha1.UserAuthenticationScramSHA256, []byte("first"), false); err != nil {
+		t.Fatal("Expected no error on EnsureUserScramCredentials, got:", err)
+	}
+	assert.Equal(t, map[sarama.ScramMechanismType][]byte{sarama.SCRAM_MECHANISM_SHA_256: []byte("first")}, admin.mockScramCredentials["test-user"])
+
+	// The registered credential is kept unless an update is requested
+	if err := client.EnsureUserScramCredentials("test-user", v1alpha1.UserAuthenticationScramSHA256, []byte("second"), false); err != nil {
+		t.Fatal("Expected no error on EnsureUserScramCredentials, got:", err)
+	}
+	assert.Equal(t, map[sarama.ScramMechanismType][]byte{sarama.SCRAM_MECHANISM_SHA_256: []byte("first")}, admin.mockScramCredentials["test-user"])
+
+	if err := client.EnsureUserScramCredentials("test-user", v1alpha1.UserAuthenticationScramSHA256, []byte("second"), true); err != nil {
+		t.Fatal("Expected no error on EnsureUserScramCredentials, got:", err)
+	}
+	assert.Equal(t, map[sarama.ScramMechanismType][]byte{sarama.SCRAM_MECHANISM_SHA_256: []byte("second")}, admin.mockScramCredentials["test-user"])
+
+	// Changing the mechanism removes the credential of the previous one
+	if err := client.EnsureUserScramCredentials("test-user", v1alpha1.UserAuthenticationScramSHA512, []byte("second"), false); err != nil {
+		t.Fatal("Expected no error on EnsureUserScramCredentials, got:", err)
+	}
+	assert.Equal(t, map[sarama.ScramMechanismType][]byte{sarama.SCRAM_MECHANISM_SHA_512: []byte("second")}, admin.mockScramCredentials["test-user"])
+
+	if err := client.EnsureUserScramCredentials("test-user", v1alpha1.UserAuthenticationTLS, []byte("second"), false); err == nil {
+		t.Error("Expected error on EnsureUserScramCredentials with tls authentication, got nil")
+	}
+
+	client.scramAdmin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if err := client.EnsureUserScramCredentials("test-user", v1alpha1.UserAuthenticationScramSHA512, []byte("second"), false); err == nil {
+		t.Error("Expected error on EnsureUserScramCredentials, got nil")
+	}
+}
+
+func TestDeleteUserScramCredentials(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := newEmptyMockClusterAdmin(false)
+	client.scramAdmin = admin
+
+	// Deleting the credentials of a user without any is not an error
+	if err := client.DeleteUserScramCredentials("test-user"); err != nil {
+		t.Error("Expected no error on DeleteUserScramCredentials, got:", err)
+	}
+
+	admin.mockScramCredentials["test-user"] = map[sarama.ScramMechanismType][]byte{
+		sarama.SCRAM_MECHANISM_SHA_256: []byte("password"),
+		sarama.SCRAM_MECHANISM_SHA_512: []byte("password"),
+	}
+	if err := client.DeleteUserScramCredentials("test-user"); err != nil {
+		t.Error("Expected no error on DeleteUserScramCredentials, got:", err)
+	}
+	assert.Empty(t, admin.mockScramCredentials["test-user"])
+
+	client.scramAdmin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if err := client.DeleteUserScramCredentials("test-user"); err == nil {
+		t.Error("Expected error on DeleteUserScramCredentials, got nil")
+	}
+}
+
+func TestScramClusterAdmin(t *testing.T) {
+	client := newOpenedMockClient()
+	var versions []sarama.KafkaVersion
+	client.newClusterAdmin = func(addrs []string, config *sarama.Config) (sarama.ClusterAdmin, error) {
+		versions = append(versions, config.Version)
+		return newMockClusterAdmin(addrs, config)
+	}
+
+	// The SCRAM requests are sent with their own version, the admin is opened once
+	for i := 0; i < 2; i++ {
+		if err := client.DeleteUserScramCredentials("test-user"); err != nil {
+			t.Fatal("Expected no error on DeleteUserScramCredentials, got:", err)
+		}
+	}
+	assert.Equal(t, []sarama.KafkaVersion{scramAPIVersion}, versions)
+	assert.False(t, client.getSaramaConfig().Version.IsAtLeast(scramAPIVersion))
+
+	client.scramAdmin = nil
+	client.newClusterAdmin = newMockClusterAdminError
+	if err := client.DeleteUserScramCredentials("test-user"); err == nil {
+		t.Error("Expected error on DeleteUserScramCredentials, got nil")
+	}
+}
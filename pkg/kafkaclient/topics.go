@@ -29,6 +29,8 @@ type CreateTopicOptions struct {
 	Partitions        int32
 	ReplicationFactor int16
 	Config            map[string]*string
+	// Placement constrains the brokers the replicas are placed on, Kafka places them when not set
+	Placement *ReplicaPlacement
 }
 
 // ListTopics is used primarily for checking the existence of topics
@@ -51,6 +53,22 @@ func (k *kafkaClient) GetTopic(topicName string) (meta *sarama.TopicDetail, err
 	return
 }
 
+// DescribeTopicConfig returns the config set on the topic, the values inherited from the brokers and Kafka's defaults
+// are left out
+func (k *kafkaClient) DescribeTopicConfig(topic string) (map[string]string, error) {
+	entries, err := k.admin.DescribeConfig(sarama.ConfigResource{Type: sarama.TopicResource, Name: topic})
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing topic config", "topic", topic)
+	}
+	config := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.Source == sarama.SourceTopic {
+			config[entry.Name] = entry.Value
+		}
+	}
+	return config, nil
+}
+
 // DescribeTopic is used during status syncs to retrieve topic metadata
 func (k *kafkaClient) DescribeTopic(topic string) (meta *sarama.TopicMetadata, err error) {
 	res, err := k.admin.DescribeTopics([]string{topic})
@@ -70,11 +88,20 @@ func (k *kafkaClient) DescribeTopic(topic string) (meta *sarama.TopicMetadata, e
 
 // CreateTopic creates a topic with the given options
 func (k *kafkaClient) CreateTopic(opts *CreateTopicOptions) (err error) {
-	err = k.admin.CreateTopic(opts.Name, &sarama.TopicDetail{
+	detail := &sarama.TopicDetail{
 		NumPartitions:     opts.Partitions,
 		ReplicationFactor: opts.ReplicationFactor,
 		ConfigEntries:     opts.Config,
-	}, false)
+	}
+	if opts.Placement != nil {
+		if detail.ReplicaAssignment, err = k.newReplicaAssignment(opts.Partitions, opts.ReplicationFactor, opts.Placement); err != nil {
+			return errorfactory.New(errorfactory.CreateTopicError{}, err, "failed to place the replicas of the topic")
+		}
+		// The partition count and replication factor are given by the replica assignment
+		detail.NumPartitions = -1
+		detail.ReplicationFactor = -1
+	}
+	err = k.admin.CreateTopic(opts.Name, detail, false)
 	if err != nil {
 		err = errorfactory.New(errorfactory.CreateTopicError{}, err, "failed to create topic")
 	}
@@ -15,6 +15,7 @@
 package kafkaclient
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/IBM/sarama"
@@ -81,6 +82,28 @@ func TestDescribeTopic(t *testing.T) {
 	}
 }
 
+func TestDescribeTopicConfig(t *testing.T) {
+	client := newOpenedMockClient()
+	retention := "3600000"
+	if err := client.CreateTopic(&CreateTopicOptions{
+		Name:              "config-topic",
+		Partitions:        1,
+		ReplicationFactor: 1,
+		Config:            map[string]*string{"retention.ms": &retention},
+	}); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	// The config inherited from the brokers is left out
+	config, err := client.DescribeTopicConfig("config-topic")
+	if err != nil {
+		t.Error("Expected no error on DescribeTopicConfig, got:", err)
+	}
+	if !reflect.DeepEqual(config, map[string]string{"retention.ms": retention}) {
+		t.Error("Expected only the config set on the topic, got:", config)
+	}
+}
+
 func TestCreateTopic(t *testing.T) {
 	client := newOpenedMockClient()
 	if err := client.CreateTopic(&CreateTopicOptions{
@@ -0,0 +1,70 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"github.com/IBM/sarama"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// TopicMetaToStatus returns the partition count, replication factor, under-replicated and offline partition counts
+// of the topic described by the metadata
+func TopicMetaToStatus(meta *sarama.TopicMetadata) *v1alpha1.KafkaTopicStatus {
+	status := &v1alpha1.KafkaTopicStatus{
+		Partitions: int32(len(meta.Partitions)),
+	}
+	for _, partition := range meta.Partitions {
+		if int32(len(partition.Replicas)) > status.ReplicationFactor {
+			status.ReplicationFactor = int32(len(partition.Replicas))
+		}
+		if len(partition.Isr) < len(partition.Replicas) {
+			status.UnderReplicatedPartitions++
+		}
+		if partition.Leader < 0 || partition.Err == sarama.ErrLeaderNotAvailable {
+			status.OfflinePartitions++
+		}
+	}
+	return status
+}
+
+// TopicSizes returns the total size on disk of all the replicas of the topics in bytes by topic,
+// the log dirs of every broker are described once for all the topics
+func (k *kafkaClient) TopicSizes() (map[string]int64, error) {
+	brokerIDs := make([]int32, 0, len(k.brokers))
+	for _, broker := range k.brokers {
+		brokerIDs = append(brokerIDs, broker.ID())
+	}
+	logDirs, err := k.admin.DescribeLogDirs(brokerIDs)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing log dirs")
+	}
+
+	sizes := make(map[string]int64)
+	for _, brokerLogDirs := range logDirs {
+		for _, logDir := range brokerLogDirs {
+			if logDir.ErrorCode != sarama.ErrNoError {
+				continue
+			}
+			for _, logDirTopic := range logDir.Topics {
+				for _, partition := range logDirTopic.Partitions {
+					sizes[logDirTopic.Topic] += partition.Size
+				}
+			}
+		}
+	}
+	return sizes, nil
+}
@@ -0,0 +1,57 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+)
+
+func TestTopicMetaToStatus(t *testing.T) {
+	status := TopicMetaToStatus(&sarama.TopicMetadata{
+		Name: "test-topic",
+		Partitions: []*sarama.PartitionMetadata{
+			{ID: 0, Leader: 1, Replicas: []int32{1, 2, 3}, Isr: []int32{1, 2, 3}},
+			{ID: 1, Leader: 2, Replicas: []int32{2, 3, 1}, Isr: []int32{2}},
+			{ID: 2, Leader: -1, Replicas: []int32{3, 1, 2}, Isr: []int32{}, Err: sarama.ErrLeaderNotAvailable},
+		},
+	})
+
+	assert.Equal(t, &v1alpha1.KafkaTopicStatus{
+		Partitions:                3,
+		ReplicationFactor:         3,
+		UnderReplicatedPartitions: 2,
+		OfflinePartitions:         1,
+	}, status)
+}
+
+func TestTopicSizes(t *testing.T) {
+	client := newOpenedMockClient()
+
+	if sizes, err := client.TopicSizes(); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else {
+		assert.Equal(t, map[string]int64{"test-topic": 1024, "other-topic": 2048}, sizes)
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err := client.TopicSizes(); err == nil {
+		t.Error("Expected error on TopicSizes, got nil")
+	}
+}
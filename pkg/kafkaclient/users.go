@@ -16,6 +16,8 @@ package kafkaclient
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/IBM/sarama"
 	"github.com/banzaicloud/koperator/api/v1alpha1"
@@ -38,154 +40,312 @@ func AclPatternTypeMapping(patternType v1alpha1.KafkaPatternType) sarama.AclReso
 	}
 }
 
-// CreateUserACLs creates Kafka ACLs for the given access type and user
-// `literal` patternType will be used if patternType == ""
-func (k *kafkaClient) CreateUserACLs(accessType v1alpha1.KafkaAccessType, patternType v1alpha1.KafkaPatternType, dn string, topic string) (err error) {
-	userName := fmt.Sprintf("User:%s", dn)
-	if patternType == "" {
-		patternType = v1alpha1.KafkaPatternTypeDefault
+// AclResourceTypeMapping maps resourceType from v1alpha1.KafkaResourceType to sarama.AclResourceType
+func AclResourceTypeMapping(resourceType v1alpha1.KafkaResourceType) sarama.AclResourceType {
+	switch resourceType {
+	case v1alpha1.KafkaResourceTypeTopic:
+		return sarama.AclResourceTopic
+	case v1alpha1.KafkaResourceTypeGroup:
+		return sarama.AclResourceGroup
+	case v1alpha1.KafkaResourceTypeCluster:
+		return sarama.AclResourceCluster
+	case v1alpha1.KafkaResourceTypeTransactionalID:
+		return sarama.AclResourceTransactionalID
+	case v1alpha1.KafkaResourceTypeDelegationToken:
+		return sarama.AclResourceDelegationToken
+	default:
+		return sarama.AclResourceUnknown
 	}
-	aclPatternType := AclPatternTypeMapping(patternType)
-	if aclPatternType == sarama.AclPatternUnknown {
-		return errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", patternType), "unrecognized pattern type")
+}
+
+// AclOperationMapping maps operation from v1alpha1.KafkaACLOperation to sarama.AclOperation
+func AclOperationMapping(operation v1alpha1.KafkaACLOperation) sarama.AclOperation {
+	switch operation {
+	case v1alpha1.KafkaACLOperationAll:
+		return sarama.AclOperationAll
+	case v1alpha1.KafkaACLOperationRead:
+		return sarama.AclOperationRead
+	case v1alpha1.KafkaACLOperationWrite:
+		return sarama.AclOperationWrite
+	case v1alpha1.KafkaACLOperationCreate:
+		return sarama.AclOperationCreate
+	case v1alpha1.KafkaACLOperationDelete:
+		return sarama.AclOperationDelete
+	case v1alpha1.KafkaACLOperationAlter:
+		return sarama.AclOperationAlter
+	case v1alpha1.KafkaACLOperationDescribe:
+		return sarama.AclOperationDescribe
+	case v1alpha1.KafkaACLOperationClusterAction:
+		return sarama.AclOperationClusterAction
+	case v1alpha1.KafkaACLOperationDescribeConfigs:
+		return sarama.AclOperationDescribeConfigs
+	case v1alpha1.KafkaACLOperationAlterConfigs:
+		return sarama.AclOperationAlterConfigs
+	case v1alpha1.KafkaACLOperationIdempotentWrite:
+		return sarama.AclOperationIdempotentWrite
+	default:
+		return sarama.AclOperationUnknown
 	}
-	switch accessType {
-	case v1alpha1.KafkaAccessTypeRead:
-		return k.createReadACLs(userName, topic, aclPatternType)
-	case v1alpha1.KafkaAccessTypeWrite:
-		return k.createWriteACLs(userName, topic, aclPatternType)
+}
+
+// AclPermissionTypeMapping maps permissionType from v1alpha1.KafkaACLPermissionType to sarama.AclPermissionType
+func AclPermissionTypeMapping(permissionType v1alpha1.KafkaACLPermissionType) sarama.AclPermissionType {
+	switch permissionType {
+	case v1alpha1.KafkaACLPermissionAllow:
+		return sarama.AclPermissionAllow
+	case v1alpha1.KafkaACLPermissionDeny:
+		return sarama.AclPermissionDeny
 	default:
-		return errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", accessType), "unrecognized access type")
+		return sarama.AclPermissionUnknown
 	}
 }
 
-func (k *kafkaClient) ListUserACLs() ([]sarama.ResourceAcls, error) {
-	acls, err := k.admin.ListAcls(sarama.AclFilter{})
+// aclBinding is a single ACL bound to a resource
+type aclBinding struct {
+	Resource sarama.Resource
+	Acl      sarama.Acl
+}
+
+// String returns the raw representation of the ACL binding used in the KafkaUser status
+func (b aclBinding) String() string {
+	return fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s", b.Acl.Principal, b.Resource.ResourceType.String(),
+		strings.ToUpper(b.Resource.ResourcePatternType.String()), b.Resource.ResourceName,
+		b.Acl.Operation.String(), b.Acl.PermissionType.String(), b.Acl.Host)
+}
+
+// ListUserACLs returns the ACLs bound to the principal of the user on any resource
+func (k *kafkaClient) ListUserACLs(dn string) ([]sarama.ResourceAcls, error) {
+	principal := fmt.Sprintf("User:%s", dn)
+	acls, err := k.admin.ListAcls(sarama.AclFilter{
+		ResourceType:              sarama.AclResourceAny,
+		ResourcePatternTypeFilter: sarama.AclPatternAny,
+		Principal:                 &principal,
+		Operation:                 sarama.AclOperationAny,
+		PermissionType:            sarama.AclPermissionAny,
+	})
 	if err != nil {
 		return nil, err
 	}
 	return acls, nil
 }
 
-// DeleteUserACLs removes all ACLs for a given user
-func (k *kafkaClient) DeleteUserACLs(dn string, patternType v1alpha1.KafkaPatternType) error {
-	if patternType == "" {
-		patternType = v1alpha1.KafkaPatternTypeDefault
+// ReconcileUserACLs makes the ACLs bound to the principal of the user match the ACLs needed by the topic grants and
+// the ACLs of the user. The missing ACLs are created and the others bound to the principal are deleted. It returns the
+// raw representation of the created and deleted ACLs.
+func (k *kafkaClient) ReconcileUserACLs(dn string, grants []v1alpha1.UserTopicGrant, acls []v1alpha1.UserACL) ([]string, []string, error) {
+	var desired []aclBinding
+	for _, grant := range grants {
+		bindings, err := topicGrantACLs(dn, grant)
+		if err != nil {
+			return nil, nil, err
+		}
+		desired = append(desired, bindings...)
 	}
-	aclPatternType := AclPatternTypeMapping(patternType)
-	matches, err := k.admin.DeleteACL(sarama.AclFilter{
-		Principal:                 &dn,
-		ResourcePatternTypeFilter: aclPatternType,
-		Operation:                 sarama.AclOperationDelete,
-		ResourceType:              sarama.AclResourceTopic,
-		PermissionType:            sarama.AclPermissionAny,
-	}, false)
+	for _, acl := range acls {
+		bindings, err := userResourceACLs(dn, acl)
+		if err != nil {
+			return nil, nil, err
+		}
+		desired = append(desired, bindings...)
+	}
+
+	current, err := k.ListUserACLs(dn)
 	if err != nil {
-		return err
+		return nil, nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error listing ACLs", "user", dn)
 	}
-	for _, x := range matches {
-		if x.Err != sarama.ErrNoError {
-			return x.Err
+	existing := make(map[aclBinding]bool)
+	for _, resourceAcls := range current {
+		for _, acl := range resourceAcls.Acls {
+			existing[aclBinding{Resource: resourceAcls.Resource, Acl: *acl}] = true
 		}
 	}
-	return nil
-}
 
-func (k *kafkaClient) createReadACLs(dn string, topic string, patternType sarama.AclResourcePatternType) (err error) {
-	if err = k.createCommonACLs(dn, topic, patternType); err != nil {
-		return
+	var missing []aclBinding
+	wanted := make(map[aclBinding]bool, len(desired))
+	for _, binding := range desired {
+		if !existing[binding] && !wanted[binding] {
+			missing = append(missing, binding)
+		}
+		wanted[binding] = true
+	}
+	var extra []aclBinding
+	for binding := range existing {
+		if !wanted[binding] {
+			extra = append(extra, binding)
+		}
 	}
 
-	// READ on topic
-	if err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceTopic,
-		ResourceName:        topic,
-		ResourcePatternType: patternType,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationRead,
-		PermissionType: sarama.AclPermissionAllow,
-	}); err != nil {
-		return
-	}
-
-	// READ on groups
-	err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceGroup,
-		ResourceName:        "*",
-		ResourcePatternType: sarama.AclPatternLiteral,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationRead,
-		PermissionType: sarama.AclPermissionAllow,
-	})
+	if err = k.createACLs(missing); err != nil {
+		return nil, nil, err
+	}
+	if err = k.deleteACLs(extra); err != nil {
+		return nil, nil, err
+	}
+	return aclBindingStrings(missing), aclBindingStrings(extra), nil
+}
 
-	return
+// createACLs creates the ACL bindings grouped by resource
+func (k *kafkaClient) createACLs(bindings []aclBinding) error {
+	if len(bindings) == 0 {
+		return nil
+	}
+	var resourceACLs []*sarama.ResourceAcls
+	byResource := make(map[sarama.Resource]*sarama.ResourceAcls)
+	for _, binding := range bindings {
+		resourceAcls, ok := byResource[binding.Resource]
+		if !ok {
+			resourceAcls = &sarama.ResourceAcls{Resource: binding.Resource}
+			byResource[binding.Resource] = resourceAcls
+			resourceACLs = append(resourceACLs, resourceAcls)
+		}
+		acl := binding.Acl
+		resourceAcls.Acls = append(resourceAcls.Acls, &acl)
+	}
+	// CreateACLs returns no error if the ACLs already exist
+	if err := k.admin.CreateACLs(resourceACLs); err != nil {
+		return errorfactory.New(errorfactory.BrokersRequestError{}, err, "error creating ACLs")
+	}
+	return nil
 }
 
-func (k *kafkaClient) createWriteACLs(dn string, topic string, patternType sarama.AclResourcePatternType) (err error) {
-	if err = k.createCommonACLs(dn, topic, patternType); err != nil {
-		return
+// deleteACLs deletes each of the ACL bindings with a filter matching only the binding
+func (k *kafkaClient) deleteACLs(bindings []aclBinding) error {
+	for _, binding := range bindings {
+		binding := binding
+		matches, err := k.admin.DeleteACL(sarama.AclFilter{
+			ResourceType:              binding.Resource.ResourceType,
+			ResourceName:              &binding.Resource.ResourceName,
+			ResourcePatternTypeFilter: binding.Resource.ResourcePatternType,
+			Principal:                 &binding.Acl.Principal,
+			Host:                      &binding.Acl.Host,
+			Operation:                 binding.Acl.Operation,
+			PermissionType:            binding.Acl.PermissionType,
+		}, false)
+		if err != nil {
+			return errorfactory.New(errorfactory.BrokersRequestError{}, err, "error deleting ACL", "acl", binding.String())
+		}
+		for _, x := range matches {
+			if x.Err != sarama.ErrNoError {
+				return errorfactory.New(errorfactory.BrokersRequestError{}, x.Err, "error deleting ACL", "acl", binding.String())
+			}
+		}
 	}
+	return nil
+}
 
-	// WRITE on topic
-	if err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceTopic,
-		ResourceName:        topic,
-		ResourcePatternType: patternType,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationWrite,
-		PermissionType: sarama.AclPermissionAllow,
-	}); err != nil {
-		return
+// topicGrantACLs returns the ACL bindings the access type of the topic grant needs
+func topicGrantACLs(dn string, grant v1alpha1.UserTopicGrant) ([]aclBinding, error) {
+	patternType := grant.PatternType
+	if patternType == "" {
+		patternType = v1alpha1.KafkaPatternTypeDefault
+	}
+	aclPatternType := AclPatternTypeMapping(patternType)
+	if aclPatternType == sarama.AclPatternUnknown {
+		return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", patternType), "unrecognized pattern type")
 	}
 
-	// CREATE on topic
-	err = k.admin.CreateACL(sarama.Resource{
+	principal := fmt.Sprintf("User:%s", dn)
+	topic := sarama.Resource{
 		ResourceType:        sarama.AclResourceTopic,
-		ResourceName:        topic,
-		ResourcePatternType: patternType,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationCreate,
-		PermissionType: sarama.AclPermissionAllow,
-	})
+		ResourceName:        grant.TopicName,
+		ResourcePatternType: aclPatternType,
+	}
+	allow := func(resource sarama.Resource, operation sarama.AclOperation) aclBinding {
+		return aclBinding{
+			Resource: resource,
+			Acl: sarama.Acl{
+				Principal:      principal,
+				Host:           v1alpha1.KafkaACLAnyHost,
+				Operation:      operation,
+				PermissionType: sarama.AclPermissionAllow,
+			},
+		}
+	}
 
-	return
+	// DESCRIBE and DESCRIBE_CONFIGS on topic
+	bindings := []aclBinding{
+		allow(topic, sarama.AclOperationDescribe),
+		allow(topic, sarama.AclOperationDescribeConfigs),
+	}
+	switch grant.AccessType {
+	case v1alpha1.KafkaAccessTypeRead:
+		groupPatternType := AclPatternTypeMapping(grant.GetConsumerGroupPatternType())
+		if groupPatternType == sarama.AclPatternUnknown {
+			return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", grant.ConsumerGroupPatternType), "unrecognized consumer group pattern type")
+		}
+		// READ on topic and the consumer groups of the grant
+		return append(bindings,
+			allow(topic, sarama.AclOperationRead),
+			allow(sarama.Resource{
+				ResourceType:        sarama.AclResourceGroup,
+				ResourceName:        grant.GetConsumerGroup(),
+				ResourcePatternType: groupPatternType,
+			}, sarama.AclOperationRead),
+		), nil
+	case v1alpha1.KafkaAccessTypeWrite:
+		// WRITE and CREATE on topic
+		return append(bindings,
+			allow(topic, sarama.AclOperationWrite),
+			allow(topic, sarama.AclOperationCreate),
+		), nil
+	default:
+		return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", grant.AccessType), "unrecognized access type")
+	}
 }
 
-func (k *kafkaClient) createCommonACLs(dn string, topic string, patternType sarama.AclResourcePatternType) (err error) {
-	// DESCRIBE on topic
-	if err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceTopic,
-		ResourceName:        topic,
-		ResourcePatternType: patternType,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationDescribe,
-		PermissionType: sarama.AclPermissionAllow,
-	}); err != nil {
-		return
+// userResourceACLs returns the ACL bindings of the user for every operation and host of the ACL
+func userResourceACLs(dn string, acl v1alpha1.UserACL) ([]aclBinding, error) {
+	resourceType := AclResourceTypeMapping(acl.ResourceType)
+	if resourceType == sarama.AclResourceUnknown {
+		return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", acl.ResourceType), "unrecognized resource type")
+	}
+	if acl.GetResourceName() == "" {
+		return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("missing name of %s resource", acl.ResourceType), "invalid ACL")
+	}
+	patternType := AclPatternTypeMapping(acl.GetPatternType())
+	if patternType != sarama.AclPatternLiteral && patternType != sarama.AclPatternPrefixed {
+		return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unsupported type: %s", acl.PatternType), "unrecognized pattern type")
+	}
+	permissionType := AclPermissionTypeMapping(acl.GetPermissionType())
+	if permissionType == sarama.AclPermissionUnknown {
+		return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", acl.PermissionType), "unrecognized permission type")
 	}
 
-	// DESCRIBE_CONFIGS on topic
-	if err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceTopic,
-		ResourceName:        topic,
+	resource := sarama.Resource{
+		ResourceType:        resourceType,
+		ResourceName:        acl.GetResourceName(),
 		ResourcePatternType: patternType,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationDescribeConfigs,
-		PermissionType: sarama.AclPermissionAllow,
-	}); err != nil {
-		return
-	}
-	return
+	}
+	var bindings []aclBinding
+	for _, op := range acl.Operations {
+		operation := AclOperationMapping(op)
+		if operation == sarama.AclOperationUnknown {
+			return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", op), "unrecognized operation")
+		}
+		for _, host := range acl.GetHosts() {
+			bindings = append(bindings, aclBinding{
+				Resource: resource,
+				Acl: sarama.Acl{
+					Principal:      fmt.Sprintf("User:%s", dn),
+					Host:           host,
+					Operation:      operation,
+					PermissionType: permissionType,
+				},
+			})
+		}
+	}
+	return bindings, nil
+}
+
+// aclBindingStrings returns the sorted raw representation of the ACL bindings
+func aclBindingStrings(bindings []aclBinding) []string {
+	if len(bindings) == 0 {
+		return nil
+	}
+	acls := make([]string, 0, len(bindings))
+	for _, binding := range bindings {
+		acls = append(acls, binding.String())
+	}
+	sort.Strings(acls)
+	return acls
 }
@@ -18,12 +18,12 @@ import (
 	"testing"
 
 	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
 	"github.com/banzaicloud/koperator/api/v1alpha1"
 )
 
-func TestCreateUserACLs(t *testing.T) {
-	client := newOpenedMockClient()
-
+func TestTopicGrantACLs(t *testing.T) {
 	validAccessTypes := []v1alpha1.KafkaAccessType{
 		"read",
 		"write"}
@@ -42,10 +42,13 @@ func TestCreateUserACLs(t *testing.T) {
 		"helloWorld"}
 	allPatternTypes := append(validPatternTypes, invalidPatternTypes...)
 
+	grant := func(accessType v1alpha1.KafkaAccessType, patternType v1alpha1.KafkaPatternType) v1alpha1.UserTopicGrant {
+		return v1alpha1.UserTopicGrant{TopicName: "test-topic", AccessType: accessType, PatternType: patternType}
+	}
 	// Test all valid combinations of accessType and patternType
 	for _, accessType := range validAccessTypes {
 		for _, patternType := range validPatternTypes {
-			if err := client.CreateUserACLs(accessType, patternType, "test-user", "test-topic"); err != nil {
+			if _, err := topicGrantACLs("test-user", grant(accessType, patternType)); err != nil {
 				t.Error("Expected no error, got:", err)
 			}
 		}
@@ -53,7 +56,7 @@ func TestCreateUserACLs(t *testing.T) {
 	// Test invalid accessTypes against all patternTypes
 	for _, accessType := range invalidAccessTypes {
 		for _, patternType := range allPatternTypes {
-			if err := client.CreateUserACLs(accessType, patternType, "test-user", "test-topic"); err == nil {
+			if _, err := topicGrantACLs("test-user", grant(accessType, patternType)); err == nil {
 				t.Error("Expected error, got nil")
 			}
 		}
@@ -61,45 +64,187 @@ func TestCreateUserACLs(t *testing.T) {
 	// Test invalid patternTypes against all accessTypes
 	for _, patternType := range invalidPatternTypes {
 		for _, accessType := range allAccessTypes {
-			if err := client.CreateUserACLs(accessType, patternType, "test-user", "test-topic"); err == nil {
+			if _, err := topicGrantACLs("test-user", grant(accessType, patternType)); err == nil {
 				t.Error("Expected error, got nil")
 			}
 		}
 	}
+}
 
-	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
-	// Test all combinations of accessType and patternType
-	for _, accessType := range allAccessTypes {
-		for _, patternType := range allPatternTypes {
-			if err := client.CreateUserACLs(accessType, patternType, "test-user", "test-topic"); err == nil {
-				t.Error("Expected error, got nil")
-			}
+func TestReconcileUserResourceACLs(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := client.admin.(*mockClusterAdmin)
+
+	_, _, err := client.ReconcileUserACLs("test-user", nil, []v1alpha1.UserACL{
+		{
+			ResourceType: v1alpha1.KafkaResourceTypeCluster,
+			Operations:   []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationIdempotentWrite, v1alpha1.KafkaACLOperationDescribe},
+		},
+		{
+			ResourceType:   v1alpha1.KafkaResourceTypeTransactionalID,
+			ResourceName:   "orders-",
+			PatternType:    v1alpha1.KafkaPatternTypePrefixed,
+			Operations:     []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationWrite},
+			PermissionType: v1alpha1.KafkaACLPermissionDeny,
+			Hosts:          []string{"10.0.0.1", "10.0.0.2"},
+		},
+	})
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+
+	cluster := admin.mockACLs[sarama.Resource{
+		ResourceType:        sarama.AclResourceCluster,
+		ResourceName:        v1alpha1.KafkaClusterResourceName,
+		ResourcePatternType: sarama.AclPatternLiteral,
+	}]
+	if cluster == nil {
+		t.Fatal("Expected ACLs on the cluster resource, got none")
+	}
+	assert.ElementsMatch(t, []*sarama.Acl{
+		{Principal: "User:test-user", Host: "*", Operation: sarama.AclOperationIdempotentWrite, PermissionType: sarama.AclPermissionAllow},
+		{Principal: "User:test-user", Host: "*", Operation: sarama.AclOperationDescribe, PermissionType: sarama.AclPermissionAllow},
+	}, cluster.Acls)
+
+	transactionalID := admin.mockACLs[sarama.Resource{
+		ResourceType:        sarama.AclResourceTransactionalID,
+		ResourceName:        "orders-",
+		ResourcePatternType: sarama.AclPatternPrefixed,
+	}]
+	if transactionalID == nil {
+		t.Fatal("Expected ACLs on the transactional id resource, got none")
+	}
+	assert.ElementsMatch(t, []*sarama.Acl{
+		{Principal: "User:test-user", Host: "10.0.0.1", Operation: sarama.AclOperationWrite, PermissionType: sarama.AclPermissionDeny},
+		{Principal: "User:test-user", Host: "10.0.0.2", Operation: sarama.AclOperationWrite, PermissionType: sarama.AclPermissionDeny},
+	}, transactionalID.Acls)
+
+	invalidACLs := []v1alpha1.UserACL{
+		{ResourceType: "helloWorld", ResourceName: "test", Operations: []v1alpha1.KafkaACLOperation{"read"}},
+		{ResourceType: v1alpha1.KafkaResourceTypeGroup, Operations: []v1alpha1.KafkaACLOperation{"read"}},
+		{ResourceType: v1alpha1.KafkaResourceTypeGroup, ResourceName: "test", PatternType: v1alpha1.KafkaPatternTypeAny, Operations: []v1alpha1.KafkaACLOperation{"read"}},
+		{ResourceType: v1alpha1.KafkaResourceTypeGroup, ResourceName: "test", Operations: []v1alpha1.KafkaACLOperation{"helloWorld"}},
+		{ResourceType: v1alpha1.KafkaResourceTypeGroup, ResourceName: "test", PermissionType: "helloWorld", Operations: []v1alpha1.KafkaACLOperation{"read"}},
+	}
+	for _, acl := range invalidACLs {
+		if _, _, err := client.ReconcileUserACLs("test-user", nil, []v1alpha1.UserACL{acl}); err == nil {
+			t.Errorf("Expected error for %+v, got nil", acl)
 		}
 	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, _, err := client.ReconcileUserACLs("test-user", nil, []v1alpha1.UserACL{{
+		ResourceType: v1alpha1.KafkaResourceTypeCluster,
+		Operations:   []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationIdempotentWrite},
+	}}); err == nil {
+		t.Error("Expected error, got nil")
+	}
 }
 
-func TestDeleteUserACLs(t *testing.T) {
+func TestReconcileUserACLs(t *testing.T) {
 	client := newOpenedMockClient()
+	admin := client.admin.(*mockClusterAdmin)
+	grants := []v1alpha1.UserTopicGrant{
+		{TopicName: "test-topic", AccessType: v1alpha1.KafkaAccessTypeRead, PatternType: v1alpha1.KafkaPatternTypeLiteral},
+		{TopicName: "test-topic", AccessType: v1alpha1.KafkaAccessTypeWrite, PatternType: v1alpha1.KafkaPatternTypeLiteral},
+	}
+	acls := []v1alpha1.UserACL{{
+		ResourceType: v1alpha1.KafkaResourceTypeCluster,
+		Operations:   []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationIdempotentWrite},
+	}}
+	// ACLs of other principals are not touched
+	if err := admin.CreateACL(sarama.Resource{
+		ResourceType:        sarama.AclResourceTopic,
+		ResourceName:        "test-topic",
+		ResourcePatternType: sarama.AclPatternLiteral,
+	}, sarama.Acl{Principal: "User:CN=other", Host: "*", Operation: sarama.AclOperationWrite, PermissionType: sarama.AclPermissionAllow}); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
 
-	validPatternTypes := []v1alpha1.KafkaPatternType{
-		"any",
-		"literal",
-		"match",
-		"prefixed",
-		""}
+	added, removed, err := client.ReconcileUserACLs("CN=test", grants, acls)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	assert.ElementsMatch(t, []string{
+		"User:CN=test,Topic,LITERAL,test-topic,Describe,Allow,*",
+		"User:CN=test,Topic,LITERAL,test-topic,DescribeConfigs,Allow,*",
+		"User:CN=test,Topic,LITERAL,test-topic,Read,Allow,*",
+		"User:CN=test,Group,LITERAL,*,Read,Allow,*",
+		"User:CN=test,Topic,LITERAL,test-topic,Write,Allow,*",
+		"User:CN=test,Topic,LITERAL,test-topic,Create,Allow,*",
+		"User:CN=test,Cluster,LITERAL,kafka-cluster,IdempotentWrite,Allow,*",
+	}, added)
+	assert.Empty(t, removed)
 
-	for _, patternType := range validPatternTypes {
-		if err := client.DeleteUserACLs("test-user", patternType); err != nil {
-			t.Error("Expected no error, got:", err)
-		}
+	// reconciling again changes nothing
+	added, removed, err = client.ReconcileUserACLs("CN=test", grants, acls)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
 
-		if err := client.DeleteUserACLs("with-error", patternType); err == nil {
-			t.Error("Expected error, got nil")
-		}
+	// the ACLs of the removed grant and ACL are pruned
+	added, removed, err = client.ReconcileUserACLs("CN=test", grants[:1], nil)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	assert.Empty(t, added)
+	assert.Equal(t, []string{
+		"User:CN=test,Cluster,LITERAL,kafka-cluster,IdempotentWrite,Allow,*",
+		"User:CN=test,Topic,LITERAL,test-topic,Create,Allow,*",
+		"User:CN=test,Topic,LITERAL,test-topic,Write,Allow,*",
+	}, removed)
+
+	current, err := client.ListUserACLs("CN=test")
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	count := 0
+	for _, resourceAcls := range current {
+		count += len(resourceAcls.Acls)
+	}
+	assert.Equal(t, 4, count)
+
+	// removing everything keeps the ACLs of the other principals
+	_, removed, err = client.ReconcileUserACLs("CN=test", nil, nil)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	assert.Len(t, removed, 4)
+	other, _ := client.ListUserACLs("CN=other")
+	assert.Len(t, other, 1)
+
+	// scoping the consumer group of the read grant replaces the ACL of every consumer group
+	_, _, err = client.ReconcileUserACLs("CN=test", grants[:1], nil)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	scoped := []v1alpha1.UserTopicGrant{{
+		TopicName:                "test-topic",
+		AccessType:               v1alpha1.KafkaAccessTypeRead,
+		PatternType:              v1alpha1.KafkaPatternTypeLiteral,
+		ConsumerGroup:            "test-group-",
+		ConsumerGroupPatternType: v1alpha1.KafkaPatternTypePrefixed,
+	}}
+	added, removed, err = client.ReconcileUserACLs("CN=test", scoped, nil)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	assert.Equal(t, []string{"User:CN=test,Group,PREFIXED,test-group-,Read,Allow,*"}, added)
+	assert.Equal(t, []string{"User:CN=test,Group,LITERAL,*,Read,Allow,*"}, removed)
+
+	if _, _, err = client.ReconcileUserACLs("CN=test", []v1alpha1.UserTopicGrant{{TopicName: "test-topic", AccessType: "helloWorld"}}, nil); err == nil {
+		t.Error("Expected error, got nil")
+	}
+	if _, _, err = client.ReconcileUserACLs("CN=test", []v1alpha1.UserTopicGrant{{
+		TopicName: "test-topic", AccessType: v1alpha1.KafkaAccessTypeRead, ConsumerGroup: "test-group", ConsumerGroupPatternType: "helloWorld",
+	}}, nil); err == nil {
+		t.Error("Expected error, got nil")
 	}
 
 	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
-	if err := client.DeleteUserACLs("test-userr", ""); err == nil {
+	if _, _, err = client.ReconcileUserACLs("CN=test", grants, nil); err == nil {
 		t.Error("Expected error, got nil")
 	}
 }
@@ -48,7 +48,7 @@ func (c *certManager) ReconcileUserCertificate(
 	var err error
 	var secret *corev1.Secret
 	// See if we have an existing certificate for this user already
-	_, err = c.getUserCertificate(ctx, user)
+	cert, err := c.getUserCertificate(ctx, user)
 
 	if err != nil && apierrors.IsNotFound(err) {
 		// the certificate does not exist, let's make one
@@ -58,13 +58,19 @@ func (c *certManager) ReconcileUserCertificate(
 				return nil, err
 			}
 		}
-		cert := c.clusterCertificateForUser(user, clusterDomain)
+		cert = c.clusterCertificateForUser(user, clusterDomain)
 		if err = c.client.Create(ctx, cert); err != nil {
 			return nil, errorfactory.New(errorfactory.APIFailure{}, err, "could not create user certificate")
 		}
 	} else if err != nil {
 		// API failure, requeue
 		return nil, errorfactory.New(errorfactory.APIFailure{}, err, "failed looking up user certificate")
+	} else if renewBefore := certificateRenewBefore(user); cert.Spec.RenewBefore == nil || cert.Spec.RenewBefore.Duration != renewBefore {
+		// the renewal time of the certificates created before it was configurable is updated
+		cert.Spec.RenewBefore = &metav1.Duration{Duration: renewBefore}
+		if err = c.client.Update(ctx, cert); err != nil {
+			return nil, errorfactory.New(errorfactory.APIFailure{}, err, "could not update user certificate")
+		}
 	}
 
 	// Get the secret created from the certificate
@@ -78,11 +84,21 @@ func (c *certManager) ReconcileUserCertificate(
 		return nil, err
 	}
 
-	return &pkicommon.UserCertificate{
+	userCert := &pkicommon.UserCertificate{
 		CA:          secret.Data[v1alpha1.CoreCACertKey],
 		Certificate: secret.Data[corev1.TLSCertKey],
 		Key:         secret.Data[corev1.TLSPrivateKeyKey],
-	}, nil
+	}
+
+	// cert-manager renews the certificate itself and retries with a backoff, the time of the last failed attempt
+	// is kept until an attempt succeeds. The current certificate stays in use meanwhile.
+	if cert.Status.LastFailureTime != nil && cert.Status.NotBefore != nil {
+		userCert.RenewalFailureTime = cert.Status.LastFailureTime.Time
+		return userCert, errorfactory.New(errorfactory.CertificateRenewalFailed{}, errors.New(certificateNotReadyMessage(cert)),
+			"cert-manager failed to renew user certificate", "certificate", cert.GetName(), "lastFailureTime", cert.Status.LastFailureTime.Time)
+	}
+
+	return userCert, nil
 }
 
 // injectJKSPassword ensures that a secret contains JKS password when requested
@@ -162,7 +178,8 @@ func (c *certManager) clusterCertificateForUser(
 				Kind:  caKind,
 				Group: caGroup,
 			},
-			Duration: &metav1.Duration{Duration: time.Duration(user.Spec.GetExpirationSeconds()) * time.Second},
+			Duration:    &metav1.Duration{Duration: time.Duration(user.Spec.GetExpirationSeconds()) * time.Second},
+			RenewBefore: &metav1.Duration{Duration: certificateRenewBefore(user)},
 		},
 	}
 	if user.Spec.IncludeJKS {
@@ -184,6 +201,23 @@ func (c *certManager) clusterCertificateForUser(
 	return cert
 }
 
+// certificateRenewBefore returns how long before its expiry the certificate of the user is renewed
+func certificateRenewBefore(user *v1alpha1.KafkaUser) time.Duration {
+	duration := time.Duration(user.Spec.GetExpirationSeconds()) * time.Second
+	return duration / 100 * time.Duration(100-user.Spec.GetCertificateRenewalPercentage())
+}
+
+// certificateNotReadyMessage returns the message of the condition of the certificate explaining why it is not ready
+func certificateNotReadyMessage(cert *certv1.Certificate) string {
+	for _, cond := range cert.Status.Conditions {
+		if (cond.Type == certv1.CertificateConditionIssuing || cond.Type == certv1.CertificateConditionReady) &&
+			cond.Status == certmeta.ConditionFalse && cond.Message != "" {
+			return cond.Message
+		}
+	}
+	return "certificate issuance failed"
+}
+
 // getCA returns the CA name/kind/group for the KafkaCluster
 func (c *certManager) getCA(user *v1alpha1.KafkaUser) (caName, caKind, caGroup string) {
 	var issuerRef *certmeta.ObjectReference
@@ -17,11 +17,17 @@ package certmanagerpki
 import (
 	"context"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"emperror.dev/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 
+	certv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
@@ -128,3 +134,73 @@ func TestReconcileUserCertificate(t *testing.T) {
 		t.Error("Expected  error, got nil")
 	}
 }
+
+func TestReconcileUserCertificateRenewal(t *testing.T) {
+	ctx := context.Background()
+	manager, err := newMock(newMockCluster())
+	if err != nil {
+		t.Error("Expected no error during initialization, got:", err)
+	}
+	user := newMockUser()
+	user.Spec.ExpirationSeconds = util.Int32Pointer(10000)
+	user.Spec.CertificateRenewalPercentage = util.Int32Pointer(80)
+	if err := manager.client.Create(ctx, user); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if err := manager.client.Create(ctx, manager.clusterCertificateForUser(user, "cluster.local")); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if err := manager.client.Create(ctx, newMockUserSecret()); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	// The certificate is renewed after the configured percentage of its lifetime
+	if _, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme, "cluster.local"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	cert := &certv1.Certificate{}
+	if err := manager.client.Get(ctx, types.NamespacedName{Name: user.Name, Namespace: user.Namespace}, cert); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if cert.Spec.RenewBefore == nil || cert.Spec.RenewBefore.Duration != 2000*time.Second {
+		t.Error("Expected certificate to be renewed 2000s before its expiry, got:", cert.Spec.RenewBefore)
+	}
+
+	// The renewal time of an existing certificate is updated
+	user.Spec.CertificateRenewalPercentage = util.Int32Pointer(50)
+	if _, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme, "cluster.local"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if err := manager.client.Get(ctx, types.NamespacedName{Name: user.Name, Namespace: user.Namespace}, cert); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if cert.Spec.RenewBefore == nil || cert.Spec.RenewBefore.Duration != 5000*time.Second {
+		t.Error("Expected certificate to be renewed 5000s before its expiry, got:", cert.Spec.RenewBefore)
+	}
+
+	// The failure of cert-manager to renew the issued certificate is reported
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	cert.Status.NotBefore = &now
+	cert.Status.LastFailureTime = &now
+	cert.Status.Conditions = []certv1.CertificateCondition{{
+		Type:    certv1.CertificateConditionIssuing,
+		Status:  cmmeta.ConditionFalse,
+		Message: "issuer is not ready",
+	}}
+	if err := manager.client.Update(ctx, cert); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	userCert, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme, "cluster.local")
+	if !errors.As(err, &errorfactory.CertificateRenewalFailed{}) {
+		t.Error("Expected certificate renewal failed error, got:", err)
+	} else if !strings.Contains(err.Error(), "issuer is not ready") {
+		t.Error("Expected the reason of the failure in the error, got:", err)
+	}
+	// The current certificate is returned along with the failure
+	if userCert == nil {
+		t.Fatal("Expected the current certificate, got nil")
+	}
+	if !userCert.RenewalFailureTime.Equal(now.Time) {
+		t.Errorf("Expected renewal failure time %s, got: %s", now.Time, userCert.RenewalFailureTime)
+	}
+}
@@ -22,8 +22,14 @@ import (
 )
 
 const (
-	DependingCsrAnnotation     string = "banzaicloud.io/csr"
+	DependingCsrAnnotation string = "banzaicloud.io/csr"
+	// RenewingCsrAnnotation holds the name of the signing request renewing the certificate of the user secret
+	RenewingCsrAnnotation      string = "banzaicloud.io/renewing-csr"
 	IncludeFullChainAnnotation string = "csr.banzaicloud.io/fullchain"
+
+	// renewalPrivateKeyKey is where the private key of the renewal signing request is kept in the user secret
+	// until the renewed certificate is issued
+	renewalPrivateKeyKey string = "renewal.key"
 )
 
 type K8sCSR interface {
@@ -20,6 +20,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
 	"github.com/banzaicloud/koperator/pkg/util"
 
 	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
@@ -110,13 +112,19 @@ func (c *k8sCSR) ReconcileUserCertificate(
 	// skip handling CSR if the secret already includes all the required fields
 	kafkaUserSecretReady := isKafkaUserCertificateReady(secret, user.Spec.IncludeJKS)
 	if kafkaUserSecretReady {
-		return &pkicommon.UserCertificate{
-			CA:          secret.Data[v1alpha1.CaChainPem],
-			Certificate: secret.Data[corev1.TLSCertKey],
-			Key:         secret.Data[corev1.TLSPrivateKeyKey],
-			JKS:         secret.Data[v1alpha1.TLSJKSKeyStore],
-			Password:    secret.Data[v1alpha1.PasswordKey],
-		}, nil
+		// The certificate of a user being deleted is not renewed
+		if k8sutil.IsMarkedForDeletion(user.ObjectMeta) {
+			return userCertificateFromSecret(secret), nil
+		}
+		userCert, err := c.reconcileUserCertificateRenewal(ctx, user, secret)
+		if err != nil {
+			if userCert == nil {
+				return nil, err
+			}
+			return userCert, errorfactory.New(errorfactory.CertificateRenewalFailed{}, err,
+				"failed to renew user certificate", "secretName", secret.GetName(), "namespace", secret.GetNamespace())
+		}
+		return userCert, nil
 	}
 
 	signingRequestGenName, ok := secret.Annotations[DependingCsrAnnotation]
@@ -182,6 +190,13 @@ func (c *k8sCSR) ReconcileUserCertificate(
 			"csrName", signingReq.GetName())
 	}
 
+	return c.storeUserCertificate(ctx, user, secret, signingReq)
+}
+
+// storeUserCertificate stores the certificate issued for the signing request, its CA chain and the keystore
+// requested by the user in the user secret
+func (c *k8sCSR) storeUserCertificate(ctx context.Context, user *v1alpha1.KafkaUser, secret *corev1.Secret,
+	signingReq *certsigningreqv1.CertificateSigningRequest) (*pkicommon.UserCertificate, error) {
 	certs, err := certutil.ParseCertificates(signingReq.Status.Certificate)
 	if err != nil {
 		return nil, err
@@ -213,20 +228,151 @@ func (c *k8sCSR) ReconcileUserCertificate(
 		}
 	}
 
-	typeMeta := secret.TypeMeta
-	err = c.client.Update(ctx, secret)
+	if err = c.updateSecret(ctx, secret); err != nil {
+		return nil, err
+	}
+	return userCertificateFromSecret(secret), nil
+}
+
+// reconcileUserCertificateRenewal returns the certificate of the ready user secret and renews it with a new private key
+// and signing request once its renewal time has passed. The current certificate and key are kept in the secret until
+// the new certificate is issued, they are returned along with the error when the renewal fails.
+func (c *k8sCSR) reconcileUserCertificateRenewal(ctx context.Context, user *v1alpha1.KafkaUser, secret *corev1.Secret) (*pkicommon.UserCertificate, error) {
+	log := logr.FromContextOrDiscard(ctx)
+	userCert := userCertificateFromSecret(secret)
+	current, err := certutil.DecodeCertificate(secret.Data[corev1.TLSCertKey])
 	if err != nil {
 		return nil, err
 	}
-	secret.TypeMeta = typeMeta
+	if time.Now().Before(user.Spec.GetCertificateRenewalTime(current.NotBefore, current.NotAfter)) {
+		return userCert, nil
+	}
+	// failed keeps the current certificate, failures reported again have the same time
+	failed := func(failureTime time.Time, err error) (*pkicommon.UserCertificate, error) {
+		userCert.RenewalFailureTime = failureTime
+		return userCert, err
+	}
+	// pending keeps the current certificate while it is valid
+	pending := func(msg string, keysAndValues ...interface{}) (*pkicommon.UserCertificate, error) {
+		if time.Now().After(current.NotAfter) {
+			return failed(current.NotAfter, errors.NewWithDetails("certificate expired before it could be renewed", "notAfter", current.NotAfter))
+		}
+		log.Info(msg, keysAndValues...)
+		return userCert, nil
+	}
 
+	signingRequestName, ok := secret.Annotations[RenewingCsrAnnotation]
+	if !ok {
+		log.Info("Renewing user certificate", "notAfter", current.NotAfter)
+		renewalKey, err := certutil.GeneratePrivateKeyInPemFormat()
+		if err != nil {
+			return failed(time.Now(), err)
+		}
+		signingReq, err := c.generateAndCreateCSR(ctx, renewalKey, user)
+		if err != nil {
+			return failed(time.Now(), err)
+		}
+		secret.Data[renewalPrivateKeyKey] = renewalKey
+		secret.Annotations = util.MergeAnnotations(secret.Annotations, map[string]string{RenewingCsrAnnotation: signingReq.GetName()})
+		if err = c.updateSecret(ctx, secret); err != nil {
+			return failed(time.Now(), err)
+		}
+		return pending("certificate renewal requested", "csrName", signingReq.GetName())
+	}
+
+	// Signing requests are cluster scoped
+	signingReq, err := c.getUserSigningRequest(ctx, signingRequestName, "")
+	if apierrors.IsNotFound(err) {
+		// A new signing request is created by the next reconciliation
+		if err = c.discardCertificateRenewal(ctx, secret, nil); err != nil {
+			return failed(time.Now(), err)
+		}
+		return failed(time.Now(), errors.NewWithDetails("kubernetes deleted the renewal csr request", "csrName", signingRequestName))
+	} else if err != nil {
+		return failed(time.Now(), errors.WrapIfWithDetails(err,
+			"failed to get signing request from K8s", "signingRequestName", signingRequestName))
+	}
+	if _, ok := secret.Data[renewalPrivateKeyKey]; !ok {
+		// The certificate can't be used without the key of the signing request
+		if err = c.discardCertificateRenewal(ctx, secret, signingReq); err != nil {
+			return failed(time.Now(), err)
+		}
+		return failed(time.Now(), errors.NewWithDetails("private key of the renewal csr request is missing", "csrName", signingRequestName))
+	}
+
+	approved := false
+	for _, cond := range signingReq.Status.Conditions {
+		switch cond.Type {
+		case certsigningreqv1.CertificateApproved:
+			approved = true
+		case certsigningreqv1.CertificateDenied, certsigningreqv1.CertificateFailed:
+			// A new signing request is created by the next reconciliation
+			if err = c.discardCertificateRenewal(ctx, secret, signingReq); err != nil {
+				return failed(time.Now(), err)
+			}
+			return failed(time.Now(), errors.NewWithDetails("renewal csr request was not signed", "csrName", signingReq.GetName(),
+				"condition", cond.Type, "reason", cond.Reason, "message", cond.Message))
+		}
+	}
+	if !approved {
+		if strings.Split(signingReq.Spec.SignerName, "/")[0] != v1alpha1.CertManagerSignerNamePrefix {
+			return pending("renewal csr request is waiting for approval", "csrName", signingReq.GetName())
+		}
+		if err = c.Approve(ctx, signingReq); err != nil {
+			return failed(time.Now(), err)
+		}
+	}
+	if len(signingReq.Status.Certificate) == 0 {
+		return pending("renewed certificate is not issued yet", "csrName", signingReq.GetName())
+	}
+
+	log.Info("User certificate renewed", "csrName", signingReq.GetName())
+	secret.Data[corev1.TLSPrivateKeyKey] = secret.Data[renewalPrivateKeyKey]
+	delete(secret.Data, renewalPrivateKeyKey)
+	// The keystore contains the certificate and the key, it is generated again for the renewed ones
+	delete(secret.Data, v1alpha1.TLSJKSKeyStore)
+	delete(secret.Data, v1alpha1.TLSJKSTrustStore)
+	delete(secret.Data, v1alpha1.PasswordKey)
+	delete(secret.Annotations, RenewingCsrAnnotation)
+	secret.Annotations[DependingCsrAnnotation] = signingReq.GetName()
+	renewedCert, err := c.storeUserCertificate(ctx, user, secret, signingReq)
+	if err != nil {
+		return failed(time.Now(), err)
+	}
+	return renewedCert, nil
+}
+
+// discardCertificateRenewal removes the renewal in progress from the user secret and deletes its signing request,
+// so the next reconciliation starts a new renewal
+func (c *k8sCSR) discardCertificateRenewal(ctx context.Context, secret *corev1.Secret,
+	signingReq *certsigningreqv1.CertificateSigningRequest) error {
+	if signingReq != nil {
+		if err := c.client.Delete(ctx, signingReq); err != nil && !apierrors.IsNotFound(err) {
+			return errors.WrapIfWithDetails(err, "failed to delete renewal csr request", "csrName", signingReq.GetName())
+		}
+	}
+	delete(secret.Annotations, RenewingCsrAnnotation)
+	delete(secret.Data, renewalPrivateKeyKey)
+	return c.updateSecret(ctx, secret)
+}
+
+func userCertificateFromSecret(secret *corev1.Secret) *pkicommon.UserCertificate {
 	return &pkicommon.UserCertificate{
 		CA:          secret.Data[v1alpha1.CaChainPem],
 		Certificate: secret.Data[corev1.TLSCertKey],
 		Key:         secret.Data[corev1.TLSPrivateKeyKey],
 		JKS:         secret.Data[v1alpha1.TLSJKSKeyStore],
 		Password:    secret.Data[v1alpha1.PasswordKey],
-	}, nil
+	}
+}
+
+func (c *k8sCSR) updateSecret(ctx context.Context, secret *corev1.Secret) error {
+	typeMeta := secret.TypeMeta
+	if err := c.client.Update(ctx, secret); err != nil {
+		return err
+	}
+	secret.TypeMeta = typeMeta
+	return nil
 }
 
 // FinalizeUserCertificate removes/revokes a user certificate
@@ -16,25 +16,35 @@ package k8scsrpki
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
+	"math/big"
 	"testing"
+	"time"
+
+	"emperror.dev/errors"
 
 	"github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	certsigningreqv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	istioclientv1beta1 "github.com/banzaicloud/istio-client-go/pkg/networking/v1beta1"
 	banzaiistiov1alpha1 "github.com/banzaicloud/istio-operator/api/v2/v1alpha1"
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
 	"github.com/banzaicloud/koperator/pkg/util"
 	"github.com/banzaicloud/koperator/pkg/util/cert"
 )
@@ -120,3 +130,126 @@ func TestReconcileUserCertificate(t *testing.T) {
 	Expect(certReq.Subject.CommonName).To(Equal(user.GetName()))
 	Expect(certReq.DNSNames).To(ConsistOf(testDns))
 }
+
+// generateUserCertificate returns a PEM encoded certificate of the key valid between notBefore and notAfter
+func generateUserCertificate(key []byte, notBefore, notAfter time.Time) ([]byte, error) {
+	block, _ := pem.Decode(key)
+	privKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(notBefore.Unix()),
+		Subject:      pkix.Name{CommonName: "test-user"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &privKey.PublicKey, privKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+func TestReconcileUserCertificateRenewal(t *testing.T) {
+	g := NewGomegaWithT(t)
+	sch, err := setupSchemeForTests()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ctx := context.Background()
+	user := createKafkaUser()
+	user.SetUID("test-uid")
+	key, err := cert.GeneratePrivateKeyInPemFormat()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The certificate valid for 2 hours is past two thirds of its lifetime
+	now := time.Now().Truncate(time.Second)
+	current, err := generateUserCertificate(key, now.Add(-90*time.Minute), now.Add(30*time.Minute))
+	g.Expect(err).NotTo(HaveOccurred())
+	secret := generateUserSecret(key, user.Spec.SecretName, user.GetNamespace())
+	secret.Data[corev1.TLSCertKey] = current
+	secret.Data[v1alpha1.CaChainPem] = []byte{}
+	g.Expect(controllerutil.SetControllerReference(user, secret, sch)).To(Succeed())
+
+	fakeClient := fake.NewClientBuilder().WithScheme(sch).WithObjects(secret).Build()
+	pkiManager := New(fakeClient, newMockCluster())
+
+	// A signing request is created for the renewal, the current certificate is kept until the new one is issued
+	userCert, err := pkiManager.ReconcileUserCertificate(ctx, user, sch, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(userCert.Certificate).To(Equal(current))
+
+	var requestList certsigningreqv1.CertificateSigningRequestList
+	g.Expect(fakeClient.List(ctx, &requestList)).To(Succeed())
+	g.Expect(requestList.Items).To(HaveLen(1))
+	signingReq := requestList.Items[0]
+	g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+	g.Expect(secret.Annotations).To(HaveKeyWithValue(RenewingCsrAnnotation, signingReq.GetName()))
+	g.Expect(secret.Data[corev1.TLSPrivateKeyKey]).To(Equal(key))
+
+	// The signing request is made for a new private key
+	renewalKey := secret.Data[renewalPrivateKeyKey]
+	g.Expect(renewalKey).NotTo(BeEmpty())
+	g.Expect(renewalKey).NotTo(Equal(key))
+	block, _ := pem.Decode(signingReq.Spec.Request)
+	certReq, err := x509.ParseCertificateRequest(block.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+	block, _ = pem.Decode(renewalKey)
+	privKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(certReq.PublicKey).To(Equal(&privKey.PublicKey))
+
+	// The signing request is not created again while it is pending
+	userCert, err = pkiManager.ReconcileUserCertificate(ctx, user, sch, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(userCert.Certificate).To(Equal(current))
+	g.Expect(fakeClient.List(ctx, &requestList)).To(Succeed())
+	g.Expect(requestList.Items).To(HaveLen(1))
+
+	// The issued certificate and its key replace the current ones
+	renewed, err := generateUserCertificate(renewalKey, now, now.Add(2*time.Hour))
+	g.Expect(err).NotTo(HaveOccurred())
+	signingReq.Status.Conditions = []certsigningreqv1.CertificateSigningRequestCondition{{Type: certsigningreqv1.CertificateApproved}}
+	signingReq.Status.Certificate = renewed
+	g.Expect(fakeClient.Status().Update(ctx, &signingReq)).To(Succeed())
+
+	userCert, err = pkiManager.ReconcileUserCertificate(ctx, user, sch, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(userCert.Certificate).To(Equal(renewed))
+	g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+	g.Expect(secret.Data[corev1.TLSCertKey]).To(Equal(renewed))
+	g.Expect(secret.Data[corev1.TLSPrivateKeyKey]).To(Equal(renewalKey))
+	g.Expect(secret.Data).NotTo(HaveKey(renewalPrivateKeyKey))
+	g.Expect(secret.Annotations).NotTo(HaveKey(RenewingCsrAnnotation))
+	g.Expect(secret.Annotations).To(HaveKeyWithValue(DependingCsrAnnotation, signingReq.GetName()))
+
+	// A denied renewal fails and its signing request is deleted, the next attempt creates a new one
+	secret.Data[corev1.TLSCertKey] = current
+	secret.Data[corev1.TLSPrivateKeyKey] = key
+	secret.Data[renewalPrivateKeyKey] = renewalKey
+	secret.Annotations[RenewingCsrAnnotation] = signingReq.GetName()
+	g.Expect(fakeClient.Update(ctx, secret)).To(Succeed())
+	signingReq.Status.Conditions = []certsigningreqv1.CertificateSigningRequestCondition{{Type: certsigningreqv1.CertificateDenied}}
+	g.Expect(fakeClient.Status().Update(ctx, &signingReq)).To(Succeed())
+
+	userCert, err = pkiManager.ReconcileUserCertificate(ctx, user, sch, "")
+	g.Expect(errors.As(err, &errorfactory.CertificateRenewalFailed{})).To(BeTrue())
+	g.Expect(userCert).NotTo(BeNil())
+	g.Expect(userCert.Certificate).To(Equal(current))
+	g.Expect(userCert.RenewalFailureTime.IsZero()).To(BeFalse())
+	g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+	g.Expect(secret.Annotations).NotTo(HaveKey(RenewingCsrAnnotation))
+	g.Expect(secret.Data).NotTo(HaveKey(renewalPrivateKeyKey))
+	g.Expect(secret.Data[corev1.TLSPrivateKeyKey]).To(Equal(key))
+	g.Expect(fakeClient.List(ctx, &requestList)).To(Succeed())
+	g.Expect(requestList.Items).To(BeEmpty())
+
+	// A user being deleted keeps its current certificate
+	deletionTime := metav1.Now()
+	user.SetDeletionTimestamp(&deletionTime)
+	userCert, err = pkiManager.ReconcileUserCertificate(ctx, user, sch, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(userCert.Certificate).To(Equal(current))
+	g.Expect(fakeClient.List(ctx, &requestList)).To(Succeed())
+	g.Expect(requestList.Items).To(BeEmpty())
+}
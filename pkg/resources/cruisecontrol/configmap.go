@@ -67,10 +67,17 @@ func (r *Reconciler) configMap(clientPass string, capacityConfig string, log log
 		log.Error(err, fmt.Sprintf("setting '%s' in Cruise Control configuration failed", kafkautils.KafkaConfigBoostrapServers), "config", bootstrapServers)
 	}
 
-	// Add Zookeeper configuration
-	zkConnect := zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())
-	if err = ccConfig.Set(kafkautils.KafkaConfigZooKeeperConnect, zkConnect); err != nil {
-		log.Error(err, fmt.Sprintf("setting '%s' in Cruise Control configuration failed", kafkautils.KafkaConfigZooKeeperConnect), "config", zkConnect)
+	if r.KafkaCluster.IsKRaftMode() {
+		// Without Zookeeper Cruise Control has to detect broker failures through the Kafka admin API
+		if err = ccConfig.Set(kafkautils.CruiseControlConfigKafkaBrokerFailureDetection, true); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in Cruise Control configuration failed", kafkautils.CruiseControlConfigKafkaBrokerFailureDetection))
+		}
+	} else {
+		// Add Zookeeper configuration
+		zkConnect := zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())
+		if err = ccConfig.Set(kafkautils.KafkaConfigZooKeeperConnect, zkConnect); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in Cruise Control configuration failed", kafkautils.KafkaConfigZooKeeperConnect), "config", zkConnect)
+		}
 	}
 
 	// Add SSL configuration
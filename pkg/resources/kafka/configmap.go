@@ -39,31 +39,102 @@ func (r *Reconciler) getConfigProperties(bConfig *v1beta1.BrokerConfig, id int32
 	serverPasses map[string]string, clientPass string, superUsers []string, log logr.Logger) *properties.Properties {
 	config := properties.NewProperties()
 
+	kRaft, zkMigration := getNodeMetadataMode(r.KafkaCluster, bConfig)
+
 	// Add listener configuration
-	listenerConf := generateListenerSpecificConfig(&r.KafkaCluster.Spec, serverPasses, log)
+	listenerConf := generateListenerSpecificConfig(&r.KafkaCluster.Spec, bConfig, kRaft, zkMigration, serverPasses, log)
 	config.Merge(listenerConf)
 
 	// Add listener configuration
-	advertisedListenerConf := generateAdvertisedListenerConfig(id, r.KafkaCluster.Spec.ListenersConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses)
-	if len(advertisedListenerConf) > 0 {
+	// KRaft controller listeners must not be advertised, the controller quorum is reached through controller.quorum.voters
+	advertisedControllerListenerStatuses := controllerIntListenerStatuses
+	if kRaft || zkMigration {
+		advertisedControllerListenerStatuses = nil
+	}
+	advertisedListenerConf := generateAdvertisedListenerConfig(id, r.KafkaCluster.Spec.ListenersConfig, extListenerStatuses, intListenerStatuses, advertisedControllerListenerStatuses)
+	// Dedicated controllers do not serve clients hence they have nothing to advertise
+	if len(advertisedListenerConf) > 0 && bConfig.IsBrokerNode() {
 		if err := config.Set(kafkautils.KafkaConfigAdvertisedListeners, advertisedListenerConf); err != nil {
 			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigAdvertisedListeners))
 		}
 	}
 
-	// Add control plane listener
-	cclConf := generateControlPlaneListener(r.KafkaCluster.Spec.ListenersConfig.InternalListeners)
-	if cclConf != "" {
-		if err := config.Set(kafkautils.KafkaConfigControlPlaneListener, cclConf); err != nil {
-			log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", kafkautils.KafkaConfigControlPlaneListener))
+	// Add KRaft configuration
+	if kRaft {
+		config.Merge(generateKRaftNodeConfig(id, bConfig, log))
+	}
+	// ZooKeeper based brokers have to reach the KRaft controllers during the migration
+	if kRaft || zkMigration {
+		config.Merge(generateKRaftQuorumConfig(r.KafkaCluster, controllerIntListenerStatuses, log))
+	} else {
+		// Add control plane listener
+		cclConf := generateControlPlaneListener(r.KafkaCluster.Spec.ListenersConfig.InternalListeners)
+		if cclConf != "" {
+			if err := config.Set(kafkautils.KafkaConfigControlPlaneListener, cclConf); err != nil {
+				log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", kafkautils.KafkaConfigControlPlaneListener))
+			}
 		}
 	}
 
 	// Add Zookeeper configuration
-	if err := config.Set(kafkautils.KafkaConfigZooKeeperConnect, zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())); err != nil {
-		log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", kafkautils.KafkaConfigZooKeeperConnect))
+	if !kRaft || zkMigration {
+		if err := config.Set(kafkautils.KafkaConfigZooKeeperConnect, zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", kafkautils.KafkaConfigZooKeeperConnect))
+		}
+	}
+	if zkMigration {
+		if err := config.Set(kafkautils.KafkaConfigZooKeeperMigration, true); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", kafkautils.KafkaConfigZooKeeperMigration))
+		}
+	}
+
+	// Dedicated controllers do not host partitions, the Cruise Control Metrics Reporter is not needed on them
+	if bConfig.IsBrokerNode() {
+		config.Merge(r.generateCruiseControlMetricsReporterConfig(id, clientPass, log))
 	}
 
+	// Kafka Broker configuration
+	// In KRaft mode the node.id is set instead of broker.id
+	if !kRaft {
+		if err := config.Set(kafkautils.KafkaConfigBrokerId, id); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigBrokerId))
+		}
+	}
+
+	// Pin the protocol version so that the brokers keep talking the old protocol while the new Kafka version is rolled out,
+	// KRaft nodes use the metadata.version feature instead
+	if !kRaft && r.KafkaCluster.Status.ProtocolVersion != "" {
+		config.Merge(generateProtocolVersionConfig(r.KafkaCluster.Status.ProtocolVersion, log))
+	}
+
+	// Storage configuration
+	storageConf := generateStorageConfig(getNodeStorageConfigs(bConfig))
+	if len(storageConf) > 0 {
+		if err := config.Set(kafkautils.KafkaConfigBrokerLogDirectory, storageConf); err != nil {
+			log.Error(err, "setting log.dirs in broker configuration resulted an error")
+		}
+	}
+	// The metadata log of dedicated controllers has to survive their restarts
+	if metadataStorage := getMetadataStorageConfig(bConfig); metadataStorage != nil {
+		if err := config.Set(kafkautils.KafkaConfigMetadataLogDirectory, util.StorageConfigKafkaMountPath(metadataStorage.MountPath)); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigMetadataLogDirectory))
+		}
+	}
+
+	// Add superuser configuration
+	su := strings.Join(generateSuperUsers(superUsers), ";")
+	if su != "" {
+		if err := config.Set(kafkautils.KafkaConfigSuperUsers, su); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigSuperUsers))
+		}
+	}
+	return config
+}
+
+// generateCruiseControlMetricsReporterConfig generates the configuration of the Cruise Control Metrics Reporter
+func (r *Reconciler) generateCruiseControlMetricsReporterConfig(id int32, clientPass string, log logr.Logger) *properties.Properties {
+	config := properties.NewProperties()
+
 	// Add Cruise Control Metrics Reporter SSL configuration
 	if util.IsSSLEnabledForInternalCommunication(r.KafkaCluster.Spec.ListenersConfig.InternalListeners) {
 		if !r.KafkaCluster.Spec.IsClientSSLSecretPresent() {
@@ -105,27 +176,6 @@ func (r *Reconciler) getConfigProperties(bConfig *v1beta1.BrokerConfig, id int32
 	if err := config.Set(kafkautils.CruiseControlConfigMetricsReporterK8sMode, true); err != nil {
 		log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.CruiseControlConfigMetricsReporterK8sMode))
 	}
-
-	// Kafka Broker configuration
-	if err := config.Set(kafkautils.KafkaConfigBrokerId, id); err != nil {
-		log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigBrokerId))
-	}
-
-	// Storage configuration
-	storageConf := generateStorageConfig(bConfig.StorageConfigs)
-	if len(storageConf) > 0 {
-		if err := config.Set(kafkautils.KafkaConfigBrokerLogDirectory, storageConf); err != nil {
-			log.Error(err, "setting log.dirs in broker configuration resulted an error")
-		}
-	}
-
-	// Add superuser configuration
-	su := strings.Join(generateSuperUsers(superUsers), ";")
-	if su != "" {
-		if err := config.Set(kafkautils.KafkaConfigSuperUsers, su); err != nil {
-			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigSuperUsers))
-		}
-	}
 	return config
 }
 
@@ -228,6 +278,39 @@ func getMountPathsFromBrokerConfigMap(configMap *v1.ConfigMap) ([]string, error)
 	return strings.Split(brokerLogDirProperty.Value(), ","), nil
 }
 
+// getNodeStorageConfigs returns the storage configs that are used by the node.
+// Dedicated controllers get no persistent data volumes, only the persistent volume of their metadata log,
+// or their emptyDir storages if they have no persistent storage.
+func getNodeStorageConfigs(bConfig *v1beta1.BrokerConfig) []v1beta1.StorageConfig {
+	if !bConfig.IsControllerOnlyNode() {
+		return bConfig.StorageConfigs
+	}
+	if metadataStorage := getMetadataStorageConfig(bConfig); metadataStorage != nil {
+		return []v1beta1.StorageConfig{*metadataStorage}
+	}
+	var storageConfigs []v1beta1.StorageConfig
+	for _, storage := range bConfig.StorageConfigs {
+		if storage.PvcSpec == nil && storage.EmptyDir != nil {
+			storageConfigs = append(storageConfigs, storage)
+		}
+	}
+	return storageConfigs
+}
+
+// getMetadataStorageConfig returns the storage config holding the metadata.log.dir of a dedicated controller,
+// which is its first persistent storage, or nil for the other nodes
+func getMetadataStorageConfig(bConfig *v1beta1.BrokerConfig) *v1beta1.StorageConfig {
+	if !bConfig.IsControllerOnlyNode() {
+		return nil
+	}
+	for i := range bConfig.StorageConfigs {
+		if bConfig.StorageConfigs[i].PvcSpec != nil {
+			return &bConfig.StorageConfigs[i]
+		}
+	}
+	return nil
+}
+
 func generateStorageConfig(sConfig []v1beta1.StorageConfig) []string {
 	mountPaths := make([]string, 0, len(sConfig))
 	for _, storage := range sConfig {
@@ -248,7 +331,135 @@ func generateControlPlaneListener(iListeners []v1beta1.InternalListenerConfig) s
 	return controlPlaneListener
 }
 
-func generateListenerSpecificConfig(kcs *v1beta1.KafkaClusterSpec, serverPasses map[string]string, log logr.Logger) *properties.Properties {
+// getNodeMetadataMode returns whether the node runs in KRaft mode and whether it takes part
+// in the migration of the cluster metadata from ZooKeeper to KRaft
+func getNodeMetadataMode(kafkaCluster *v1beta1.KafkaCluster, bConfig *v1beta1.BrokerConfig) (kRaft bool, zkMigration bool) {
+	if kafkaCluster.IsKRaftMode() {
+		return true, false
+	}
+	if !kafkaCluster.Spec.MigrateToKRaft {
+		return false, false
+	}
+
+	state := kafkaCluster.Status.KRaftMigrationState
+	// The KRaft controllers keep copying the metadata to ZooKeeper until the migration gets finalized
+	if bConfig.IsControllerOnlyNode() {
+		return true, state != v1beta1.KafkaClusterKRaftMigrationFinalizing
+	}
+	switch state {
+	case v1beta1.KafkaClusterKRaftMigrationEnablingBrokers:
+		return false, true
+	case v1beta1.KafkaClusterKRaftMigrationMigratingBrokers, v1beta1.KafkaClusterKRaftMigrationFinalizing:
+		return true, false
+	default:
+		return false, false
+	}
+}
+
+// generateKRaftNodeConfig generates the configuration which identifies the node with the given id in KRaft mode
+func generateKRaftNodeConfig(id int32, bConfig *v1beta1.BrokerConfig, log logr.Logger) *properties.Properties {
+	config := properties.NewProperties()
+
+	if err := config.Set(kafkautils.KafkaConfigNodeId, id); err != nil {
+		log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigNodeId))
+	}
+
+	if err := config.Set(kafkautils.KafkaConfigProcessRoles, generateProcessRoles(bConfig)); err != nil {
+		log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigProcessRoles))
+	}
+
+	return config
+}
+
+// generateKRaftQuorumConfig generates the configuration needed to reach the KRaft controller quorum
+func generateKRaftQuorumConfig(kafkaCluster *v1beta1.KafkaCluster,
+	controllerIntListenerStatuses map[string]v1beta1.ListenerStatusList, log logr.Logger) *properties.Properties {
+	config := properties.NewProperties()
+
+	controllerListenerName := generateControlPlaneListener(kafkaCluster.Spec.ListenersConfig.InternalListeners)
+	if controllerListenerName == "" {
+		log.Error(errors.New("no internal listener is marked with usedForControllerCommunication"), "KRaft controller listener is missing")
+		return config
+	}
+	if err := config.Set(kafkautils.KafkaConfigControllerListenerName, controllerListenerName); err != nil {
+		log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigControllerListenerName))
+	}
+
+	quorumVoters, err := generateQuorumVoters(kafkaCluster.Spec, controllerIntListenerStatuses)
+	if err != nil {
+		log.Error(err, "could not determine the members of the KRaft controller quorum")
+	}
+	if len(quorumVoters) > 0 {
+		if err := config.Set(kafkautils.KafkaConfigControllerQuorumVoters, quorumVoters); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigControllerQuorumVoters))
+		}
+	}
+
+	return config
+}
+
+// generateProtocolVersionConfig generates the inter broker protocol and log message format version configuration
+func generateProtocolVersionConfig(protocolVersion string, log logr.Logger) *properties.Properties {
+	config := properties.NewProperties()
+
+	if err := config.Set(kafkautils.KafkaConfigInterBrokerProtocolVersion, protocolVersion); err != nil {
+		log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigInterBrokerProtocolVersion))
+	}
+
+	// log.message.format.version is ignored from Kafka 3.0 when the inter broker protocol is 3.0 or higher
+	if cmp, err := kafkautils.CompareProtocolVersions(protocolVersion, "3.0"); err == nil && cmp < 0 {
+		if err := config.Set(kafkautils.KafkaConfigLogMessageFormatVersion, protocolVersion); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigLogMessageFormatVersion))
+		}
+	}
+
+	return config
+}
+
+// generateProcessRoles returns the value of the process.roles config based on the role of the node
+func generateProcessRoles(bConfig *v1beta1.BrokerConfig) []string {
+	var processRoles []string
+	if bConfig.IsBrokerNode() {
+		processRoles = append(processRoles, kafkautils.ProcessRoleBroker)
+	}
+	if bConfig.IsControllerNode() {
+		processRoles = append(processRoles, kafkautils.ProcessRoleController)
+	}
+	return processRoles
+}
+
+// generateQuorumVoters returns the members of the KRaft controller quorum in the {id}@{host}:{port} format
+// using the addresses of the controller listener
+func generateQuorumVoters(kafkaClusterSpec v1beta1.KafkaClusterSpec, controllerIntListenerStatuses map[string]v1beta1.ListenerStatusList) ([]string, error) {
+	// The order of the voters must not depend on the order of the brokers in the spec
+	controllerIds := make([]int, 0, len(kafkaClusterSpec.Brokers))
+	for _, broker := range kafkaClusterSpec.Brokers {
+		brokerConfig, err := broker.GetBrokerConfig(kafkaClusterSpec)
+		if err != nil {
+			return nil, err
+		}
+		if brokerConfig.IsControllerNode() {
+			controllerIds = append(controllerIds, int(broker.Id))
+		}
+	}
+	sort.Ints(controllerIds)
+
+	quorumVoters := make([]string, 0, len(controllerIds))
+	for _, controllerId := range controllerIds {
+		for _, statuses := range controllerIntListenerStatuses {
+			for _, status := range statuses {
+				if status.Name == fmt.Sprintf("broker-%d", controllerId) {
+					quorumVoters = append(quorumVoters, fmt.Sprintf("%d@%s", controllerId, status.Address))
+					break
+				}
+			}
+		}
+	}
+	return quorumVoters, nil
+}
+
+func generateListenerSpecificConfig(kcs *v1beta1.KafkaClusterSpec, bConfig *v1beta1.BrokerConfig, kRaft, zkMigration bool,
+	serverPasses map[string]string, log logr.Logger) *properties.Properties {
 	var (
 		interBrokerListenerName   string
 		securityProtocolMapConfig []string
@@ -259,6 +470,10 @@ func generateListenerSpecificConfig(kcs *v1beta1.KafkaClusterSpec, serverPasses
 
 	config := properties.NewProperties()
 
+	// In KRaft mode and during the migration to KRaft dedicated controllers listen only on the controller listener,
+	// while dedicated brokers must not listen on it
+	filterByRole := kRaft || zkMigration
+
 	for _, eListener := range l.ExternalListeners {
 		if eListener.UsedForInnerBrokerCommunication {
 			if interBrokerListenerName == "" {
@@ -267,6 +482,9 @@ func generateListenerSpecificConfig(kcs *v1beta1.KafkaClusterSpec, serverPasses
 				log.Error(errors.New("inter broker listener name already set"), "config error")
 			}
 		}
+		if filterByRole && !bConfig.IsBrokerNode() {
+			continue
+		}
 		upperedListenerType := eListener.Type.ToUpperString()
 		upperedListenerName := strings.ToUpper(eListener.Name)
 		securityProtocolMapConfig = append(securityProtocolMapConfig, fmt.Sprintf("%s:%s", upperedListenerName, upperedListenerType))
@@ -278,6 +496,10 @@ func generateListenerSpecificConfig(kcs *v1beta1.KafkaClusterSpec, serverPasses
 	}
 
 	for _, iListener := range l.InternalListeners {
+		upperedListenerType := iListener.Type.ToUpperString()
+		upperedListenerName := strings.ToUpper(iListener.Name)
+		// Brokers still need to know the security protocol of the controller listener to reach the quorum
+		securityProtocolMapConfig = append(securityProtocolMapConfig, fmt.Sprintf("%s:%s", upperedListenerName, upperedListenerType))
 		if iListener.UsedForInnerBrokerCommunication {
 			if interBrokerListenerName == "" {
 				interBrokerListenerName = strings.ToUpper(iListener.Name)
@@ -285,9 +507,12 @@ func generateListenerSpecificConfig(kcs *v1beta1.KafkaClusterSpec, serverPasses
 				log.Error(errors.New("inter broker listener name already set"), "config error")
 			}
 		}
-		upperedListenerType := iListener.Type.ToUpperString()
-		upperedListenerName := strings.ToUpper(iListener.Name)
-		securityProtocolMapConfig = append(securityProtocolMapConfig, fmt.Sprintf("%s:%s", upperedListenerName, upperedListenerType))
+		if filterByRole {
+			if iListener.UsedForControllerCommunication && !bConfig.IsControllerNode() ||
+				!iListener.UsedForControllerCommunication && !bConfig.IsBrokerNode() {
+				continue
+			}
+		}
 		listenerConfig = append(listenerConfig, fmt.Sprintf("%s://:%d", upperedListenerName, iListener.ContainerPort))
 		// Add internal listeners SSL configuration
 		if iListener.Type == v1beta1.SecurityProtocolSSL {
@@ -298,7 +523,9 @@ func generateListenerSpecificConfig(kcs *v1beta1.KafkaClusterSpec, serverPasses
 	if err := config.Set(kafkautils.KafkaConfigListenerSecurityProtocolMap, securityProtocolMapConfig); err != nil {
 		log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", kafkautils.KafkaConfigListenerSecurityProtocolMap))
 	}
-	if !strings.Contains(r, kafkautils.KafkaConfigSecurityInterBrokerProtocol+"=") {
+	// Dedicated controllers do not communicate with the brokers through the inter broker listener,
+	// except during the migration when they have to send requests to the ZooKeeper based brokers
+	if !strings.Contains(r, kafkautils.KafkaConfigSecurityInterBrokerProtocol+"=") && (!kRaft || zkMigration || bConfig.IsBrokerNode()) {
 		if err := config.Set(kafkautils.KafkaConfigInterBrokerListenerName, interBrokerListenerName); err != nil {
 			log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", kafkautils.KafkaConfigInterBrokerListenerName))
 		}
@@ -396,6 +623,12 @@ func (r Reconciler) generateBrokerConfig(id int32, brokerConfig *v1beta1.BrokerC
 			//nolint:errcheck
 			opGenConf.Set(kafkautils.KafkaConfigSuperUsers, suMerged)
 		}
+		// The protocol versions set explicitly by the user are not managed by the operator
+		for _, key := range []string{kafkautils.KafkaConfigInterBrokerProtocolVersion, kafkautils.KafkaConfigLogMessageFormatVersion} {
+			if _, found := finalBrokerConfig.Get(key); found {
+				opGenConf.Delete(key)
+			}
+		}
 		finalBrokerConfig.Merge(opGenConf)
 	}
 
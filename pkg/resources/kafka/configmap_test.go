@@ -132,6 +132,11 @@ func TestGenerateBrokerConfig(t *testing.T) { //nolint funlen
 		readOnlyConfig            string
 		zkAddresses               []string
 		zkPath                    string
+		kRaftMode                 bool
+		migrateToKRaft            bool
+		kRaftMigrationState       v1beta1.ClusterState
+		protocolVersion           string
+		processRole               v1beta1.ProcessRole
 		kubernetesClusterDomain   string
 		clusterWideConfig         string
 		perBrokerReadOnlyConfig   string
@@ -625,6 +630,251 @@ listeners=INTERNAL://:9092
 zookeeper.connect=example.zk:2181/
 security.inter.broker.protocol=SASL_SSL`,
 		},
+		{
+			testName:                  "protocolVersionPinned",
+			readOnlyConfig:            ``,
+			zkAddresses:               []string{"example.zk:2181"},
+			protocolVersion:           "2.8",
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-0.kafka.svc.cluster.local:9092
+broker.id=0
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+inter.broker.protocol.version=2.8
+listener.security.protocol.map=INTERNAL:PLAINTEXT
+listeners=INTERNAL://:9092
+log.message.format.version=2.8
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+zookeeper.connect=example.zk:2181/`,
+		},
+		{
+			testName:                  "protocolVersionSetByUser",
+			readOnlyConfig:            `inter.broker.protocol.version=3.3`,
+			zkAddresses:               []string{"example.zk:2181"},
+			protocolVersion:           "3.4",
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-0.kafka.svc.cluster.local:9092
+broker.id=0
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+inter.broker.protocol.version=3.3
+listener.security.protocol.map=INTERNAL:PLAINTEXT
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+zookeeper.connect=example.zk:2181/`,
+		},
+		{
+			testName:                  "kRaftModeWithProtocolVersion",
+			readOnlyConfig:            ``,
+			kRaftMode:                 true,
+			protocolVersion:           "3.4",
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,1@kafka-1.kafka.svc.cluster.local:9093
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=INTERNAL://:9092,CONTROLLER://:9093
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+node.id=0
+process.roles=broker,controller`,
+		},
+		{
+			testName:                  "kRaftMode",
+			readOnlyConfig:            ``,
+			kRaftMode:                 true,
+			kubernetesClusterDomain:   ``,
+			clusterWideConfig:         ``,
+			perBrokerConfig:           ``,
+			perBrokerReadOnlyConfig:   ``,
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,1@kafka-1.kafka.svc.cluster.local:9093
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=INTERNAL://:9092,CONTROLLER://:9093
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+node.id=0
+process.roles=broker,controller`,
+		},
+		{
+			testName:                  "kRaftModeBrokerNode",
+			readOnlyConfig:            ``,
+			kRaftMode:                 true,
+			processRole:               v1beta1.ProcessRoleBroker,
+			kubernetesClusterDomain:   ``,
+			clusterWideConfig:         ``,
+			perBrokerConfig:           ``,
+			perBrokerReadOnlyConfig:   ``,
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `controller.listener.names=CONTROLLER
+controller.quorum.voters=1@kafka-1.kafka.svc.cluster.local:9093
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+node.id=0
+process.roles=broker`,
+		},
+		{
+			testName:                "kRaftModeControllerNode",
+			readOnlyConfig:          ``,
+			kRaftMode:               true,
+			processRole:             v1beta1.ProcessRoleController,
+			kubernetesClusterDomain: ``,
+			clusterWideConfig:       ``,
+			perBrokerConfig:         ``,
+			perBrokerReadOnlyConfig: ``,
+			perBrokerStorageConfig: []v1beta1.StorageConfig{
+				{
+					MountPath: "/kafka-logs",
+					PvcSpec:   &v1.PersistentVolumeClaimSpec{},
+				},
+				{
+					MountPath: "/kafka-metadata",
+					EmptyDir:  &v1.EmptyDirVolumeSource{},
+				},
+			},
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,1@kafka-1.kafka.svc.cluster.local:9093
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=CONTROLLER://:9093
+log.dirs=/kafka-logs/kafka
+metadata.log.dir=/kafka-logs/kafka
+node.id=0
+process.roles=controller`,
+		},
+		{
+			testName:                "kRaftModeControllerNodeWithoutPersistentStorage",
+			readOnlyConfig:          ``,
+			kRaftMode:               true,
+			processRole:             v1beta1.ProcessRoleController,
+			kubernetesClusterDomain: ``,
+			clusterWideConfig:       ``,
+			perBrokerConfig:         ``,
+			perBrokerReadOnlyConfig: ``,
+			perBrokerStorageConfig: []v1beta1.StorageConfig{
+				{
+					MountPath: "/kafka-metadata",
+					EmptyDir:  &v1.EmptyDirVolumeSource{},
+				},
+			},
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,1@kafka-1.kafka.svc.cluster.local:9093
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=CONTROLLER://:9093
+log.dirs=/kafka-metadata/kafka
+node.id=0
+process.roles=controller`,
+		},
+		{
+			testName:                  "kRaftMigrationEnablingBrokersBrokerNode",
+			readOnlyConfig:            ``,
+			zkAddresses:               []string{"example.zk:2181"},
+			migrateToKRaft:            true,
+			kRaftMigrationState:       v1beta1.KafkaClusterKRaftMigrationEnablingBrokers,
+			processRole:               v1beta1.ProcessRoleBroker,
+			kubernetesClusterDomain:   ``,
+			clusterWideConfig:         ``,
+			perBrokerConfig:           ``,
+			perBrokerReadOnlyConfig:   ``,
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `broker.id=0
+controller.listener.names=CONTROLLER
+controller.quorum.voters=1@kafka-1.kafka.svc.cluster.local:9093
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+zookeeper.connect=example.zk:2181/
+zookeeper.metadata.migration.enable=true`,
+		},
+		{
+			testName:                  "kRaftMigrationMigratingBrokersBrokerNode",
+			readOnlyConfig:            ``,
+			zkAddresses:               []string{"example.zk:2181"},
+			migrateToKRaft:            true,
+			kRaftMigrationState:       v1beta1.KafkaClusterKRaftMigrationMigratingBrokers,
+			processRole:               v1beta1.ProcessRoleBroker,
+			kubernetesClusterDomain:   ``,
+			clusterWideConfig:         ``,
+			perBrokerConfig:           ``,
+			perBrokerReadOnlyConfig:   ``,
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `controller.listener.names=CONTROLLER
+controller.quorum.voters=1@kafka-1.kafka.svc.cluster.local:9093
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+node.id=0
+process.roles=broker`,
+		},
+		{
+			testName:                  "kRaftMigrationDeployingControllersControllerNode",
+			readOnlyConfig:            ``,
+			zkAddresses:               []string{"example.zk:2181"},
+			migrateToKRaft:            true,
+			kRaftMigrationState:       v1beta1.KafkaClusterKRaftMigrationDeployingControllers,
+			processRole:               v1beta1.ProcessRoleController,
+			kubernetesClusterDomain:   ``,
+			clusterWideConfig:         ``,
+			perBrokerConfig:           ``,
+			perBrokerReadOnlyConfig:   ``,
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,1@kafka-1.kafka.svc.cluster.local:9093
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=CONTROLLER://:9093
+node.id=0
+process.roles=controller
+zookeeper.connect=example.zk:2181/
+zookeeper.metadata.migration.enable=true`,
+		},
+		{
+			testName:                  "kRaftMigrationFinalizingControllerNode",
+			readOnlyConfig:            ``,
+			zkAddresses:               []string{"example.zk:2181"},
+			migrateToKRaft:            true,
+			kRaftMigrationState:       v1beta1.KafkaClusterKRaftMigrationFinalizing,
+			processRole:               v1beta1.ProcessRoleController,
+			kubernetesClusterDomain:   ``,
+			clusterWideConfig:         ``,
+			perBrokerConfig:           ``,
+			perBrokerReadOnlyConfig:   ``,
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			expectedConfig: `controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,1@kafka-1.kafka.svc.cluster.local:9093
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=CONTROLLER://:9093
+node.id=0
+process.roles=controller`,
+		},
 	}
 
 	t.Parallel()
@@ -635,6 +885,69 @@ security.inter.broker.protocol=SASL_SSL`,
 
 		t.Run(test.testName, func(t *testing.T) {
 			mockClient := mocks.NewMockClient(mockCtrl)
+			internalListeners := []v1beta1.InternalListenerConfig{
+				{
+					CommonListenerSpec: v1beta1.CommonListenerSpec{
+						Type:          v1beta1.SecurityProtocol(test.listenerType),
+						Name:          "internal",
+						ContainerPort: 9092,
+						ServerSSLCertSecret: &v1.LocalObjectReference{
+							Name: "server-secret",
+						},
+						SSLClientAuth:                   test.sslClientAuth,
+						UsedForInnerBrokerCommunication: true,
+					},
+				},
+			}
+			controllerListenerStatus := map[string]v1beta1.ListenerStatusList{
+				"internal": {
+					{
+						Name:    "broker-0",
+						Address: test.advertisedListenerAddress,
+					},
+				},
+			}
+			if test.kRaftMode || test.migrateToKRaft {
+				internalListeners = append(internalListeners, v1beta1.InternalListenerConfig{
+					CommonListenerSpec: v1beta1.CommonListenerSpec{
+						Type:          v1beta1.SecurityProtocol(test.listenerType),
+						Name:          "controller",
+						ContainerPort: 9093,
+					},
+					UsedForControllerCommunication: true,
+				})
+				controllerListenerStatus = map[string]v1beta1.ListenerStatusList{
+					"controller": {
+						{
+							Name:    "broker-0",
+							Address: "kafka-0.kafka.svc.cluster.local:9093",
+						},
+						{
+							Name:    "broker-1",
+							Address: "kafka-1.kafka.svc.cluster.local:9093",
+						},
+					},
+				}
+			}
+			brokers := []v1beta1.Broker{{
+				Id:             0,
+				ReadOnlyConfig: test.perBrokerReadOnlyConfig,
+				BrokerConfig: &v1beta1.BrokerConfig{
+					Config:         test.perBrokerConfig,
+					StorageConfigs: test.perBrokerStorageConfig,
+					ProcessRole:    test.processRole,
+				},
+			},
+			}
+			if test.kRaftMode || test.migrateToKRaft {
+				// dedicated controller node to form the quorum with
+				brokers = append(brokers, v1beta1.Broker{
+					Id: 1,
+					BrokerConfig: &v1beta1.BrokerConfig{
+						ProcessRole: v1beta1.ProcessRoleController,
+					},
+				})
+			}
 			r := Reconciler{
 				Reconciler: resources.Reconciler{
 					Client: mockClient,
@@ -649,47 +962,24 @@ security.inter.broker.protocol=SASL_SSL`,
 							ClientSSLCertSecret: &v1.LocalObjectReference{
 								Name: "client-secret",
 							},
+							KRaftMode:      test.kRaftMode,
+							MigrateToKRaft: test.migrateToKRaft,
 							ListenersConfig: v1beta1.ListenersConfig{
-								InternalListeners: []v1beta1.InternalListenerConfig{
-									{
-										CommonListenerSpec: v1beta1.CommonListenerSpec{
-											Type:          v1beta1.SecurityProtocol(test.listenerType),
-											Name:          "internal",
-											ContainerPort: 9092,
-											ServerSSLCertSecret: &v1.LocalObjectReference{
-												Name: "server-secret",
-											},
-											SSLClientAuth:                   test.sslClientAuth,
-											UsedForInnerBrokerCommunication: true,
-										},
-									},
-								},
+								InternalListeners: internalListeners,
 							},
 							ReadOnlyConfig:          test.readOnlyConfig,
 							KubernetesClusterDomain: test.kubernetesClusterDomain,
 							ClusterWideConfig:       test.clusterWideConfig,
-							Brokers: []v1beta1.Broker{{
-								Id:             0,
-								ReadOnlyConfig: test.perBrokerReadOnlyConfig,
-								BrokerConfig: &v1beta1.BrokerConfig{
-									Config:         test.perBrokerConfig,
-									StorageConfigs: test.perBrokerStorageConfig,
-								},
-							},
-							},
+							Brokers:                 brokers,
+						},
+						Status: v1beta1.KafkaClusterStatus{
+							KRaftMigrationState: test.kRaftMigrationState,
+							ProtocolVersion:     test.protocolVersion,
 						},
 					},
 				},
 			}
 
-			controllerListenerStatus := map[string]v1beta1.ListenerStatusList{
-				"internal": {
-					{
-						Name:    "broker-0",
-						Address: test.advertisedListenerAddress,
-					},
-				},
-			}
 			var (
 				serverPasses map[string]string
 				clientPass   string
@@ -0,0 +1,266 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+const kafkaContainerName = "kafka"
+
+// getBrokerImage returns the image of the broker which is the image recorded for the broker by the image rollback
+// while the rollout of the failed image is paused, or the image set in the KafkaCluster spec otherwise
+func (r *Reconciler) getBrokerImage(brokerID int32, brokerConfig *v1beta1.BrokerConfig) string {
+	image := util.GetBrokerImage(brokerConfig, r.KafkaCluster.Spec.GetClusterImage())
+	rollback := r.KafkaCluster.Status.ImageRollback
+	if rollback != nil && image == rollback.FailedImage {
+		if rollbackImage := rollback.BrokerImages[strconv.Itoa(int(brokerID))]; rollbackImage != "" {
+			return rollbackImage
+		}
+	}
+	return image
+}
+
+// recordImageUpgrade records the image the broker ran before it is restarted with a new image,
+// so that the broker can be rolled back if it does not become ready with the new image in time
+func (r *Reconciler) recordImageUpgrade(desiredPod, currentPod *corev1.Pod, log logr.Logger) error {
+	if r.KafkaCluster.Spec.RollingUpgradeConfig.ImageRollbackDeadlineSeconds == 0 {
+		return nil
+	}
+	desiredImage := getKafkaContainerImage(desiredPod)
+	currentImage := getKafkaContainerImage(currentPod)
+	if desiredImage == "" || desiredImage == currentImage {
+		return nil
+	}
+
+	brokerID := currentPod.Labels[v1beta1.BrokerIdLabelKey]
+	// The broker is being rolled back, which must not be rolled back again
+	if rollback := r.KafkaCluster.Status.ImageRollback; rollback != nil && rollback.BrokerImages[brokerID] == desiredImage {
+		return nil
+	}
+
+	previousImage := r.KafkaCluster.Status.BrokersState[brokerID].Image
+	if previousImage == "" || previousImage == desiredImage {
+		previousImage = currentImage
+	}
+	state := v1beta1.ImageUpgradeState{
+		Phase:         v1beta1.ImageUpgradeInProgress,
+		PreviousImage: previousImage,
+		TargetImage:   desiredImage,
+		StartedAt:     time.Now().Format(time.RFC3339),
+	}
+	log.Info("upgrading broker image", v1beta1.BrokerIdLabelKey, brokerID, "from", previousImage, "to", desiredImage)
+	if err := k8sutil.UpdateBrokerStatus(r.Client, []string{brokerID}, r.KafkaCluster, state, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "recording image upgrade failed", "brokerId", brokerID)
+	}
+	return nil
+}
+
+// reconcileImageRollback checks whether the brokers restarted with a new image became ready within the deadline.
+// Otherwise the failed brokers, and optionally every broker already upgraded, are reverted to their previous image and
+// the rollout of the failed image is paused until the image is changed in the KafkaCluster spec.
+func (r *Reconciler) reconcileImageRollback(ctx context.Context, log logr.Logger) error {
+	// The rollout is resumed once the failed image is replaced in the spec
+	if rollback := r.KafkaCluster.Status.ImageRollback; rollback != nil && !r.isImageInSpec(rollback.FailedImage) {
+		log.Info("failed image is not used anymore, resuming rollout", "failedImage", rollback.FailedImage)
+		if err := k8sutil.UpdateImageRollbackStatus(r.Client, r.KafkaCluster, nil, log); err != nil {
+			return errorfactory.New(errorfactory.StatusUpdateError{}, err, "clearing image rollback status failed")
+		}
+	}
+
+	deadline := time.Duration(r.KafkaCluster.Spec.RollingUpgradeConfig.ImageRollbackDeadlineSeconds) * time.Second
+	if deadline == 0 {
+		return nil
+	}
+
+	podList := &corev1.PodList{}
+	matchingLabels := client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name))
+	err := r.Client.List(ctx, podList, client.ListOption(client.InNamespace(r.KafkaCluster.Namespace)), client.ListOption(matchingLabels))
+	if err != nil {
+		return errors.WrapIf(err, "failed to list kafka pods")
+	}
+	brokerPods := make(map[string]*corev1.Pod, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !k8sutil.IsMarkedForDeletion(pod.ObjectMeta) {
+			brokerPods[pod.Labels[v1beta1.BrokerIdLabelKey]] = pod
+		}
+	}
+
+	brokerIDs := make([]string, 0, len(r.KafkaCluster.Status.BrokersState))
+	for brokerID := range r.KafkaCluster.Status.BrokersState {
+		brokerIDs = append(brokerIDs, brokerID)
+	}
+	sort.Strings(brokerIDs)
+
+	var failedImage string
+	var failedBrokers []string
+	for _, brokerID := range brokerIDs {
+		state := r.KafkaCluster.Status.BrokersState[brokerID].ImageUpgradeState
+		if state == nil || state.Phase != v1beta1.ImageUpgradeInProgress {
+			continue
+		}
+		pod := brokerPods[brokerID]
+		if pod != nil && getKafkaContainerImage(pod) == state.TargetImage && isPodReady(pod) {
+			log.Info("broker became ready with the new image", v1beta1.BrokerIdLabelKey, brokerID, "image", state.TargetImage)
+			succeeded := *state
+			succeeded.Phase = v1beta1.ImageUpgradeSucceeded
+			if err := k8sutil.UpdateBrokerStatus(r.Client, []string{brokerID}, r.KafkaCluster, succeeded, log); err != nil {
+				return errorfactory.New(errorfactory.StatusUpdateError{}, err, "updating image upgrade state failed", "brokerId", brokerID)
+			}
+			continue
+		}
+		startedAt, err := time.Parse(time.RFC3339, state.StartedAt)
+		if err == nil && time.Since(startedAt) < deadline {
+			continue
+		}
+		// Only one failed image is rolled back at once
+		if failedImage == "" {
+			failedImage = state.TargetImage
+		}
+		if state.TargetImage == failedImage {
+			failedBrokers = append(failedBrokers, brokerID)
+		}
+	}
+	if len(failedBrokers) == 0 {
+		return nil
+	}
+	if rollback := r.KafkaCluster.Status.ImageRollback; rollback != nil && rollback.FailedImage != failedImage {
+		log.Info("an image rollback is already in progress, the brokers failing with another image are not rolled back",
+			"failedImage", failedImage, "brokers", failedBrokers, "rollbackFailedImage", rollback.FailedImage)
+		return nil
+	}
+
+	return r.rollbackImage(ctx, failedImage, failedBrokers, brokerPods, log)
+}
+
+func (r *Reconciler) rollbackImage(ctx context.Context, failedImage string, failedBrokers []string, brokerPods map[string]*corev1.Pod, log logr.Logger) error {
+	isFailedBroker := make(map[string]bool, len(failedBrokers))
+	for _, brokerID := range failedBrokers {
+		isFailedBroker[brokerID] = true
+	}
+
+	brokerImages := make(map[string]string)
+	rolledBackStates := make(map[string]v1beta1.ImageUpgradeState)
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
+		if err != nil {
+			return errors.WrapIf(err, "failed to determine broker config")
+		}
+		if util.GetBrokerImage(brokerConfig, r.KafkaCluster.Spec.GetClusterImage()) != failedImage {
+			continue
+		}
+		brokerID := strconv.Itoa(int(broker.Id))
+		brokerState := r.KafkaCluster.Status.BrokersState[brokerID]
+		upgradeState := brokerState.ImageUpgradeState
+		upgradedToFailedImage := upgradeState != nil && upgradeState.TargetImage == failedImage && upgradeState.PreviousImage != ""
+
+		switch {
+		case upgradedToFailedImage && (isFailedBroker[brokerID] || r.KafkaCluster.Spec.RollingUpgradeConfig.RollbackWholeRollout):
+			brokerImages[brokerID] = upgradeState.PreviousImage
+			rolledBack := *upgradeState
+			rolledBack.Phase = v1beta1.ImageUpgradeRolledBack
+			rolledBackStates[brokerID] = rolledBack
+		case !upgradedToFailedImage && brokerState.Image != "" && brokerState.Image != failedImage:
+			// The brokers not upgraded yet keep running their current image while the rollout is paused
+			brokerImages[brokerID] = brokerState.Image
+		}
+	}
+
+	reason := fmt.Sprintf("broker(s) %s did not become ready within %d seconds with image %s", strings.Join(failedBrokers, ","),
+		r.KafkaCluster.Spec.RollingUpgradeConfig.ImageRollbackDeadlineSeconds, failedImage)
+	log.Info("rolling back image upgrade", "reason", reason, "brokerImages", brokerImages)
+
+	if len(rolledBackStates) > 0 {
+		brokerIDs := make([]string, 0, len(rolledBackStates))
+		for brokerID := range rolledBackStates {
+			brokerIDs = append(brokerIDs, brokerID)
+		}
+		if err := k8sutil.UpdateBrokerStatus(r.Client, brokerIDs, r.KafkaCluster, rolledBackStates, log); err != nil {
+			return errorfactory.New(errorfactory.StatusUpdateError{}, err, "updating image upgrade state failed")
+		}
+	}
+	rollback := &v1beta1.ImageRollbackStatus{
+		FailedImage:  failedImage,
+		BrokerImages: brokerImages,
+		Reason:       reason,
+		RolledBackAt: time.Now().Format(time.RFC3339),
+	}
+	if err := k8sutil.UpdateImageRollbackStatus(r.Client, r.KafkaCluster, rollback, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting image rollback status failed")
+	}
+
+	// The failed brokers may not even be running, which would block the rolling upgrade from replacing them
+	for _, brokerID := range failedBrokers {
+		pod, ok := brokerPods[brokerID]
+		if !ok || brokerImages[brokerID] == "" {
+			continue
+		}
+		if err := r.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return errorfactory.New(errorfactory.APIFailure{}, err, "deleting failed broker pod failed", "brokerId", brokerID)
+		}
+		log.Info("failed broker pod deleted to roll back its image", v1beta1.BrokerIdLabelKey, brokerID, "image", brokerImages[brokerID])
+	}
+	return nil
+}
+
+// isImageInSpec returns true if any of the brokers is set to run the given image in the KafkaCluster spec
+func (r *Reconciler) isImageInSpec(image string) bool {
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
+		if err != nil {
+			continue
+		}
+		if util.GetBrokerImage(brokerConfig, r.KafkaCluster.Spec.GetClusterImage()) == image {
+			return true
+		}
+	}
+	return false
+}
+
+func getKafkaContainerImage(pod *corev1.Pod) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == kafkaContainerName {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
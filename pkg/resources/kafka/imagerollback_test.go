@@ -0,0 +1,204 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka/mocks"
+)
+
+func TestReconcileImageRollback(t *testing.T) {
+	t.Parallel()
+	const (
+		oldImage = "ghcr.io/banzaicloud/kafka:2.13-3.3.2"
+		newImage = "ghcr.io/banzaicloud/kafka:2.13-3.4.1"
+	)
+	kafkaPod := func(brokerID, image string, ready bool) corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafka-" + brokerID, Labels: map[string]string{"brokerId": brokerID}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "kafka", Image: image}}},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+		}
+	}
+	upgradeState := func(phase v1beta1.ImageUpgradePhase, startedAt time.Time) *v1beta1.ImageUpgradeState {
+		return &v1beta1.ImageUpgradeState{
+			Phase:         phase,
+			PreviousImage: oldImage,
+			TargetImage:   newImage,
+			StartedAt:     startedAt.Format(time.RFC3339),
+		}
+	}
+
+	testCases := []struct {
+		testName              string
+		clusterImage          string
+		rollbackWholeRollout  bool
+		brokersState          map[string]v1beta1.BrokerState
+		imageRollback         *v1beta1.ImageRollbackStatus
+		pods                  []corev1.Pod
+		expectedDeletedPods   int
+		expectedPhases        map[string]v1beta1.ImageUpgradePhase
+		expectedImageRollback *v1beta1.ImageRollbackStatus
+	}{
+		{
+			testName:     "broker ready with the new image",
+			clusterImage: newImage,
+			brokersState: map[string]v1beta1.BrokerState{
+				"0": {Image: newImage, ImageUpgradeState: upgradeState(v1beta1.ImageUpgradeInProgress, time.Now().Add(-time.Hour))},
+			},
+			pods:           []corev1.Pod{kafkaPod("0", newImage, true)},
+			expectedPhases: map[string]v1beta1.ImageUpgradePhase{"0": v1beta1.ImageUpgradeSucceeded},
+		},
+		{
+			testName:     "broker not ready before the deadline",
+			clusterImage: newImage,
+			brokersState: map[string]v1beta1.BrokerState{
+				"0": {Image: newImage, ImageUpgradeState: upgradeState(v1beta1.ImageUpgradeInProgress, time.Now())},
+			},
+			pods:           []corev1.Pod{kafkaPod("0", newImage, false)},
+			expectedPhases: map[string]v1beta1.ImageUpgradePhase{"0": v1beta1.ImageUpgradeInProgress},
+		},
+		{
+			testName:     "failed broker is rolled back and the rollout is paused",
+			clusterImage: newImage,
+			brokersState: map[string]v1beta1.BrokerState{
+				"0": {Image: newImage, ImageUpgradeState: upgradeState(v1beta1.ImageUpgradeSucceeded, time.Now().Add(-time.Hour))},
+				"1": {Image: oldImage, ImageUpgradeState: upgradeState(v1beta1.ImageUpgradeInProgress, time.Now().Add(-time.Hour))},
+				"2": {Image: oldImage},
+			},
+			pods:                []corev1.Pod{kafkaPod("0", newImage, true), kafkaPod("1", newImage, false), kafkaPod("2", oldImage, true)},
+			expectedDeletedPods: 1,
+			expectedPhases: map[string]v1beta1.ImageUpgradePhase{
+				"0": v1beta1.ImageUpgradeSucceeded,
+				"1": v1beta1.ImageUpgradeRolledBack,
+			},
+			expectedImageRollback: &v1beta1.ImageRollbackStatus{
+				FailedImage:  newImage,
+				BrokerImages: map[string]string{"1": oldImage, "2": oldImage},
+				Reason:       "broker(s) 1 did not become ready within 300 seconds with image " + newImage,
+			},
+		},
+		{
+			testName:             "whole rollout is rolled back",
+			clusterImage:         newImage,
+			rollbackWholeRollout: true,
+			brokersState: map[string]v1beta1.BrokerState{
+				"0": {Image: newImage, ImageUpgradeState: upgradeState(v1beta1.ImageUpgradeSucceeded, time.Now().Add(-time.Hour))},
+				"1": {Image: oldImage, ImageUpgradeState: upgradeState(v1beta1.ImageUpgradeInProgress, time.Now().Add(-time.Hour))},
+			},
+			pods:                []corev1.Pod{kafkaPod("0", newImage, true), kafkaPod("1", newImage, false)},
+			expectedDeletedPods: 1,
+			expectedPhases: map[string]v1beta1.ImageUpgradePhase{
+				"0": v1beta1.ImageUpgradeRolledBack,
+				"1": v1beta1.ImageUpgradeRolledBack,
+			},
+			expectedImageRollback: &v1beta1.ImageRollbackStatus{
+				FailedImage:  newImage,
+				BrokerImages: map[string]string{"0": oldImage, "1": oldImage},
+				Reason:       "broker(s) 1 did not become ready within 300 seconds with image " + newImage,
+			},
+		},
+		{
+			testName:     "rollout is resumed once the failed image is replaced",
+			clusterImage: oldImage,
+			brokersState: map[string]v1beta1.BrokerState{
+				"0": {Image: oldImage, ImageUpgradeState: upgradeState(v1beta1.ImageUpgradeRolledBack, time.Now().Add(-time.Hour))},
+			},
+			imageRollback: &v1beta1.ImageRollbackStatus{
+				FailedImage:  newImage,
+				BrokerImages: map[string]string{"0": oldImage},
+			},
+			pods:           []corev1.Pod{kafkaPod("0", oldImage, true)},
+			expectedPhases: map[string]v1beta1.ImageUpgradePhase{"0": v1beta1.ImageUpgradeRolledBack},
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(mockCtrl)
+			mockSubResourceClient := mocks.NewMockSubResourceClient(mockCtrl)
+			brokers := make([]v1beta1.Broker, 0, len(test.brokersState))
+			for _, id := range []int32{0, 1, 2} {
+				if _, ok := test.brokersState[strconv.Itoa(int(id))]; ok {
+					brokers = append(brokers, v1beta1.Broker{Id: id, BrokerConfig: &v1beta1.BrokerConfig{}})
+				}
+			}
+			kafkaCluster := &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+				Spec: v1beta1.KafkaClusterSpec{
+					ClusterImage: test.clusterImage,
+					Brokers:      brokers,
+					RollingUpgradeConfig: v1beta1.RollingUpgradeConfig{
+						ImageRollbackDeadlineSeconds: 300,
+						RollbackWholeRollout:         test.rollbackWholeRollout,
+					},
+				},
+				Status: v1beta1.KafkaClusterStatus{
+					BrokersState:  test.brokersState,
+					ImageRollback: test.imageRollback,
+				},
+			}
+			r := New(mockClient, nil, kafkaCluster, nil)
+
+			mockClient.EXPECT().List(
+				context.Background(),
+				gomock.AssignableToTypeOf(&corev1.PodList{}),
+				client.InNamespace("kafka"),
+				gomock.Any(),
+			).Do(func(ctx context.Context, list *corev1.PodList, opts ...client.ListOption) {
+				list.Items = test.pods
+			}).Return(nil).AnyTimes()
+			mockClient.EXPECT().Status().Return(mockSubResourceClient).AnyTimes()
+			mockSubResourceClient.EXPECT().Update(context.Background(), gomock.AssignableToTypeOf(&v1beta1.KafkaCluster{})).Return(nil).AnyTimes()
+			mockClient.EXPECT().Delete(context.Background(), gomock.AssignableToTypeOf(&corev1.Pod{})).Return(nil).Times(test.expectedDeletedPods)
+
+			err := r.reconcileImageRollback(context.Background(), logr.Discard())
+
+			assert.Nil(t, err)
+			for brokerID, phase := range test.expectedPhases {
+				assert.Equal(t, phase, r.KafkaCluster.Status.BrokersState[brokerID].ImageUpgradeState.Phase, "broker %s", brokerID)
+			}
+			imageRollback := r.KafkaCluster.Status.ImageRollback
+			if test.expectedImageRollback == nil {
+				assert.Nil(t, imageRollback)
+				return
+			}
+			if assert.NotNil(t, imageRollback) {
+				assert.Equal(t, test.expectedImageRollback.FailedImage, imageRollback.FailedImage)
+				assert.Equal(t, test.expectedImageRollback.BrokerImages, imageRollback.BrokerImages)
+				assert.Equal(t, test.expectedImageRollback.Reason, imageRollback.Reason)
+				assert.Equal(t, oldImage, r.getBrokerImage(1, &v1beta1.BrokerConfig{}))
+			}
+		})
+	}
+}
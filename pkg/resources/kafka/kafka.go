@@ -245,6 +245,22 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		return errors.WrapIf(err, "failed to reconcile resource")
 	}
 
+	// Start the migration before any of the resources are generated for the KRaft controllers
+	if err := r.startKRaftMigration(ctx, log); err != nil {
+		return err
+	}
+
+	// The KRaft cluster id must be known before the storage of the brokers gets formatted
+	if r.KafkaCluster.Spec.IsKRaftMode() && r.KafkaCluster.Status.ClusterID == "" {
+		clusterID, err := kafka.GenerateClusterID()
+		if err != nil {
+			return errors.WrapIf(err, "could not generate cluster id")
+		}
+		if err := k8sutil.UpdateClusterID(ctx, r.Client, r.KafkaCluster, clusterID); err != nil {
+			return errors.WrapIf(err, "could not update cluster id")
+		}
+	}
+
 	extListenerStatuses, err := r.createExternalListenerStatuses(log)
 	if err != nil {
 		return errors.WrapIf(err, "could not update status for external listeners")
@@ -287,6 +303,10 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 			if storage.PvcSpec == nil {
 				continue
 			}
+			// Dedicated controllers get no persistent data volumes, only the one of their metadata log
+			if brokerConfig.IsControllerOnlyNode() && getMetadataStorageConfig(brokerConfig).MountPath != storage.MountPath {
+				continue
+			}
 			o, err := r.pvc(broker.Id, index, storage)
 			if err != nil {
 				return errors.WrapIfWithDetails(err, "failed to generate resource", "resources", "PersistentVolumeClaim")
@@ -336,7 +356,30 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
-	reorderedBrokers := reorderBrokers(runningBrokers, boundPersistentVolumeClaims, r.KafkaCluster.Spec.Brokers, r.KafkaCluster.Status.BrokersState, controllerID, log)
+	// The demoted brokers have to become the preferred replicas again before the leadership can be moved back to them
+	if err = r.reconcileDemotedReplicas(ctx, log); err != nil {
+		return err
+	}
+
+	// The leadership has to be moved back to the restarted brokers before the next broker is restarted
+	if err = r.reconcilePreferredLeaderElection(ctx, log); err != nil {
+		return err
+	}
+
+	if err = r.reconcileImageRollback(ctx, log); err != nil {
+		return err
+	}
+
+	// The protocol version has to be pinned before the config of the brokers is generated for a new image
+	if err = r.pinProtocolVersion(brokerPods.Items, log); err != nil {
+		return err
+	}
+
+	var brokerRacks map[int32]string
+	if r.KafkaCluster.Spec.RollingUpgradeConfig.Strategy == v1beta1.RackByRackRollingUpgradeStrategy {
+		brokerRacks = getBrokerAzMap(r.KafkaCluster)
+	}
+	reorderedBrokers := reorderBrokers(runningBrokers, boundPersistentVolumeClaims, r.KafkaCluster.Spec.Brokers, r.KafkaCluster.Status.BrokersState, controllerID, brokerRacks, log)
 	allBrokerDynamicConfigSucceeded := true
 	for _, broker := range reorderedBrokers {
 		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
@@ -361,7 +404,7 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 			}
 		}
 
-		pvcs, err := getCreatedPvcForBroker(ctx, r.Client, broker.Id, brokerConfig.StorageConfigs, r.KafkaCluster.Namespace, r.KafkaCluster.Name)
+		pvcs, err := getCreatedPvcForBroker(ctx, r.Client, broker.Id, getNodeStorageConfigs(brokerConfig), r.KafkaCluster.Namespace, r.KafkaCluster.Name)
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "failed to list PVC's")
 		}
@@ -403,6 +446,20 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		return err
 	}
 
+	if err = r.reconcileRestartRequest(ctx, log); err != nil {
+		return err
+	}
+
+	// Every node is reconciled at this point, the protocol version can be raised if every broker runs the new Kafka version
+	if err = r.reconcileProtocolVersion(ctx, log); err != nil {
+		return err
+	}
+
+	// Every node is reconciled at this point, the migration can move on to its next phase if the cluster is healthy
+	if err = r.advanceKRaftMigration(ctx, log); err != nil {
+		return err
+	}
+
 	// in case HeadlessServiceEnabled is changed, delete the service that was created by the previous
 	// reconcile flow. The services must be deleted at the end of the reconcile flow after the new services
 	// were created and broker configurations reflecting the new services otherwise the Kafka brokers
@@ -750,6 +807,7 @@ func (r *Reconciler) reconcileKafkaPod(log logr.Logger, desiredPod *corev1.Pod,
 	}
 	switch {
 	case len(podList.Items) == 0:
+		r.setRestartAnnotation(desiredPod, nil, log)
 		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(desiredPod); err != nil {
 			return errors.WrapIf(err, "could not apply last state to annotation")
 		}
@@ -823,6 +881,7 @@ func (r *Reconciler) reconcileKafkaPod(log logr.Logger, desiredPod *corev1.Pod,
 	default:
 		return errorfactory.New(errorfactory.TooManyResources{}, errors.New("reconcile failed"), "more then one matching pod found", "labels", matchingLabels)
 	}
+	r.setRestartAnnotation(desiredPod, currentPod, log)
 	err = r.handleRollingUpgrade(log, desiredPod, currentPod, desiredType)
 	if err != nil {
 		return errors.Wrap(err, "could not handle rolling upgrade")
@@ -840,6 +899,10 @@ func (r *Reconciler) updateStatusWithDockerImageAndVersion(brokerId int32, broke
 	if err != nil {
 		return err
 	}
+	// The broker may run the image recorded by the image rollback instead of the one in the spec
+	if r.KafkaCluster.Status.ImageRollback != nil {
+		kafkaVersion.Image = r.getBrokerImage(brokerId, brokerConfig)
+	}
 	err = k8sutil.UpdateBrokerStatus(r.Client, []string{strconv.Itoa(int(brokerId))}, r.KafkaCluster,
 		*kafkaVersion, log)
 	if err != nil {
@@ -911,12 +974,15 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("pod count differs from brokers spec"), "rolling upgrade in progress")
 			}
 
-			// Check if we support multiple broker restarts and restart only in same AZ, otherwise restart only 1 broker at once
+			// Check if we support multiple broker restarts and restart only in same AZ, otherwise restart only 1 broker at once.
+			// With the rack by rack strategy every broker of the same AZ can be restarted at once.
+			rackByRack := r.KafkaCluster.Spec.RollingUpgradeConfig.Strategy == v1beta1.RackByRackRollingUpgradeStrategy
+			concurrentRestartsPerRack := rackByRack || r.KafkaCluster.Spec.RollingUpgradeConfig.ConcurrentBrokerRestartCountPerRack > 1
 			terminatingOrPendingPods := getPodsInTerminatingOrPendingState(podList.Items)
-			if len(terminatingOrPendingPods) >= r.KafkaCluster.Spec.RollingUpgradeConfig.ConcurrentBrokerRestartCountPerRack {
+			if !rackByRack && len(terminatingOrPendingPods) >= r.KafkaCluster.Spec.RollingUpgradeConfig.ConcurrentBrokerRestartCountPerRack {
 				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New(strconv.Itoa(r.KafkaCluster.Spec.RollingUpgradeConfig.ConcurrentBrokerRestartCountPerRack)+" pod(s) is still terminating or creating"), "rolling upgrade in progress")
 			}
-			if r.KafkaCluster.Spec.RollingUpgradeConfig.ConcurrentBrokerRestartCountPerRack > 1 && len(terminatingOrPendingPods) > 0 {
+			if concurrentRestartsPerRack && len(terminatingOrPendingPods) > 0 {
 				err = r.checkCCRackAwareDistributionGoal()
 				if err != nil {
 					return err
@@ -924,7 +990,7 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 			}
 			kafkaBrokerAvailabilityZoneMap := getBrokerAzMap(r.KafkaCluster)
 			currentPodAz, _ := r.getBrokerAz(currentPod, kafkaBrokerAvailabilityZoneMap)
-			if r.KafkaCluster.Spec.RollingUpgradeConfig.ConcurrentBrokerRestartCountPerRack > 1 && r.existsTerminatingPodFromAnotherAz(currentPodAz, terminatingOrPendingPods, kafkaBrokerAvailabilityZoneMap) {
+			if concurrentRestartsPerRack && r.existsTerminatingPodFromAnotherAz(currentPodAz, terminatingOrPendingPods, kafkaBrokerAvailabilityZoneMap) {
 				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("pod is still terminating or creating from another AZ"), "rolling upgrade in progress")
 			}
 
@@ -935,41 +1001,48 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 				return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
 			}
 			defer close()
-			allOfflineReplicas, err := kClient.AllOfflineReplicas()
+			impactedReplicas, err := getImpactedReplicas(kClient, log)
 			if err != nil {
-				return errors.WrapIf(err, "health check failed")
+				return err
 			}
-			if len(allOfflineReplicas) > 0 {
-				log.Info("offline replicas", "IDs", allOfflineReplicas)
-			}
-			outOfSyncReplicas, err := kClient.OutOfSyncReplicas()
-			if err != nil {
-				return errors.WrapIf(err, "health check failed")
-			}
-			if len(outOfSyncReplicas) > 0 {
-				log.Info("out-of-sync replicas", "IDs", outOfSyncReplicas)
-			}
-			impactedReplicas := make(map[int32]struct{})
-			for _, brokerID := range allOfflineReplicas {
-				impactedReplicas[brokerID] = struct{}{}
-			}
-			for _, brokerID := range outOfSyncReplicas {
-				impactedReplicas[brokerID] = struct{}{}
+			// With the rack by rack strategy the brokers of the AZ being restarted are expected to be out of sync,
+			// the next AZ is restarted only once every replica of the other AZs is back in sync
+			if rackByRack {
+				if r.existsFailedBrokerFromAnotherRack(currentPodAz, impactedReplicas, kafkaBrokerAvailabilityZoneMap) {
+					return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("broker is not healthy from another AZ"), "rolling upgrade in progress")
+				}
+			} else {
+				errorCount += len(impactedReplicas)
 			}
-			errorCount += len(impactedReplicas)
 			if errorCount >= r.KafkaCluster.Spec.RollingUpgradeConfig.FailureThreshold {
 				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("cluster is not healthy"), "rolling upgrade in progress")
 			}
 
 			// If multiple concurrent restarts and broker failures allowed, restart only brokers from the same AZ
-			if r.KafkaCluster.Spec.RollingUpgradeConfig.ConcurrentBrokerRestartCountPerRack > 1 && r.KafkaCluster.Spec.RollingUpgradeConfig.FailureThreshold > 1 {
+			if !rackByRack && r.KafkaCluster.Spec.RollingUpgradeConfig.ConcurrentBrokerRestartCountPerRack > 1 && r.KafkaCluster.Spec.RollingUpgradeConfig.FailureThreshold > 1 {
 				if r.existsFailedBrokerFromAnotherRack(currentPodAz, impactedReplicas, kafkaBrokerAvailabilityZoneMap) {
 					return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("broker is not healthy from another AZ"), "rolling upgrade in progress")
 				}
 			}
+
+			if err := r.checkBrokerRestartSafety(kClient, currentPod, log); err != nil {
+				return err
+			}
+
+			if err := r.drainLeadership(context.TODO(), kClient, currentPod, log); err != nil {
+				return err
+			}
 		}
 	}
 
+	if err := r.addPendingLeaderElection(currentPod, log); err != nil {
+		return err
+	}
+
+	if err := r.recordImageUpgrade(desiredPod, currentPod, log); err != nil {
+		return err
+	}
+
 	err = r.Client.Delete(context.TODO(), currentPod)
 	if err != nil {
 		return errorfactory.New(errorfactory.APIFailure{}, err, "deleting resource failed", "kind", desiredType)
@@ -988,6 +1061,103 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 	return nil
 }
 
+// maxReportedUnsafePartitions limits the number of partitions listed in the rolling upgrade status
+const maxReportedUnsafePartitions = 10
+
+// checkBrokerRestartSafety blocks the restart of the broker if it would make partitions under-min-ISR or leaderless
+// which would make producers with acks=all fail. The reason is recorded in the rolling upgrade status.
+func (r *Reconciler) checkBrokerRestartSafety(kClient kafkaclient.KafkaClient, pod *corev1.Pod, log logr.Logger) error {
+	brokerID, err := strconv.ParseInt(pod.Labels[v1beta1.BrokerIdLabelKey], 10, 32)
+	if err != nil {
+		return errors.WrapIf(err, "could not parse broker id of the pod")
+	}
+	unsafePartitions, err := kClient.UnsafePartitionsForRestart(int32(brokerID))
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not check the partitions of the broker")
+	}
+
+	var reason string
+	if len(unsafePartitions) > 0 {
+		partitions := make([]string, 0, maxReportedUnsafePartitions+1)
+		for i, partition := range unsafePartitions {
+			if i == maxReportedUnsafePartitions {
+				partitions = append(partitions, fmt.Sprintf("and %d more", len(unsafePartitions)-i))
+				break
+			}
+			partitions = append(partitions, partition.String())
+		}
+		reason = fmt.Sprintf("restarting broker %d would make partitions under-min-ISR or leaderless: %s", brokerID, strings.Join(partitions, ", "))
+	}
+	if reason != r.KafkaCluster.Status.RollingUpgrade.BlockedReason {
+		if err := k8sutil.UpdateRollingUpgradeBlockedReason(r.Client, r.KafkaCluster, reason, log); err != nil {
+			return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting rolling upgrade blocked reason failed")
+		}
+	}
+	if reason != "" {
+		return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New(reason), "rolling upgrade in progress")
+	}
+	return nil
+}
+
+// checkClusterHealth checks that every node has been rolled and is in sync, and that there are no offline or out-of-sync replicas
+// using the same checks the rolling upgrade relies on. The returned error is created with the given errorfactory type.
+func (r *Reconciler) checkClusterHealth(ctx context.Context, kClient kafkaclient.KafkaClient, errType interface{}, msg string, log logr.Logger) error {
+	podList := &corev1.PodList{}
+	matchingLabels := client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name))
+	err := r.Client.List(ctx, podList, client.ListOption(client.InNamespace(r.KafkaCluster.Namespace)), client.ListOption(matchingLabels))
+	if err != nil {
+		return errors.WrapIf(err, "failed to list kafka pods")
+	}
+	if len(podList.Items) != len(r.KafkaCluster.Spec.Brokers) {
+		return errorfactory.New(errType, errors.New("pod count differs from brokers spec"), msg)
+	}
+	if len(getPodsInTerminatingOrPendingState(podList.Items)) > 0 {
+		return errorfactory.New(errType, errors.New("pod(s) still terminating or creating"), msg)
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if k8sutil.IsPodContainsTerminatedContainer(pod) ||
+			r.KafkaCluster.Status.BrokersState[pod.Labels[v1beta1.BrokerIdLabelKey]].ConfigurationState != v1beta1.ConfigInSync {
+			return errorfactory.New(errType, errors.New("broker is not in sync"), msg, v1beta1.BrokerIdLabelKey, pod.Labels[v1beta1.BrokerIdLabelKey])
+		}
+	}
+
+	impactedReplicas, err := getImpactedReplicas(kClient, log)
+	if err != nil {
+		return err
+	}
+	if len(impactedReplicas) > 0 {
+		return errorfactory.New(errType, errors.New("cluster is not healthy"), msg)
+	}
+	return nil
+}
+
+// getImpactedReplicas returns the ids of the brokers which have offline or out-of-sync replicas
+func getImpactedReplicas(kClient kafkaclient.KafkaClient, log logr.Logger) (map[int32]struct{}, error) {
+	allOfflineReplicas, err := kClient.AllOfflineReplicas()
+	if err != nil {
+		return nil, errors.WrapIf(err, "health check failed")
+	}
+	if len(allOfflineReplicas) > 0 {
+		log.Info("offline replicas", "IDs", allOfflineReplicas)
+	}
+	outOfSyncReplicas, err := kClient.OutOfSyncReplicas()
+	if err != nil {
+		return nil, errors.WrapIf(err, "health check failed")
+	}
+	if len(outOfSyncReplicas) > 0 {
+		log.Info("out-of-sync replicas", "IDs", outOfSyncReplicas)
+	}
+	impactedReplicas := make(map[int32]struct{})
+	for _, brokerID := range allOfflineReplicas {
+		impactedReplicas[brokerID] = struct{}{}
+	}
+	for _, brokerID := range outOfSyncReplicas {
+		impactedReplicas[brokerID] = struct{}{}
+	}
+	return impactedReplicas, nil
+}
+
 func (r *Reconciler) checkCCRackAwareDistributionGoal() error {
 	cruiseControlURL := scale.CruiseControlURLFromKafkaCluster(r.KafkaCluster)
 	cc, err := r.CruiseControlScalerFactory(context.TODO(), r.KafkaCluster)
@@ -1549,7 +1719,8 @@ func getServiceFromExternalListener(client client.Client, cluster *v1beta1.Kafka
 //   - prioritize upscale in order to allow upscaling the cluster even when there is a stuck RU
 //   - prioritize missing broker pods to be able for escaping from offline partitions, not all replicas in sync which
 //     could stall RU flow
-func reorderBrokers(runningBrokers, boundPersistentVolumeClaims map[string]struct{}, desiredBrokers []v1beta1.Broker, brokersState map[string]v1beta1.BrokerState, controllerBrokerID int32, log logr.Logger) []v1beta1.Broker {
+//   - running brokers are grouped by their racks if brokerRacks is given, the rack of the controller broker is reconciled last
+func reorderBrokers(runningBrokers, boundPersistentVolumeClaims map[string]struct{}, desiredBrokers []v1beta1.Broker, brokersState map[string]v1beta1.BrokerState, controllerBrokerID int32, brokerRacks map[int32]string, log logr.Logger) []v1beta1.Broker {
 	brokersReconcilePriority := make(map[string]brokerReconcilePriority, len(desiredBrokers))
 	missingBrokerDownScaleRunning := make(map[string]struct{})
 	// logic for handling that case when a broker pod is removed before downscale operation completed
@@ -1593,12 +1764,35 @@ func reorderBrokers(runningBrokers, boundPersistentVolumeClaims map[string]struc
 	// copy desiredBrokers from KafkaCluster CR for ordering
 	reorderedBrokers = append(reorderedBrokers, desiredBrokers...)
 
+	// order the racks by their first appearance with the rack of the controller broker being the last one
+	rackOrder := make(map[string]int)
+	for _, b := range desiredBrokers {
+		if rack, ok := brokerRacks[b.Id]; ok && b.Id != controllerBrokerID {
+			if _, ok := rackOrder[rack]; !ok {
+				rackOrder[rack] = len(rackOrder)
+			}
+		}
+	}
+	if controllerRack, ok := brokerRacks[controllerBrokerID]; ok {
+		rackOrder[controllerRack] = len(rackOrder)
+	}
+
 	// sort brokers by weight
 	sort.SliceStable(reorderedBrokers, func(i, j int) bool {
 		brokerID1 := fmt.Sprintf("%d", reorderedBrokers[i].Id)
 		brokerID2 := fmt.Sprintf("%d", reorderedBrokers[j].Id)
-
-		return brokersReconcilePriority[brokerID1] < brokersReconcilePriority[brokerID2]
+		priority1 := brokersReconcilePriority[brokerID1]
+		priority2 := brokersReconcilePriority[brokerID2]
+
+		// missing and new brokers are still reconciled first to be able to escape from a stalled RU flow
+		if brokerRacks != nil && priority1 >= nonControllerBrokerReconcilePriority && priority2 >= nonControllerBrokerReconcilePriority {
+			rack1 := rackOrder[brokerRacks[reorderedBrokers[i].Id]]
+			rack2 := rackOrder[brokerRacks[reorderedBrokers[j].Id]]
+			if rack1 != rack2 {
+				return rack1 < rack2
+			}
+		}
+		return priority1 < priority2
 	})
 
 	return reorderedBrokers
@@ -333,6 +333,7 @@ func TestReorderBrokers(t *testing.T) {
 		desiredBrokers           []v1beta1.Broker
 		brokersState             map[string]v1beta1.BrokerState
 		controllerBrokerID       int32
+		brokerRacks              map[int32]string
 		expectedReorderedBrokers []v1beta1.Broker
 	}{
 		{
@@ -547,6 +548,44 @@ func TestReorderBrokers(t *testing.T) {
 				{Id: 1}, // controller broker should be last
 			},
 		},
+		{
+			testName: "running brokers grouped by racks with the rack of the controller broker being the last",
+			brokerPods: corev1.PodList{
+				Items: []corev1.Pod{
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.BrokerIdLabelKey: "0"}}},
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.BrokerIdLabelKey: "1"}}},
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.BrokerIdLabelKey: "2"}}},
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.BrokerIdLabelKey: "3"}}},
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.BrokerIdLabelKey: "4"}}},
+				},
+			},
+			desiredBrokers: []v1beta1.Broker{
+				{Id: 0},
+				{Id: 1},
+				{Id: 2},
+				{Id: 3},
+				{Id: 4},
+				{Id: 5},
+			},
+			brokersState: map[string]v1beta1.BrokerState{
+				"0": {ConfigurationState: v1beta1.ConfigOutOfSync},
+				"1": {ConfigurationState: v1beta1.ConfigOutOfSync},
+				"2": {ConfigurationState: v1beta1.ConfigOutOfSync},
+				"3": {ConfigurationState: v1beta1.ConfigOutOfSync},
+				"4": {ConfigurationState: v1beta1.ConfigOutOfSync},
+				"5": {ConfigurationState: v1beta1.ConfigOutOfSync},
+			},
+			controllerBrokerID: 0,
+			brokerRacks:        map[int32]string{0: "az1", 1: "az2", 2: "az3", 3: "az1", 4: "az2", 5: "az3"},
+			expectedReorderedBrokers: []v1beta1.Broker{
+				{Id: 5}, // broker pod 5 missing thus should have higher prio
+				{Id: 1},
+				{Id: 4},
+				{Id: 2},
+				{Id: 3},
+				{Id: 0}, // controller broker and its rack should be last
+			},
+		},
 	}
 
 	t.Parallel()
@@ -570,7 +609,7 @@ func TestReorderBrokers(t *testing.T) {
 				}
 			}
 
-			reorderedBrokers := reorderBrokers(runningBrokers, boundPersistentVolumeClaims, test.desiredBrokers, test.brokersState, test.controllerBrokerID, logr.Discard())
+			reorderedBrokers := reorderBrokers(runningBrokers, boundPersistentVolumeClaims, test.desiredBrokers, test.brokersState, test.controllerBrokerID, test.brokerRacks, logr.Discard())
 
 			g.Expect(reorderedBrokers).To(gomega.Equal(test.expectedReorderedBrokers))
 		})
@@ -1237,6 +1276,7 @@ func TestReconcileConcurrentBrokerRestartsAllowed(t *testing.T) {
 		pods               []corev1.Pod
 		allOfflineReplicas []int32
 		outOfSyncReplicas  []int32
+		unsafePartitions   []kafkaclient.UnsafePartition
 		ccStatus           *scale.StatusTaskResult
 		errorExpected      bool
 	}{
@@ -1741,12 +1781,119 @@ func TestReconcileConcurrentBrokerRestartsAllowed(t *testing.T) {
 			},
 			errorExpected: true,
 		},
+		{
+			testName: "Pod is deleted with rack by rack strategy if brokers of the same AZ are restarting",
+			kafkaCluster: v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kafka",
+					Namespace: "kafka",
+				},
+				Spec: v1beta1.KafkaClusterSpec{
+					Brokers: []v1beta1.Broker{
+						{Id: 101, ReadOnlyConfig: "broker.rack=az1"},
+						{Id: 102, ReadOnlyConfig: "broker.rack=az1"},
+						{Id: 103, ReadOnlyConfig: "broker.rack=az1"},
+						{Id: 201, ReadOnlyConfig: "broker.rack=az2"},
+						{Id: 202, ReadOnlyConfig: "broker.rack=az2"},
+						{Id: 203, ReadOnlyConfig: "broker.rack=az2"}},
+					RollingUpgradeConfig: v1beta1.RollingUpgradeConfig{
+						FailureThreshold:                    1,
+						ConcurrentBrokerRestartCountPerRack: 1,
+						Strategy:                            v1beta1.RackByRackRollingUpgradeStrategy,
+					},
+				},
+				Status: v1beta1.KafkaClusterStatus{State: v1beta1.KafkaClusterRollingUpgrading},
+			},
+			desiredPod: &corev1.Pod{},
+			currentPod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "kafka-103", Labels: map[string]string{"brokerId": "103"}}},
+			pods: []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-101", Labels: map[string]string{"brokerId": "101"}, DeletionTimestamp: &metav1.Time{Time: time.Now()}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-102", Labels: map[string]string{"brokerId": "102"}, DeletionTimestamp: &metav1.Time{Time: time.Now()}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-103", Labels: map[string]string{"brokerId": "103"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-201", Labels: map[string]string{"brokerId": "201"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-202", Labels: map[string]string{"brokerId": "202"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-203", Labels: map[string]string{"brokerId": "203"}}},
+			},
+			allOfflineReplicas: []int32{101},
+			outOfSyncReplicas:  []int32{102},
+			ccStatus: &scale.StatusTaskResult{
+				State: &ccTypes.StateResult{
+					AnalyzerState: ccTypes.AnalyzerState{ReadyGoals: []ccTypes.Goal{ccTypes.RackAwareDistributionGoal}},
+					AnomalyDetectorState: ccTypes.AnomalyDetectorState{
+						RecentGoalViolations: []ccTypes.AnomalyDetails{{UnfixableViolatedGoals: []ccTypes.Goal{}, FixableViolatedGoals: []ccTypes.Goal{}}},
+					},
+				},
+			},
+			errorExpected: false,
+		},
+		{
+			testName: "Pod is not deleted with rack by rack strategy if brokers of another AZ are not back in sync",
+			kafkaCluster: v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kafka",
+					Namespace: "kafka",
+				},
+				Spec: v1beta1.KafkaClusterSpec{
+					Brokers: []v1beta1.Broker{
+						{Id: 101, ReadOnlyConfig: "broker.rack=az1"},
+						{Id: 102, ReadOnlyConfig: "broker.rack=az1"},
+						{Id: 201, ReadOnlyConfig: "broker.rack=az2"},
+						{Id: 202, ReadOnlyConfig: "broker.rack=az2"}},
+					RollingUpgradeConfig: v1beta1.RollingUpgradeConfig{
+						FailureThreshold:                    1,
+						ConcurrentBrokerRestartCountPerRack: 1,
+						Strategy:                            v1beta1.RackByRackRollingUpgradeStrategy,
+					},
+				},
+				Status: v1beta1.KafkaClusterStatus{State: v1beta1.KafkaClusterRollingUpgrading},
+			},
+			desiredPod: &corev1.Pod{},
+			currentPod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "kafka-201", Labels: map[string]string{"brokerId": "201"}}},
+			pods: []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-101", Labels: map[string]string{"brokerId": "101"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-102", Labels: map[string]string{"brokerId": "102"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-201", Labels: map[string]string{"brokerId": "201"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-202", Labels: map[string]string{"brokerId": "202"}}},
+			},
+			allOfflineReplicas: []int32{},
+			outOfSyncReplicas:  []int32{102},
+			errorExpected:      true,
+		},
+		{
+			testName: "Pod is not deleted if restarting the broker would make partitions under-min-ISR",
+			kafkaCluster: v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kafka",
+					Namespace: "kafka",
+				},
+				Spec: v1beta1.KafkaClusterSpec{
+					Brokers: []v1beta1.Broker{{Id: 101}, {Id: 102}, {Id: 103}},
+					RollingUpgradeConfig: v1beta1.RollingUpgradeConfig{
+						FailureThreshold:                    2,
+						ConcurrentBrokerRestartCountPerRack: 1,
+					},
+				},
+				Status: v1beta1.KafkaClusterStatus{State: v1beta1.KafkaClusterRollingUpgrading},
+			},
+			desiredPod: &corev1.Pod{},
+			currentPod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "kafka-102", Labels: map[string]string{"brokerId": "102"}}},
+			pods: []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-101", Labels: map[string]string{"brokerId": "101"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-102", Labels: map[string]string{"brokerId": "102"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-103", Labels: map[string]string{"brokerId": "103"}}},
+			},
+			allOfflineReplicas: []int32{},
+			outOfSyncReplicas:  []int32{101},
+			unsafePartitions:   []kafkaclient.UnsafePartition{{Topic: "test", Partition: 0, InSyncReplicas: 2, MinInSyncReplicas: 2}},
+			errorExpected:      true,
+		},
 	}
 
 	mockCtrl := gomock.NewController(t)
 
 	for _, test := range testCases {
 		mockClient := mocks.NewMockClient(mockCtrl)
+		mockSubResourceClient := mocks.NewMockSubResourceClient(mockCtrl)
 		mockKafkaClientProvider := new(kafkaclient.MockedProvider)
 
 		t.Run(test.testName, func(t *testing.T) {
@@ -1773,8 +1920,13 @@ func TestReconcileConcurrentBrokerRestartsAllowed(t *testing.T) {
 			if test.outOfSyncReplicas != nil {
 				mockedKafkaClient.EXPECT().OutOfSyncReplicas().Return(test.outOfSyncReplicas, nil)
 			}
+			mockedKafkaClient.EXPECT().UnsafePartitionsForRestart(gomock.Any()).Return(test.unsafePartitions, nil).AnyTimes()
 			mockKafkaClientProvider.On("NewFromCluster", mockClient, &test.kafkaCluster).Return(mockedKafkaClient, func() {}, nil)
 
+			// Mock the status update call
+			mockClient.EXPECT().Status().Return(mockSubResourceClient).AnyTimes()
+			mockSubResourceClient.EXPECT().Update(context.Background(), gomock.AssignableToTypeOf(&v1beta1.KafkaCluster{})).Return(nil).AnyTimes()
+
 			// Mock Cruise Control client
 			mockCruiseControl := controllerMocks.NewMockCruiseControlScaler(mockCtrl)
 			if test.ccStatus != nil {
@@ -1791,6 +1943,9 @@ func TestReconcileConcurrentBrokerRestartsAllowed(t *testing.T) {
 			} else {
 				assert.Nil(t, err, "Expected no error but got one")
 			}
+			if len(test.unsafePartitions) > 0 {
+				assert.Contains(t, r.KafkaCluster.Status.RollingUpgrade.BlockedReason, "test-0")
+			}
 		})
 	}
 }
@@ -1876,3 +2031,66 @@ func TestGetBrokerAzMap(t *testing.T) {
 		})
 	}
 }
+
+func TestGetLowestBrokerProtocolVersion(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		testName        string
+		kafkaCluster    v1beta1.KafkaCluster
+		expectedVersion string
+		expectedErr     bool
+	}{
+		{
+			testName: "version of a broker is not known yet",
+			kafkaCluster: v1beta1.KafkaCluster{
+				Spec: v1beta1.KafkaClusterSpec{
+					Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}},
+				},
+				Status: v1beta1.KafkaClusterStatus{
+					BrokersState: map[string]v1beta1.BrokerState{
+						"0": {Version: "3.4.1"},
+					},
+				},
+			},
+			expectedVersion: "",
+		},
+		{
+			testName: "brokers are being rolled to a new version",
+			kafkaCluster: v1beta1.KafkaCluster{
+				Spec: v1beta1.KafkaClusterSpec{
+					Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}},
+				},
+				Status: v1beta1.KafkaClusterStatus{
+					BrokersState: map[string]v1beta1.BrokerState{
+						"0": {Version: "3.5.1"},
+						"1": {Version: "3.4.1"},
+						"2": {Version: "3.5.1"},
+					},
+				},
+			},
+			expectedVersion: "3.4",
+		},
+		{
+			testName: "invalid broker version",
+			kafkaCluster: v1beta1.KafkaCluster{
+				Spec: v1beta1.KafkaClusterSpec{
+					Brokers: []v1beta1.Broker{{Id: 0}},
+				},
+				Status: v1beta1.KafkaClusterStatus{
+					BrokersState: map[string]v1beta1.BrokerState{
+						"0": {Version: "unknown"},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			version, err := getLowestBrokerProtocolVersion(&test.kafkaCluster)
+			assert.Equal(t, test.expectedErr, err != nil)
+			assert.Equal(t, test.expectedVersion, version)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// nextKRaftMigrationState describes the order of the phases of the migration from ZooKeeper to KRaft
+var nextKRaftMigrationState = map[v1beta1.ClusterState]v1beta1.ClusterState{
+	// The KRaft controllers are running and copy the metadata from ZooKeeper
+	v1beta1.KafkaClusterKRaftMigrationDeployingControllers: v1beta1.KafkaClusterKRaftMigrationEnablingBrokers,
+	// The ZooKeeper based brokers are registered to the KRaft controllers
+	v1beta1.KafkaClusterKRaftMigrationEnablingBrokers: v1beta1.KafkaClusterKRaftMigrationMigratingBrokers,
+	// The brokers run in KRaft mode
+	v1beta1.KafkaClusterKRaftMigrationMigratingBrokers: v1beta1.KafkaClusterKRaftMigrationFinalizing,
+	// The KRaft controllers do not use ZooKeeper anymore
+	v1beta1.KafkaClusterKRaftMigrationFinalizing: v1beta1.KafkaClusterKRaftMigrationCompleted,
+}
+
+// startKRaftMigration takes over the id of the ZooKeeper based cluster and starts the deployment of the KRaft controllers
+func (r *Reconciler) startKRaftMigration(ctx context.Context, log logr.Logger) error {
+	if !r.KafkaCluster.IsKRaftMigrationInProgress() || r.KafkaCluster.Status.KRaftMigrationState != "" {
+		return nil
+	}
+
+	// The KRaft controllers must be formatted with the id of the existing cluster
+	if r.KafkaCluster.Status.ClusterID == "" {
+		kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+		if err != nil {
+			return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+		}
+		defer close()
+
+		clusterID, err := kClient.ClusterID()
+		if err != nil {
+			return errors.WrapIf(err, "could not get the id of the ZooKeeper based cluster")
+		}
+		if err := k8sutil.UpdateClusterID(ctx, r.Client, r.KafkaCluster, clusterID); err != nil {
+			return errors.WrapIf(err, "could not update cluster id")
+		}
+	}
+
+	log.Info("starting the migration from ZooKeeper to KRaft")
+	if err := k8sutil.UpdateKRaftMigrationState(r.Client, r.KafkaCluster, v1beta1.KafkaClusterKRaftMigrationDeployingControllers, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting KRaft migration state failed")
+	}
+	return nil
+}
+
+// advanceKRaftMigration moves the migration from ZooKeeper to KRaft to its next phase
+// once every node has been rolled with the configuration of the current phase and the cluster is healthy
+func (r *Reconciler) advanceKRaftMigration(ctx context.Context, log logr.Logger) error {
+	if !r.KafkaCluster.IsKRaftMigrationInProgress() {
+		return nil
+	}
+
+	currentState := r.KafkaCluster.Status.KRaftMigrationState
+	nextState, ok := nextKRaftMigrationState[currentState]
+	if !ok {
+		return errors.NewWithDetails("unknown KRaft migration state", "state", currentState)
+	}
+
+	if err := r.checkKRaftMigrationHealth(ctx, currentState, log); err != nil {
+		return err
+	}
+
+	log.Info("advancing the migration from ZooKeeper to KRaft", "from", currentState, "to", nextState)
+	if err := k8sutil.UpdateKRaftMigrationState(r.Client, r.KafkaCluster, nextState, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting KRaft migration state failed")
+	}
+	if nextState == v1beta1.KafkaClusterKRaftMigrationCompleted {
+		log.Info("migration from ZooKeeper to KRaft completed")
+		return nil
+	}
+	// The nodes have to be rolled with the configuration of the next phase
+	return errorfactory.New(errorfactory.ReconcileKRaftMigration{}, errors.New("KRaft migration phase changed"), "KRaft migration in progress", "state", nextState)
+}
+
+// checkKRaftMigrationHealth checks whether the cluster is healthy enough to move on to the next phase of the migration
+func (r *Reconciler) checkKRaftMigrationHealth(ctx context.Context, currentState v1beta1.ClusterState, log logr.Logger) error {
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer close()
+
+	if err := r.checkClusterHealth(ctx, kClient, errorfactory.ReconcileKRaftMigration{}, "KRaft migration in progress", log); err != nil {
+		return err
+	}
+
+	// The brokers can leave ZooKeeper only once the KRaft controllers have taken over the metadata
+	if currentState == v1beta1.KafkaClusterKRaftMigrationEnablingBrokers {
+		return r.checkKRaftControllerIsActive(kClient)
+	}
+	return nil
+}
+
+// checkKRaftControllerIsActive checks whether the active controller of the cluster is one of the KRaft controllers
+func (r *Reconciler) checkKRaftControllerIsActive(kClient kafkaclient.KafkaClient) error {
+	_, controllerID, err := kClient.DescribeCluster()
+	if err != nil {
+		return errors.WrapIf(err, "could not describe the cluster")
+	}
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		if broker.Id != controllerID {
+			continue
+		}
+		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
+		if err != nil {
+			return errors.WrapIf(err, "failed to determine broker config")
+		}
+		if brokerConfig.IsControllerNode() {
+			return nil
+		}
+	}
+	return errorfactory.New(errorfactory.ReconcileKRaftMigration{},
+		fmt.Errorf("the active controller %d is not a KRaft controller", controllerID),
+		"KRaft migration in progress")
+}
@@ -0,0 +1,147 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// addPendingLeaderElection records that the preferred leader election has to be run for the broker of the given pod
+// once it is restarted and back in the ISR
+func (r *Reconciler) addPendingLeaderElection(pod *corev1.Pod, log logr.Logger) error {
+	if !r.KafkaCluster.Spec.RollingUpgradeConfig.PreferredLeaderElection {
+		return nil
+	}
+	brokerID, err := strconv.ParseInt(pod.Labels[v1beta1.BrokerIdLabelKey], 10, 32)
+	if err != nil {
+		return errors.WrapIf(err, "could not parse broker id of the pod")
+	}
+
+	status := r.KafkaCluster.Status.PreferredLeaderElection
+	for _, pendingBroker := range status.PendingBrokers {
+		if pendingBroker == int32(brokerID) {
+			return nil
+		}
+	}
+	status.PendingBrokers = append(append([]int32{}, status.PendingBrokers...), int32(brokerID))
+	if err := k8sutil.UpdatePreferredLeaderElectionStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "adding broker to pending preferred leader election failed")
+	}
+	return nil
+}
+
+// reconcilePreferredLeaderElection runs preferred leader election for the partitions of the restarted brokers
+// which are back in the ISR, so that the leadership moves back to them before the next broker is restarted.
+// Failing elections do not block the reconciliation as the partitions are served by their current leaders.
+func (r *Reconciler) reconcilePreferredLeaderElection(ctx context.Context, log logr.Logger) error {
+	status := r.KafkaCluster.Status.PreferredLeaderElection
+	if len(status.PendingBrokers) == 0 {
+		return nil
+	}
+	if !r.KafkaCluster.Spec.RollingUpgradeConfig.PreferredLeaderElection {
+		status.PendingBrokers = nil
+		if err := k8sutil.UpdatePreferredLeaderElectionStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+			return errorfactory.New(errorfactory.StatusUpdateError{}, err, "clearing pending preferred leader election failed")
+		}
+		return nil
+	}
+
+	podList := &corev1.PodList{}
+	matchingLabels := client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name))
+	err := r.Client.List(ctx, podList, client.ListOption(client.InNamespace(r.KafkaCluster.Namespace)), client.ListOption(matchingLabels))
+	if err != nil {
+		return errors.WrapIf(err, "failed to list kafka pods")
+	}
+	// The broker may not have been stopped yet, its partitions must not be treated as caught up
+	restartingBrokers := make(map[string]struct{})
+	for _, pod := range getPodsInTerminatingOrPendingState(podList.Items) {
+		restartingBrokers[pod.Labels[v1beta1.BrokerIdLabelKey]] = struct{}{}
+	}
+
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		log.Error(err, "could not connect to kafka brokers to run preferred leader election")
+		return nil
+	}
+	defer close()
+
+	impactedReplicas, err := getImpactedReplicas(kClient, log)
+	if err != nil {
+		log.Error(err, "could not check whether the restarted brokers are back in the ISR")
+		return nil
+	}
+
+	var pendingBrokers, electedBrokers []int32
+	partitions := make(map[string][]int32)
+	for _, brokerID := range status.PendingBrokers {
+		_, restarting := restartingBrokers[strconv.Itoa(int(brokerID))]
+		_, impacted := impactedReplicas[brokerID]
+		// The leadership can move back to the broker only once it is the preferred replica again
+		if restarting || impacted || hasDemotedPartitions(r.KafkaCluster, brokerID) {
+			pendingBrokers = append(pendingBrokers, brokerID)
+			continue
+		}
+		brokerPartitions, err := kClient.PreferredLeaderPartitions(brokerID)
+		if err != nil {
+			log.Error(err, "could not get the partitions to run preferred leader election for", v1beta1.BrokerIdLabelKey, brokerID)
+			pendingBrokers = append(pendingBrokers, brokerID)
+			continue
+		}
+		for topic, topicPartitions := range brokerPartitions {
+			partitions[topic] = append(partitions[topic], topicPartitions...)
+		}
+		electedBrokers = append(electedBrokers, brokerID)
+	}
+	if len(electedBrokers) == 0 {
+		return nil
+	}
+
+	var elected int
+	var electionErr error
+	if len(partitions) > 0 {
+		elected, electionErr = kClient.ElectLeaders(partitions)
+	}
+	if electionErr != nil {
+		log.Error(electionErr, "preferred leader election failed for some of the partitions", "brokers", electedBrokers)
+	} else {
+		log.Info("preferred leader election completed", "brokers", electedBrokers, "electedPartitions", elected)
+	}
+
+	status = v1beta1.PreferredLeaderElectionStatus{
+		PendingBrokers:     pendingBrokers,
+		LastElectionTime:   time.Now().Format(time.RFC3339),
+		LastElectedBrokers: electedBrokers,
+		ElectedPartitions:  elected,
+	}
+	if electionErr != nil {
+		status.Error = electionErr.Error()
+	}
+	if err := k8sutil.UpdatePreferredLeaderElectionStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "updating preferred leader election status failed")
+	}
+	return nil
+}
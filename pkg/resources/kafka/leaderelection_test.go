@@ -0,0 +1,138 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka/mocks"
+)
+
+func TestReconcilePreferredLeaderElection(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		testName               string
+		enabled                bool
+		pendingBrokers         []int32
+		pods                   []corev1.Pod
+		outOfSyncReplicas      []int32
+		expectedElection       map[string][]int32
+		expectedPendingBrokers []int32
+		expectedElectedBrokers []int32
+		brokersState           map[string]v1beta1.BrokerState
+	}{
+		{
+			testName:       "no pending brokers",
+			enabled:        true,
+			pendingBrokers: nil,
+		},
+		{
+			testName:               "pending brokers are cleared if the election is disabled",
+			enabled:                false,
+			pendingBrokers:         []int32{101},
+			expectedPendingBrokers: nil,
+		},
+		{
+			testName:       "restarting broker stays pending",
+			enabled:        true,
+			pendingBrokers: []int32{101},
+			pods: []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-101", Labels: map[string]string{"brokerId": "101"}, DeletionTimestamp: &metav1.Time{Time: time.Now()}}},
+			},
+			outOfSyncReplicas:      []int32{},
+			expectedPendingBrokers: []int32{101},
+		},
+		{
+			testName:               "broker out of the ISR stays pending",
+			enabled:                true,
+			pendingBrokers:         []int32{101, 102},
+			pods:                   []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "kafka-101", Labels: map[string]string{"brokerId": "101"}}}},
+			outOfSyncReplicas:      []int32{101},
+			expectedElection:       map[string][]int32{"test-topic": {0}},
+			expectedPendingBrokers: []int32{101},
+			expectedElectedBrokers: []int32{102},
+		},
+		{
+			testName:          "broker whose replica order is not restored yet stays pending",
+			enabled:           true,
+			pendingBrokers:    []int32{101},
+			pods:              []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "kafka-101", Labels: map[string]string{"brokerId": "101"}}}},
+			outOfSyncReplicas: []int32{},
+			brokersState: map[string]v1beta1.BrokerState{
+				"101": {LeadershipDrainState: &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDrained, DemotedPartitions: map[string][]int32{"test-topic": {0}}}},
+			},
+			expectedPendingBrokers: []int32{101},
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(mockCtrl)
+			mockSubResourceClient := mocks.NewMockSubResourceClient(mockCtrl)
+			mockKafkaClientProvider := new(kafkaclient.MockedProvider)
+			kafkaCluster := &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+				Spec: v1beta1.KafkaClusterSpec{
+					RollingUpgradeConfig: v1beta1.RollingUpgradeConfig{PreferredLeaderElection: test.enabled},
+				},
+				Status: v1beta1.KafkaClusterStatus{
+					PreferredLeaderElection: v1beta1.PreferredLeaderElectionStatus{PendingBrokers: test.pendingBrokers},
+					BrokersState:            test.brokersState,
+				},
+			}
+			r := New(mockClient, nil, kafkaCluster, mockKafkaClientProvider)
+
+			mockClient.EXPECT().List(
+				context.Background(),
+				gomock.AssignableToTypeOf(&corev1.PodList{}),
+				client.InNamespace("kafka"),
+				gomock.Any(),
+			).Do(func(ctx context.Context, list *corev1.PodList, opts ...client.ListOption) {
+				list.Items = test.pods
+			}).Return(nil).AnyTimes()
+			mockClient.EXPECT().Status().Return(mockSubResourceClient).AnyTimes()
+			mockSubResourceClient.EXPECT().Update(context.Background(), gomock.AssignableToTypeOf(&v1beta1.KafkaCluster{})).Return(nil).AnyTimes()
+
+			mockedKafkaClient := mocks.NewMockKafkaClient(mockCtrl)
+			mockedKafkaClient.EXPECT().AllOfflineReplicas().Return([]int32{}, nil).AnyTimes()
+			mockedKafkaClient.EXPECT().OutOfSyncReplicas().Return(test.outOfSyncReplicas, nil).AnyTimes()
+			mockedKafkaClient.EXPECT().PreferredLeaderPartitions(gomock.Any()).Return(map[string][]int32{"test-topic": {0}}, nil).AnyTimes()
+			if test.expectedElection != nil {
+				mockedKafkaClient.EXPECT().ElectLeaders(test.expectedElection).Return(1, nil)
+			}
+			mockKafkaClientProvider.On("NewFromCluster", mockClient, kafkaCluster).Return(mockedKafkaClient, func() {}, nil)
+
+			err := r.reconcilePreferredLeaderElection(context.Background(), logr.Discard())
+
+			assert.Nil(t, err)
+			status := r.KafkaCluster.Status.PreferredLeaderElection
+			assert.Equal(t, test.expectedPendingBrokers, status.PendingBrokers)
+			assert.Equal(t, test.expectedElectedBrokers, status.LastElectedBrokers)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+const leadershipDrainTimeFormat = time.RFC3339
+
+// drainLeadership moves the partition leadership away from the broker of the given pod using Cruise Control demote
+// and blocks the deletion of the pod until the broker leads zero partitions or the drain times out
+func (r *Reconciler) drainLeadership(ctx context.Context, kClient kafkaclient.KafkaClient, pod *corev1.Pod, log logr.Logger) error {
+	timeout := time.Duration(r.KafkaCluster.Spec.RollingUpgradeConfig.LeadershipDrainTimeoutSeconds) * time.Second
+	if timeout == 0 {
+		return nil
+	}
+
+	brokerID := pod.Labels[v1beta1.BrokerIdLabelKey]
+	parsedBrokerID, err := strconv.ParseInt(brokerID, 10, 32)
+	if err != nil {
+		return errors.WrapIf(err, "could not parse broker id of the pod")
+	}
+	leaderCount, err := kClient.LeaderPartitionCount(int32(parsedBrokerID))
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not get the partition leadership of the broker", "brokerId", brokerID)
+	}
+
+	drainState := r.KafkaCluster.Status.BrokersState[brokerID].LeadershipDrainState
+	draining := drainState != nil && drainState.Phase == v1beta1.LeadershipDraining
+
+	switch {
+	case leaderCount == 0:
+		if draining {
+			log.Info("partition leadership drained from broker", v1beta1.BrokerIdLabelKey, brokerID)
+			return r.updateLeadershipDrainState(brokerID, v1beta1.LeadershipDrained, drainState.StartedAt, leaderCount, drainState.DemotedPartitions, log)
+		}
+		return nil
+	case !draining:
+		// The demote moves the broker to the end of the replicas, their order is restored once the broker is back in sync
+		demotedPartitions, err := kClient.PreferredReplicaPartitions(int32(parsedBrokerID))
+		if err != nil {
+			return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not get the preferred replicas of the broker", "brokerId", brokerID)
+		}
+		// The partitions of an earlier demote are kept until their replica order is restored
+		if drainState != nil {
+			demotedPartitions = mergePartitions(drainState.DemotedPartitions, demotedPartitions)
+		}
+		log.Info("draining partition leadership from broker", v1beta1.BrokerIdLabelKey, brokerID, "leaderCount", leaderCount)
+		r.demoteBroker(ctx, brokerID, log)
+		if err := r.updateLeadershipDrainState(brokerID, v1beta1.LeadershipDraining, time.Now().Format(leadershipDrainTimeFormat), leaderCount,
+			demotedPartitions, log); err != nil {
+			return err
+		}
+	default:
+		startedAt, err := time.Parse(leadershipDrainTimeFormat, drainState.StartedAt)
+		if err != nil || time.Since(startedAt) > timeout {
+			log.Info("partition leadership drain timed out, deleting the broker pod anyway", v1beta1.BrokerIdLabelKey, brokerID, "leaderCount", leaderCount)
+			return r.updateLeadershipDrainState(brokerID, v1beta1.LeadershipDrainTimedOut, drainState.StartedAt, leaderCount, drainState.DemotedPartitions, log)
+		}
+		if leaderCount != drainState.LeaderCount {
+			if err := r.updateLeadershipDrainState(brokerID, v1beta1.LeadershipDraining, drainState.StartedAt, leaderCount, drainState.DemotedPartitions, log); err != nil {
+				return err
+			}
+		}
+	}
+	return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("broker still leads partitions"), "leadership drain in progress",
+		"brokerId", brokerID, "leaderCount", leaderCount)
+}
+
+// demoteBroker requests Cruise Control to move the partition leadership away from the broker which also moves the broker
+// to the end of the replicas of the partitions. Failures are only logged since the drain is bounded by its timeout anyway.
+func (r *Reconciler) demoteBroker(ctx context.Context, brokerID string, log logr.Logger) {
+	cc, err := r.CruiseControlScalerFactory(ctx, r.KafkaCluster)
+	if err != nil {
+		log.Error(err, "failed to initialize Cruise Control", "cruise control url", scale.CruiseControlURLFromKafkaCluster(r.KafkaCluster))
+		return
+	}
+	if _, err := cc.DemoteBrokers(ctx, brokerID); err != nil {
+		log.Error(err, "failed to demote broker with Cruise Control", v1beta1.BrokerIdLabelKey, brokerID)
+	}
+}
+
+func (r *Reconciler) updateLeadershipDrainState(brokerID string, phase v1beta1.LeadershipDrainPhase, startedAt string, leaderCount int,
+	demotedPartitions map[string][]int32, log logr.Logger) error {
+	state := v1beta1.LeadershipDrainState{
+		Phase:             phase,
+		StartedAt:         startedAt,
+		LeaderCount:       leaderCount,
+		DemotedPartitions: demotedPartitions,
+	}
+	if err := k8sutil.UpdateBrokerStatus(r.Client, []string{brokerID}, r.KafkaCluster, state, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "updating leadership drain state failed", "brokerId", brokerID)
+	}
+	return nil
+}
+
+// reconcileDemotedReplicas moves the restarted brokers back to the front of the replicas of the partitions they were demoted
+// from once they are back in the ISR, so that the preferred leader election moves the leadership back to them.
+// Failures do not block the reconciliation, the replica order is restored by the next one.
+func (r *Reconciler) reconcileDemotedReplicas(ctx context.Context, log logr.Logger) error {
+	var demotedBrokers []string
+	for brokerID, state := range r.KafkaCluster.Status.BrokersState {
+		if drainState := state.LeadershipDrainState; drainState != nil && drainState.Phase != v1beta1.LeadershipDraining &&
+			len(drainState.DemotedPartitions) > 0 {
+			demotedBrokers = append(demotedBrokers, brokerID)
+		}
+	}
+	if len(demotedBrokers) == 0 {
+		return nil
+	}
+	sort.Strings(demotedBrokers)
+
+	podList := &corev1.PodList{}
+	matchingLabels := client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name))
+	err := r.Client.List(ctx, podList, client.ListOption(client.InNamespace(r.KafkaCluster.Namespace)), client.ListOption(matchingLabels))
+	if err != nil {
+		return errors.WrapIf(err, "failed to list kafka pods")
+	}
+	// The broker may not have been stopped yet, it must not be treated as restarted
+	restartingBrokers := make(map[string]struct{})
+	for _, pod := range getPodsInTerminatingOrPendingState(podList.Items) {
+		restartingBrokers[pod.Labels[v1beta1.BrokerIdLabelKey]] = struct{}{}
+	}
+
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		log.Error(err, "could not connect to kafka brokers to restore the replica order")
+		return nil
+	}
+	defer close()
+
+	impactedReplicas, err := getImpactedReplicas(kClient, log)
+	if err != nil {
+		log.Error(err, "could not check whether the restarted brokers are back in the ISR")
+		return nil
+	}
+
+	for _, brokerID := range demotedBrokers {
+		parsedBrokerID, err := strconv.ParseInt(brokerID, 10, 32)
+		if err != nil {
+			continue
+		}
+		_, restarting := restartingBrokers[brokerID]
+		_, impacted := impactedReplicas[int32(parsedBrokerID)]
+		if restarting || impacted {
+			continue
+		}
+		drainState := r.KafkaCluster.Status.BrokersState[brokerID].LeadershipDrainState
+		restored, pending, err := kClient.RestorePreferredReplica(int32(parsedBrokerID), drainState.DemotedPartitions)
+		if err != nil {
+			log.Error(err, "could not restore the replica order of the demoted partitions", v1beta1.BrokerIdLabelKey, brokerID)
+			continue
+		}
+		log.Info("replica order of the demoted partitions restored", v1beta1.BrokerIdLabelKey, brokerID, "restoredPartitions", restored)
+		// The partitions of the topics being reassigned are kept until their reassignment is completed
+		if len(pending) == 0 {
+			pending = nil
+		}
+		if err := r.updateLeadershipDrainState(brokerID, drainState.Phase, drainState.StartedAt, drainState.LeaderCount, pending, log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasDemotedPartitions returns whether the replica order of the partitions the broker was demoted from is not restored yet
+func hasDemotedPartitions(kafkaCluster *v1beta1.KafkaCluster, brokerID int32) bool {
+	drainState := kafkaCluster.Status.BrokersState[strconv.Itoa(int(brokerID))].LeadershipDrainState
+	return drainState != nil && len(drainState.DemotedPartitions) > 0
+}
+
+// mergePartitions returns the union of the given partitions by topic
+func mergePartitions(a, b map[string][]int32) map[string][]int32 {
+	merged := make(map[string][]int32, len(a)+len(b))
+	for _, partitions := range []map[string][]int32{a, b} {
+		for topic, topicPartitions := range partitions {
+			for _, partition := range topicPartitions {
+				if !containsPartition(merged[topic], partition) {
+					merged[topic] = append(merged[topic], partition)
+				}
+			}
+		}
+	}
+	return merged
+}
+
+func containsPartition(partitions []int32, partition int32) bool {
+	for _, p := range partitions {
+		if p == partition {
+			return true
+		}
+	}
+	return false
+}
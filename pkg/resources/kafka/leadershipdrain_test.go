@@ -0,0 +1,275 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	controllerMocks "github.com/banzaicloud/koperator/controllers/tests/mocks"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka/mocks"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+func TestDrainLeadership(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		testName       string
+		timeoutSeconds int
+		drainState     *v1beta1.LeadershipDrainState
+		leaderCount    int
+		demoteExpected bool
+		errorExpected  bool
+		expectedPhase  v1beta1.LeadershipDrainPhase
+
+		expectedDemotedPartitions map[string][]int32
+	}{
+		{
+			testName:       "leadership drain is disabled",
+			timeoutSeconds: 0,
+			leaderCount:    10,
+		},
+		{
+			testName:       "broker leads zero partitions",
+			timeoutSeconds: 60,
+			leaderCount:    0,
+		},
+		{
+			testName:       "leadership drain is started",
+			timeoutSeconds: 60,
+			leaderCount:    10,
+			demoteExpected: true,
+			errorExpected:  true,
+			expectedPhase:  v1beta1.LeadershipDraining,
+
+			expectedDemotedPartitions: map[string][]int32{"test-topic": {0}},
+		},
+		{
+			testName:       "leadership drain is restarted for a new rolling upgrade",
+			timeoutSeconds: 60,
+			drainState:     &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDrained, StartedAt: time.Now().Add(-time.Hour).Format(time.RFC3339)},
+			leaderCount:    10,
+			demoteExpected: true,
+			errorExpected:  true,
+			expectedPhase:  v1beta1.LeadershipDraining,
+
+			expectedDemotedPartitions: map[string][]int32{"test-topic": {0}},
+		},
+		{
+			testName:       "partitions of an earlier demote which are not restored yet are kept",
+			timeoutSeconds: 60,
+			drainState: &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDrainTimedOut, StartedAt: time.Now().Add(-time.Hour).Format(time.RFC3339),
+				DemotedPartitions: map[string][]int32{"test-topic": {1}, "other-topic": {0}}},
+			leaderCount:    10,
+			demoteExpected: true,
+			errorExpected:  true,
+			expectedPhase:  v1beta1.LeadershipDraining,
+
+			expectedDemotedPartitions: map[string][]int32{"test-topic": {1, 0}, "other-topic": {0}},
+		},
+		{
+			testName:       "leadership drain is in progress",
+			timeoutSeconds: 60,
+			drainState:     &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDraining, StartedAt: time.Now().Format(time.RFC3339), LeaderCount: 10},
+			leaderCount:    5,
+			errorExpected:  true,
+			expectedPhase:  v1beta1.LeadershipDraining,
+		},
+		{
+			testName:       "leadership drain is completed",
+			timeoutSeconds: 60,
+			drainState: &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDraining, StartedAt: time.Now().Format(time.RFC3339), LeaderCount: 10,
+				DemotedPartitions: map[string][]int32{"test-topic": {0}}},
+			leaderCount:   0,
+			expectedPhase: v1beta1.LeadershipDrained,
+
+			expectedDemotedPartitions: map[string][]int32{"test-topic": {0}},
+		},
+		{
+			testName:       "leadership drain is timed out",
+			timeoutSeconds: 60,
+			drainState:     &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDraining, StartedAt: time.Now().Add(-time.Hour).Format(time.RFC3339), LeaderCount: 10},
+			leaderCount:    3,
+			expectedPhase:  v1beta1.LeadershipDrainTimedOut,
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(mockCtrl)
+			mockSubResourceClient := mocks.NewMockSubResourceClient(mockCtrl)
+			mockClient.EXPECT().Status().Return(mockSubResourceClient).AnyTimes()
+			mockSubResourceClient.EXPECT().Update(context.Background(), gomock.AssignableToTypeOf(&v1beta1.KafkaCluster{})).Return(nil).AnyTimes()
+
+			mockedKafkaClient := mocks.NewMockKafkaClient(mockCtrl)
+			mockedKafkaClient.EXPECT().LeaderPartitionCount(int32(101)).Return(test.leaderCount, nil).AnyTimes()
+			mockedKafkaClient.EXPECT().PreferredReplicaPartitions(int32(101)).Return(map[string][]int32{"test-topic": {0}}, nil).AnyTimes()
+
+			mockCruiseControl := controllerMocks.NewMockCruiseControlScaler(mockCtrl)
+			if test.demoteExpected {
+				mockCruiseControl.EXPECT().DemoteBrokers(gomock.Any(), "101").Return(&scale.Result{}, nil)
+			}
+
+			r := Reconciler{
+				Reconciler: resources.Reconciler{
+					Client: mockClient,
+					KafkaCluster: &v1beta1.KafkaCluster{
+						Spec: v1beta1.KafkaClusterSpec{
+							RollingUpgradeConfig: v1beta1.RollingUpgradeConfig{LeadershipDrainTimeoutSeconds: test.timeoutSeconds},
+						},
+						Status: v1beta1.KafkaClusterStatus{
+							BrokersState: map[string]v1beta1.BrokerState{"101": {LeadershipDrainState: test.drainState}},
+						},
+					},
+				},
+				CruiseControlScalerFactory: controllerMocks.NewMockScaleFactory(mockCruiseControl),
+			}
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.BrokerIdLabelKey: "101"}}}
+
+			err := r.drainLeadership(context.Background(), mockedKafkaClient, pod, logr.Discard())
+
+			assert.Equal(t, test.errorExpected, err != nil)
+			drainState := r.KafkaCluster.Status.BrokersState["101"].LeadershipDrainState
+			if test.expectedPhase == "" {
+				assert.Equal(t, test.drainState, drainState)
+				return
+			}
+			if assert.NotNil(t, drainState) {
+				assert.Equal(t, test.expectedPhase, drainState.Phase)
+				assert.Equal(t, test.leaderCount, drainState.LeaderCount)
+				assert.Equal(t, test.expectedDemotedPartitions, drainState.DemotedPartitions)
+			}
+		})
+	}
+}
+
+func TestReconcileDemotedReplicas(t *testing.T) {
+	t.Parallel()
+	demotedPartitions := map[string][]int32{"test-topic": {0, 1}}
+	testCases := []struct {
+		testName          string
+		drainState        *v1beta1.LeadershipDrainState
+		pods              []corev1.Pod
+		outOfSyncReplicas []int32
+		restoreExpected   bool
+		pendingPartitions map[string][]int32
+
+		expectedDemotedPartitions map[string][]int32
+	}{
+		{
+			testName:   "no demoted partitions",
+			drainState: &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDrained},
+		},
+		{
+			testName:   "broker is still being drained",
+			drainState: &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDraining, DemotedPartitions: demotedPartitions},
+
+			expectedDemotedPartitions: demotedPartitions,
+		},
+		{
+			testName:   "restarting broker keeps its demoted partitions",
+			drainState: &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDrained, DemotedPartitions: demotedPartitions},
+			pods: []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "kafka-101", Labels: map[string]string{"brokerId": "101"}, DeletionTimestamp: &metav1.Time{Time: time.Now()}}},
+			},
+			outOfSyncReplicas: []int32{},
+
+			expectedDemotedPartitions: demotedPartitions,
+		},
+		{
+			testName:          "broker out of the ISR keeps its demoted partitions",
+			drainState:        &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDrainTimedOut, DemotedPartitions: demotedPartitions},
+			pods:              []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "kafka-101", Labels: map[string]string{"brokerId": "101"}}}},
+			outOfSyncReplicas: []int32{101},
+
+			expectedDemotedPartitions: demotedPartitions,
+		},
+		{
+			testName:          "replica order is restored for the broker back in the ISR",
+			drainState:        &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDrained, DemotedPartitions: demotedPartitions},
+			pods:              []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "kafka-101", Labels: map[string]string{"brokerId": "101"}}}},
+			outOfSyncReplicas: []int32{},
+			restoreExpected:   true,
+		},
+		{
+			testName:          "partitions of topics being reassigned are kept until the reassignment is completed",
+			drainState:        &v1beta1.LeadershipDrainState{Phase: v1beta1.LeadershipDrained, DemotedPartitions: demotedPartitions},
+			pods:              []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "kafka-101", Labels: map[string]string{"brokerId": "101"}}}},
+			outOfSyncReplicas: []int32{},
+			restoreExpected:   true,
+			pendingPartitions: map[string][]int32{"test-topic": {0}},
+
+			expectedDemotedPartitions: map[string][]int32{"test-topic": {0}},
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(mockCtrl)
+			mockSubResourceClient := mocks.NewMockSubResourceClient(mockCtrl)
+			mockKafkaClientProvider := new(kafkaclient.MockedProvider)
+			kafkaCluster := &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+				Status: v1beta1.KafkaClusterStatus{
+					BrokersState: map[string]v1beta1.BrokerState{"101": {LeadershipDrainState: test.drainState}},
+				},
+			}
+			r := New(mockClient, nil, kafkaCluster, mockKafkaClientProvider)
+
+			mockClient.EXPECT().List(
+				context.Background(),
+				gomock.AssignableToTypeOf(&corev1.PodList{}),
+				client.InNamespace("kafka"),
+				gomock.Any(),
+			).Do(func(ctx context.Context, list *corev1.PodList, opts ...client.ListOption) {
+				list.Items = test.pods
+			}).Return(nil).AnyTimes()
+			mockClient.EXPECT().Status().Return(mockSubResourceClient).AnyTimes()
+			mockSubResourceClient.EXPECT().Update(context.Background(), gomock.AssignableToTypeOf(&v1beta1.KafkaCluster{})).Return(nil).AnyTimes()
+
+			mockedKafkaClient := mocks.NewMockKafkaClient(mockCtrl)
+			mockedKafkaClient.EXPECT().AllOfflineReplicas().Return([]int32{}, nil).AnyTimes()
+			mockedKafkaClient.EXPECT().OutOfSyncReplicas().Return(test.outOfSyncReplicas, nil).AnyTimes()
+			if test.restoreExpected {
+				mockedKafkaClient.EXPECT().RestorePreferredReplica(int32(101), demotedPartitions).Return(2, test.pendingPartitions, nil)
+			}
+			mockKafkaClientProvider.On("NewFromCluster", mockClient, kafkaCluster).Return(mockedKafkaClient, func() {}, nil)
+
+			err := r.reconcileDemotedReplicas(context.Background(), logr.Discard())
+
+			assert.Nil(t, err)
+			drainState := r.KafkaCluster.Status.BrokersState["101"].LeadershipDrainState
+			if assert.NotNil(t, drainState) {
+				assert.Equal(t, test.drainState.Phase, drainState.Phase)
+				assert.Equal(t, test.expectedDemotedPartitions, drainState.DemotedPartitions)
+			}
+		})
+	}
+}
@@ -94,6 +94,20 @@ func (mr *MockKafkaClientMockRecorder) AlterPerBrokerConfig(arg0, arg1, arg2 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AlterPerBrokerConfig", reflect.TypeOf((*MockKafkaClient)(nil).AlterPerBrokerConfig), arg0, arg1, arg2)
 }
 
+// AlterUserQuotas mocks base method.
+func (m *MockKafkaClient) AlterUserQuotas(user string, quotas *v1alpha1.UserQuotas) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AlterUserQuotas", user, quotas)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AlterUserQuotas indicates an expected call of AlterUserQuotas.
+func (mr *MockKafkaClientMockRecorder) AlterUserQuotas(user, quotas interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AlterUserQuotas", reflect.TypeOf((*MockKafkaClient)(nil).AlterUserQuotas), user, quotas)
+}
+
 // Brokers mocks base method.
 func (m *MockKafkaClient) Brokers() map[int32]string {
 	m.ctrl.T.Helper()
@@ -122,32 +136,48 @@ func (mr *MockKafkaClientMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockKafkaClient)(nil).Close))
 }
 
-// CreateTopic mocks base method.
-func (m *MockKafkaClient) CreateTopic(arg0 *kafkaclient.CreateTopicOptions) error {
+// ClusterID mocks base method.
+func (m *MockKafkaClient) ClusterID() (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTopic", arg0)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ClusterID")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// CreateTopic indicates an expected call of CreateTopic.
-func (mr *MockKafkaClientMockRecorder) CreateTopic(arg0 interface{}) *gomock.Call {
+// ClusterID indicates an expected call of ClusterID.
+func (mr *MockKafkaClientMockRecorder) ClusterID() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTopic", reflect.TypeOf((*MockKafkaClient)(nil).CreateTopic), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterID", reflect.TypeOf((*MockKafkaClient)(nil).ClusterID))
 }
 
-// CreateUserACLs mocks base method.
-func (m *MockKafkaClient) CreateUserACLs(arg0 v1alpha1.KafkaAccessType, arg1 v1alpha1.KafkaPatternType, arg2, arg3 string) error {
+// ConsumerGroupLag mocks base method.
+func (m *MockKafkaClient) ConsumerGroupLag(group string) (map[string]map[int32]int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUserACLs", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "ConsumerGroupLag", group)
+	ret0, _ := ret[0].(map[string]map[int32]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumerGroupLag indicates an expected call of ConsumerGroupLag.
+func (mr *MockKafkaClientMockRecorder) ConsumerGroupLag(group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumerGroupLag", reflect.TypeOf((*MockKafkaClient)(nil).ConsumerGroupLag), group)
+}
+
+// CreateTopic mocks base method.
+func (m *MockKafkaClient) CreateTopic(arg0 *kafkaclient.CreateTopicOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTopic", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CreateUserACLs indicates an expected call of CreateUserACLs.
-func (mr *MockKafkaClientMockRecorder) CreateUserACLs(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+// CreateTopic indicates an expected call of CreateTopic.
+func (mr *MockKafkaClientMockRecorder) CreateTopic(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserACLs", reflect.TypeOf((*MockKafkaClient)(nil).CreateUserACLs), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTopic", reflect.TypeOf((*MockKafkaClient)(nil).CreateTopic), arg0)
 }
 
 // DeleteTopic mocks base method.
@@ -164,18 +194,18 @@ func (mr *MockKafkaClientMockRecorder) DeleteTopic(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTopic", reflect.TypeOf((*MockKafkaClient)(nil).DeleteTopic), arg0, arg1)
 }
 
-// DeleteUserACLs mocks base method.
-func (m *MockKafkaClient) DeleteUserACLs(arg0 string, arg1 v1alpha1.KafkaPatternType) error {
+// DeleteUserScramCredentials mocks base method.
+func (m *MockKafkaClient) DeleteUserScramCredentials(user string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUserACLs", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteUserScramCredentials", user)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteUserACLs indicates an expected call of DeleteUserACLs.
-func (mr *MockKafkaClientMockRecorder) DeleteUserACLs(arg0, arg1 interface{}) *gomock.Call {
+// DeleteUserScramCredentials indicates an expected call of DeleteUserScramCredentials.
+func (mr *MockKafkaClientMockRecorder) DeleteUserScramCredentials(user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserACLs", reflect.TypeOf((*MockKafkaClient)(nil).DeleteUserACLs), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserScramCredentials", reflect.TypeOf((*MockKafkaClient)(nil).DeleteUserScramCredentials), user)
 }
 
 // DescribeCluster mocks base method.
@@ -209,6 +239,21 @@ func (mr *MockKafkaClientMockRecorder) DescribeClusterWideConfig() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeClusterWideConfig", reflect.TypeOf((*MockKafkaClient)(nil).DescribeClusterWideConfig))
 }
 
+// DescribeConsumerGroup mocks base method.
+func (m *MockKafkaClient) DescribeConsumerGroup(group string) (*kafkaclient.ConsumerGroupDescription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeConsumerGroup", group)
+	ret0, _ := ret[0].(*kafkaclient.ConsumerGroupDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeConsumerGroup indicates an expected call of DescribeConsumerGroup.
+func (mr *MockKafkaClientMockRecorder) DescribeConsumerGroup(group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeConsumerGroup", reflect.TypeOf((*MockKafkaClient)(nil).DescribeConsumerGroup), group)
+}
+
 // DescribePerBrokerConfig mocks base method.
 func (m *MockKafkaClient) DescribePerBrokerConfig(arg0 int32, arg1 []string) ([]*sarama.ConfigEntry, error) {
 	m.ctrl.T.Helper()
@@ -239,6 +284,51 @@ func (mr *MockKafkaClientMockRecorder) DescribeTopic(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeTopic", reflect.TypeOf((*MockKafkaClient)(nil).DescribeTopic), arg0)
 }
 
+// DescribeTopicConfig mocks base method.
+func (m *MockKafkaClient) DescribeTopicConfig(topic string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeTopicConfig", topic)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeTopicConfig indicates an expected call of DescribeTopicConfig.
+func (mr *MockKafkaClientMockRecorder) DescribeTopicConfig(topic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeTopicConfig", reflect.TypeOf((*MockKafkaClient)(nil).DescribeTopicConfig), topic)
+}
+
+// DescribeUserQuotas mocks base method.
+func (m *MockKafkaClient) DescribeUserQuotas(user string) (*v1alpha1.UserQuotas, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeUserQuotas", user)
+	ret0, _ := ret[0].(*v1alpha1.UserQuotas)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeUserQuotas indicates an expected call of DescribeUserQuotas.
+func (mr *MockKafkaClientMockRecorder) DescribeUserQuotas(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeUserQuotas", reflect.TypeOf((*MockKafkaClient)(nil).DescribeUserQuotas), user)
+}
+
+// ElectLeaders mocks base method.
+func (m *MockKafkaClient) ElectLeaders(partitions map[string][]int32) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ElectLeaders", partitions)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ElectLeaders indicates an expected call of ElectLeaders.
+func (mr *MockKafkaClientMockRecorder) ElectLeaders(partitions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ElectLeaders", reflect.TypeOf((*MockKafkaClient)(nil).ElectLeaders), partitions)
+}
+
 // EnsurePartitionCount mocks base method.
 func (m *MockKafkaClient) EnsurePartitionCount(arg0 string, arg1 int32) (bool, error) {
 	m.ctrl.T.Helper()
@@ -254,6 +344,36 @@ func (mr *MockKafkaClientMockRecorder) EnsurePartitionCount(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsurePartitionCount", reflect.TypeOf((*MockKafkaClient)(nil).EnsurePartitionCount), arg0, arg1)
 }
 
+// EnsureReplicaPlacement mocks base method.
+func (m *MockKafkaClient) EnsureReplicaPlacement(topic string, placement kafkaclient.ReplicaPlacement) (kafkaclient.PartitionReassignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureReplicaPlacement", topic, placement)
+	ret0, _ := ret[0].(kafkaclient.PartitionReassignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureReplicaPlacement indicates an expected call of EnsureReplicaPlacement.
+func (mr *MockKafkaClientMockRecorder) EnsureReplicaPlacement(topic, placement interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureReplicaPlacement", reflect.TypeOf((*MockKafkaClient)(nil).EnsureReplicaPlacement), topic, placement)
+}
+
+// EnsureReplicationFactor mocks base method.
+func (m *MockKafkaClient) EnsureReplicationFactor(topic string, desired int16, placement *kafkaclient.ReplicaPlacement) (kafkaclient.PartitionReassignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureReplicationFactor", topic, desired, placement)
+	ret0, _ := ret[0].(kafkaclient.PartitionReassignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureReplicationFactor indicates an expected call of EnsureReplicationFactor.
+func (mr *MockKafkaClientMockRecorder) EnsureReplicationFactor(topic, desired, placement interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureReplicationFactor", reflect.TypeOf((*MockKafkaClient)(nil).EnsureReplicationFactor), topic, desired, placement)
+}
+
 // EnsureTopicConfig mocks base method.
 func (m *MockKafkaClient) EnsureTopicConfig(arg0 string, arg1 map[string]*string) error {
 	m.ctrl.T.Helper()
@@ -268,6 +388,20 @@ func (mr *MockKafkaClientMockRecorder) EnsureTopicConfig(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureTopicConfig", reflect.TypeOf((*MockKafkaClient)(nil).EnsureTopicConfig), arg0, arg1)
 }
 
+// EnsureUserScramCredentials mocks base method.
+func (m *MockKafkaClient) EnsureUserScramCredentials(user string, authentication v1alpha1.UserAuthenticationType, password []byte, update bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureUserScramCredentials", user, authentication, password, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureUserScramCredentials indicates an expected call of EnsureUserScramCredentials.
+func (mr *MockKafkaClientMockRecorder) EnsureUserScramCredentials(user, authentication, password, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureUserScramCredentials", reflect.TypeOf((*MockKafkaClient)(nil).EnsureUserScramCredentials), user, authentication, password, update)
+}
+
 // GetTopic mocks base method.
 func (m *MockKafkaClient) GetTopic(arg0 string) (*sarama.TopicDetail, error) {
 	m.ctrl.T.Helper()
@@ -283,6 +417,36 @@ func (mr *MockKafkaClientMockRecorder) GetTopic(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopic", reflect.TypeOf((*MockKafkaClient)(nil).GetTopic), arg0)
 }
 
+// LeaderPartitionCount mocks base method.
+func (m *MockKafkaClient) LeaderPartitionCount(brokerID int32) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaderPartitionCount", brokerID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeaderPartitionCount indicates an expected call of LeaderPartitionCount.
+func (mr *MockKafkaClientMockRecorder) LeaderPartitionCount(brokerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaderPartitionCount", reflect.TypeOf((*MockKafkaClient)(nil).LeaderPartitionCount), brokerID)
+}
+
+// ListConsumerGroups mocks base method.
+func (m *MockKafkaClient) ListConsumerGroups() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConsumerGroups")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConsumerGroups indicates an expected call of ListConsumerGroups.
+func (mr *MockKafkaClientMockRecorder) ListConsumerGroups() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConsumerGroups", reflect.TypeOf((*MockKafkaClient)(nil).ListConsumerGroups))
+}
+
 // ListTopics mocks base method.
 func (m *MockKafkaClient) ListTopics() (map[string]sarama.TopicDetail, error) {
 	m.ctrl.T.Helper()
@@ -299,18 +463,34 @@ func (mr *MockKafkaClientMockRecorder) ListTopics() *gomock.Call {
 }
 
 // ListUserACLs mocks base method.
-func (m *MockKafkaClient) ListUserACLs() ([]sarama.ResourceAcls, error) {
+func (m *MockKafkaClient) ListUserACLs(dn string) ([]sarama.ResourceAcls, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListUserACLs")
+	ret := m.ctrl.Call(m, "ListUserACLs", dn)
 	ret0, _ := ret[0].([]sarama.ResourceAcls)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListUserACLs indicates an expected call of ListUserACLs.
-func (mr *MockKafkaClientMockRecorder) ListUserACLs() *gomock.Call {
+func (mr *MockKafkaClientMockRecorder) ListUserACLs(dn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserACLs", reflect.TypeOf((*MockKafkaClient)(nil).ListUserACLs))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserACLs", reflect.TypeOf((*MockKafkaClient)(nil).ListUserACLs), dn)
+}
+
+// MetadataVersion mocks base method.
+func (m *MockKafkaClient) MetadataVersion() (int16, int16, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MetadataVersion")
+	ret0, _ := ret[0].(int16)
+	ret1, _ := ret[1].(int16)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MetadataVersion indicates an expected call of MetadataVersion.
+func (mr *MockKafkaClientMockRecorder) MetadataVersion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MetadataVersion", reflect.TypeOf((*MockKafkaClient)(nil).MetadataVersion))
 }
 
 // NumBrokers mocks base method.
@@ -356,16 +536,123 @@ func (mr *MockKafkaClientMockRecorder) OutOfSyncReplicas() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OutOfSyncReplicas", reflect.TypeOf((*MockKafkaClient)(nil).OutOfSyncReplicas))
 }
 
-// TopicMetaToStatus mocks base method.
-func (m *MockKafkaClient) TopicMetaToStatus(meta *sarama.TopicMetadata) *v1alpha1.KafkaTopicStatus {
+// PreferredLeaderPartitions mocks base method.
+func (m *MockKafkaClient) PreferredLeaderPartitions(brokerID int32) (map[string][]int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreferredLeaderPartitions", brokerID)
+	ret0, _ := ret[0].(map[string][]int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreferredLeaderPartitions indicates an expected call of PreferredLeaderPartitions.
+func (mr *MockKafkaClientMockRecorder) PreferredLeaderPartitions(brokerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreferredLeaderPartitions", reflect.TypeOf((*MockKafkaClient)(nil).PreferredLeaderPartitions), brokerID)
+}
+
+// PreferredReplicaPartitions mocks base method.
+func (m *MockKafkaClient) PreferredReplicaPartitions(brokerID int32) (map[string][]int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreferredReplicaPartitions", brokerID)
+	ret0, _ := ret[0].(map[string][]int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreferredReplicaPartitions indicates an expected call of PreferredReplicaPartitions.
+func (mr *MockKafkaClientMockRecorder) PreferredReplicaPartitions(brokerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreferredReplicaPartitions", reflect.TypeOf((*MockKafkaClient)(nil).PreferredReplicaPartitions), brokerID)
+}
+
+// ReconcileUserACLs mocks base method.
+func (m *MockKafkaClient) ReconcileUserACLs(dn string, grants []v1alpha1.UserTopicGrant, acls []v1alpha1.UserACL) ([]string, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileUserACLs", dn, grants, acls)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReconcileUserACLs indicates an expected call of ReconcileUserACLs.
+func (mr *MockKafkaClientMockRecorder) ReconcileUserACLs(dn, grants, acls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileUserACLs", reflect.TypeOf((*MockKafkaClient)(nil).ReconcileUserACLs), dn, grants, acls)
+}
+
+// ResetConsumerGroupOffsets mocks base method.
+func (m *MockKafkaClient) ResetConsumerGroupOffsets(group string, reset *v1alpha1.ConsumerGroupOffsetReset) (map[string]map[int32]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetConsumerGroupOffsets", group, reset)
+	ret0, _ := ret[0].(map[string]map[int32]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetConsumerGroupOffsets indicates an expected call of ResetConsumerGroupOffsets.
+func (mr *MockKafkaClientMockRecorder) ResetConsumerGroupOffsets(group, reset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetConsumerGroupOffsets", reflect.TypeOf((*MockKafkaClient)(nil).ResetConsumerGroupOffsets), group, reset)
+}
+
+// RestorePreferredReplica mocks base method.
+func (m *MockKafkaClient) RestorePreferredReplica(brokerID int32, partitions map[string][]int32) (int, map[string][]int32, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TopicMetaToStatus", meta)
-	ret0, _ := ret[0].(*v1alpha1.KafkaTopicStatus)
+	ret := m.ctrl.Call(m, "RestorePreferredReplica", brokerID, partitions)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(map[string][]int32)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RestorePreferredReplica indicates an expected call of RestorePreferredReplica.
+func (mr *MockKafkaClientMockRecorder) RestorePreferredReplica(brokerID, partitions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePreferredReplica", reflect.TypeOf((*MockKafkaClient)(nil).RestorePreferredReplica), brokerID, partitions)
+}
+
+// TopicSizes mocks base method.
+func (m *MockKafkaClient) TopicSizes() (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopicSizes")
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopicSizes indicates an expected call of TopicSizes.
+func (mr *MockKafkaClientMockRecorder) TopicSizes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopicSizes", reflect.TypeOf((*MockKafkaClient)(nil).TopicSizes))
+}
+
+// UnsafePartitionsForRestart mocks base method.
+func (m *MockKafkaClient) UnsafePartitionsForRestart(brokerID int32) ([]kafkaclient.UnsafePartition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsafePartitionsForRestart", brokerID)
+	ret0, _ := ret[0].([]kafkaclient.UnsafePartition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnsafePartitionsForRestart indicates an expected call of UnsafePartitionsForRestart.
+func (mr *MockKafkaClientMockRecorder) UnsafePartitionsForRestart(brokerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsafePartitionsForRestart", reflect.TypeOf((*MockKafkaClient)(nil).UnsafePartitionsForRestart), brokerID)
+}
+
+// UpdateMetadataVersion mocks base method.
+func (m *MockKafkaClient) UpdateMetadataVersion(level int16) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMetadataVersion", level)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// TopicMetaToStatus indicates an expected call of TopicMetaToStatus.
-func (mr *MockKafkaClientMockRecorder) TopicMetaToStatus(meta interface{}) *gomock.Call {
+// UpdateMetadataVersion indicates an expected call of UpdateMetadataVersion.
+func (mr *MockKafkaClientMockRecorder) UpdateMetadataVersion(level interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopicMetaToStatus", reflect.TypeOf((*MockKafkaClient)(nil).TopicMetaToStatus), meta)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadataVersion", reflect.TypeOf((*MockKafkaClient)(nil).UpdateMetadataVersion), level)
 }
@@ -48,6 +48,10 @@ func (r *Reconciler) pod(id int32, brokerConfig *v1beta1.BrokerConfig, pvcs []co
 		if _, ok := r.KafkaCluster.Status.ListenerStatuses.ExternalListeners[eListener.Name]; !ok {
 			continue
 		}
+		// Dedicated controllers are not reachable from outside of the Kubernetes cluster
+		if brokerConfig.IsControllerOnlyNode() {
+			break
+		}
 		kafkaBrokerContainerPorts = append(kafkaBrokerContainerPorts, corev1.ContainerPort{
 			Name:          strings.ReplaceAll(eListener.GetListenerServiceName(), "_", "-"),
 			ContainerPort: eListener.ContainerPort,
@@ -82,7 +86,38 @@ func (r *Reconciler) pod(id int32, brokerConfig *v1beta1.BrokerConfig, pvcs []co
 		}
 	}
 
-	dataVolume, dataVolumeMount := generateDataVolumeAndVolumeMount(pvcs, brokerConfig.StorageConfigs)
+	// Dedicated controllers get no persistent data volumes, only the one of their metadata log
+	storageConfigs := getNodeStorageConfigs(brokerConfig)
+	if brokerConfig.IsControllerOnlyNode() {
+		pvcs = getStoragePvcs(pvcs, storageConfigs)
+	}
+	dataVolume, dataVolumeMount := generateDataVolumeAndVolumeMount(pvcs, storageConfigs)
+
+	defaultEnvVars := []corev1.EnvVar{
+		{
+			Name:  "CLASSPATH",
+			Value: "/opt/kafka/libs/extensions/*",
+		},
+		{
+			Name:  "KAFKA_OPTS",
+			Value: "-javaagent:/opt/jmx-exporter/jmx_prometheus.jar=9020:/etc/jmx-exporter/config.yaml",
+		},
+		{
+			Name: "ENVOY_SIDECAR_STATUS",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: `metadata.annotations['sidecar.istio.io/status']`,
+				},
+			},
+		},
+	}
+	// In KRaft mode the storage has to be formatted with the cluster id before the broker starts
+	if kRaft, _ := getNodeMetadataMode(r.KafkaCluster, brokerConfig); kRaft {
+		defaultEnvVars = append(defaultEnvVars, corev1.EnvVar{
+			Name:  "CLUSTER_ID",
+			Value: r.KafkaCluster.Status.ClusterID,
+		})
+	}
 
 	// TODO remove this bash envoy sidecar checker script once sidecar precedence becomes available to Kubernetes(baluchicken)
 	command := []string{"bash", "-c", envoySidecarScript}
@@ -101,7 +136,7 @@ func (r *Reconciler) pod(id int32, brokerConfig *v1beta1.BrokerConfig, pvcs []co
 			Containers: append([]corev1.Container{
 				{
 					Name:  "kafka",
-					Image: util.GetBrokerImage(brokerConfig, r.KafkaCluster.Spec.GetClusterImage()),
+					Image: r.getBrokerImage(id, brokerConfig),
 					Lifecycle: &corev1.Lifecycle{
 						PreStop: &corev1.LifecycleHandler{
 							Exec: &corev1.ExecAction{
@@ -121,26 +156,8 @@ fi`},
 						},
 					},
 					SecurityContext: brokerConfig.SecurityContext,
-					Env: generateEnvConfig(brokerConfig, []corev1.EnvVar{
-						{
-							Name:  "CLASSPATH",
-							Value: "/opt/kafka/libs/extensions/*",
-						},
-						{
-							Name:  "KAFKA_OPTS",
-							Value: "-javaagent:/opt/jmx-exporter/jmx_prometheus.jar=9020:/etc/jmx-exporter/config.yaml",
-						},
-						{
-							Name: "ENVOY_SIDECAR_STATUS",
-							ValueFrom: &corev1.EnvVarSource{
-								FieldRef: &corev1.ObjectFieldSelector{
-									FieldPath: `metadata.annotations['sidecar.istio.io/status']`,
-								},
-							},
-						},
-					}),
-
-					Command: command,
+					Env:             generateEnvConfig(brokerConfig, defaultEnvVars),
+					Command:         command,
 					Ports: append(kafkaBrokerContainerPorts, []corev1.ContainerPort{
 						{
 							ContainerPort: 9020,
@@ -345,6 +362,20 @@ func generatePodAntiAffinity(clusterName string, hardRuleEnabled bool) *corev1.P
 	return &podAntiAffinity
 }
 
+// getStoragePvcs returns the pvcs which belong to the given storage configs
+func getStoragePvcs(pvcs []corev1.PersistentVolumeClaim, storageConfigs []v1beta1.StorageConfig) []corev1.PersistentVolumeClaim {
+	var storagePvcs []corev1.PersistentVolumeClaim
+	for _, pvc := range pvcs {
+		for _, storage := range storageConfigs {
+			if storage.PvcSpec != nil && storage.MountPath == pvc.Annotations["mountPath"] {
+				storagePvcs = append(storagePvcs, pvc)
+				break
+			}
+		}
+	}
+	return storagePvcs
+}
+
 func generateDataVolumeAndVolumeMount(pvcs []corev1.PersistentVolumeClaim, storageConfigs []v1beta1.StorageConfig) ([]corev1.Volume, []corev1.VolumeMount) {
 	var volumes []corev1.Volume
 	var volumeMounts []corev1.VolumeMount
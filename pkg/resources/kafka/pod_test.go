@@ -185,3 +185,25 @@ func Test_generateEnvConfig(t *testing.T) {
 		t.Error("Expected:", expected, "Got:", result)
 	}
 }
+
+func TestGetStoragePvcs(t *testing.T) {
+	pvc := func(name, mountPath string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{"mountPath": mountPath}}}
+	}
+	pvcs := []corev1.PersistentVolumeClaim{pvc("kafka-0-storage-0", "/kafka-logs"), pvc("kafka-0-storage-1", "/kafka-data")}
+
+	// Dedicated controllers only keep the persistent volume of their metadata log
+	controllerConfig := &v1beta1.BrokerConfig{
+		ProcessRole: v1beta1.ProcessRoleController,
+		StorageConfigs: []v1beta1.StorageConfig{
+			{MountPath: "/kafka-metadata", EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			{MountPath: "/kafka-logs", PvcSpec: &corev1.PersistentVolumeClaimSpec{}},
+			{MountPath: "/kafka-data", PvcSpec: &corev1.PersistentVolumeClaimSpec{}},
+		},
+	}
+	result := getStoragePvcs(pvcs, getNodeStorageConfigs(controllerConfig))
+	expected := []corev1.PersistentVolumeClaim{pvc("kafka-0-storage-0", "/kafka-logs")}
+	if !reflect.DeepEqual(result, expected) {
+		t.Error("Expected:", expected, "Got:", result)
+	}
+}
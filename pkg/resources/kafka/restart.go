@@ -0,0 +1,126 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// isBrokerRestartRequested returns true if the broker with the given id is in the scope of the restart request of the cluster.
+// Without the broker id and rack annotations every broker is requested to be restarted.
+func isBrokerRestartRequested(kafkaCluster *v1beta1.KafkaCluster, brokerID int32, log logr.Logger) bool {
+	annotations := kafkaCluster.GetAnnotations()
+	if annotations[v1beta1.RestartAnnotationKey] == "" {
+		return false
+	}
+
+	if brokerIDs, ok := annotations[v1beta1.RestartBrokersAnnotationKey]; ok {
+		listed := false
+		for _, id := range strings.Split(brokerIDs, ",") {
+			parsedID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 32)
+			if err != nil {
+				log.Error(err, "invalid broker id in the restart request", "brokerIds", brokerIDs)
+				continue
+			}
+			if int32(parsedID) == brokerID {
+				listed = true
+				break
+			}
+		}
+		if !listed {
+			return false
+		}
+	}
+
+	if rack, ok := annotations[v1beta1.RestartRackAnnotationKey]; ok {
+		return getBrokerAzMap(kafkaCluster)[brokerID] == rack
+	}
+	return true
+}
+
+// setRestartAnnotation annotates the desired broker pod with the restart request of the cluster if the broker is requested
+// to be restarted which makes the rolling upgrade restart the pod. Otherwise the annotation of the current pod is kept.
+func (r *Reconciler) setRestartAnnotation(desiredPod, currentPod *corev1.Pod, log logr.Logger) {
+	brokerID, err := strconv.ParseInt(desiredPod.Labels[v1beta1.BrokerIdLabelKey], 10, 32)
+	if err != nil {
+		log.Error(err, "could not parse broker id of the pod")
+		return
+	}
+
+	var restartRequest string
+	if currentPod != nil {
+		restartRequest = currentPod.GetAnnotations()[v1beta1.RestartAnnotationKey]
+	}
+	// A new pod is started anyway so it does not need to be restarted for the current request
+	if currentPod == nil || isBrokerRestartRequested(r.KafkaCluster, int32(brokerID), log) {
+		restartRequest = r.KafkaCluster.GetAnnotations()[v1beta1.RestartAnnotationKey]
+	}
+	if restartRequest == "" {
+		return
+	}
+
+	if desiredPod.Annotations == nil {
+		desiredPod.Annotations = make(map[string]string)
+	}
+	desiredPod.Annotations[v1beta1.RestartAnnotationKey] = restartRequest
+}
+
+// reconcileRestartRequest records the restart request of the cluster in the status once every requested broker has been restarted
+func (r *Reconciler) reconcileRestartRequest(ctx context.Context, log logr.Logger) error {
+	restartRequest := r.KafkaCluster.GetAnnotations()[v1beta1.RestartAnnotationKey]
+	if restartRequest == "" || restartRequest == r.KafkaCluster.Status.RollingUpgrade.LastRestartRequest {
+		return nil
+	}
+
+	podList := &corev1.PodList{}
+	matchingLabels := client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name))
+	err := r.Client.List(ctx, podList, client.ListOption(client.InNamespace(r.KafkaCluster.Namespace)), client.ListOption(matchingLabels))
+	if err != nil {
+		return errors.WrapIf(err, "failed to list kafka pods")
+	}
+
+	terminatingOrPendingPods := make(map[string]struct{})
+	for _, pod := range getPodsInTerminatingOrPendingState(podList.Items) {
+		terminatingOrPendingPods[pod.Name] = struct{}{}
+	}
+	for _, pod := range podList.Items {
+		brokerID, err := strconv.ParseInt(pod.Labels[v1beta1.BrokerIdLabelKey], 10, 32)
+		if err != nil || !isBrokerRestartRequested(r.KafkaCluster, int32(brokerID), log) {
+			continue
+		}
+		if _, ok := terminatingOrPendingPods[pod.Name]; ok || pod.GetAnnotations()[v1beta1.RestartAnnotationKey] != restartRequest {
+			log.V(1).Info("restart request is in progress", "restartRequest", restartRequest)
+			return nil
+		}
+	}
+
+	log.Info("every requested broker has been restarted", "restartRequest", restartRequest)
+	if err := k8sutil.UpdateLastRestartRequest(r.Client, r.KafkaCluster, restartRequest, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting last restart request failed")
+	}
+	return nil
+}
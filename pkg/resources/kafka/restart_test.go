@@ -0,0 +1,141 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestIsBrokerRestartRequested(t *testing.T) {
+	t.Parallel()
+	brokers := []v1beta1.Broker{
+		{Id: 101, ReadOnlyConfig: "broker.rack=az1"},
+		{Id: 102, ReadOnlyConfig: "broker.rack=az1"},
+		{Id: 201, ReadOnlyConfig: "broker.rack=az2"},
+	}
+	testCases := []struct {
+		testName          string
+		annotations       map[string]string
+		expectedRequested map[int32]bool
+	}{
+		{
+			testName:          "no restart request",
+			annotations:       nil,
+			expectedRequested: map[int32]bool{101: false, 102: false, 201: false},
+		},
+		{
+			testName:          "restart of all brokers",
+			annotations:       map[string]string{v1beta1.RestartAnnotationKey: "1"},
+			expectedRequested: map[int32]bool{101: true, 102: true, 201: true},
+		},
+		{
+			testName: "restart of listed brokers",
+			annotations: map[string]string{
+				v1beta1.RestartAnnotationKey:        "1",
+				v1beta1.RestartBrokersAnnotationKey: "101, 201,invalid",
+			},
+			expectedRequested: map[int32]bool{101: true, 102: false, 201: true},
+		},
+		{
+			testName: "restart of a rack",
+			annotations: map[string]string{
+				v1beta1.RestartAnnotationKey:     "1",
+				v1beta1.RestartRackAnnotationKey: "az1",
+			},
+			expectedRequested: map[int32]bool{101: true, 102: true, 201: false},
+		},
+		{
+			testName: "restart of listed brokers of a rack",
+			annotations: map[string]string{
+				v1beta1.RestartAnnotationKey:        "1",
+				v1beta1.RestartBrokersAnnotationKey: "102,201",
+				v1beta1.RestartRackAnnotationKey:    "az1",
+			},
+			expectedRequested: map[int32]bool{101: false, 102: true, 201: false},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			kafkaCluster := &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations},
+				Spec:       v1beta1.KafkaClusterSpec{Brokers: brokers},
+			}
+			for brokerID, expected := range test.expectedRequested {
+				assert.Equal(t, expected, isBrokerRestartRequested(kafkaCluster, brokerID, logr.Discard()), "broker %d", brokerID)
+			}
+		})
+	}
+}
+
+func TestSetRestartAnnotation(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		testName           string
+		annotations        map[string]string
+		currentPod         *corev1.Pod
+		expectedAnnotation string
+	}{
+		{
+			testName:           "no restart request",
+			currentPod:         &corev1.Pod{},
+			expectedAnnotation: "",
+		},
+		{
+			testName:           "new pod",
+			annotations:        map[string]string{v1beta1.RestartAnnotationKey: "2", v1beta1.RestartBrokersAnnotationKey: "1"},
+			currentPod:         nil,
+			expectedAnnotation: "2",
+		},
+		{
+			testName:           "requested broker",
+			annotations:        map[string]string{v1beta1.RestartAnnotationKey: "2"},
+			currentPod:         &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1beta1.RestartAnnotationKey: "1"}}},
+			expectedAnnotation: "2",
+		},
+		{
+			testName:           "broker not requested",
+			annotations:        map[string]string{v1beta1.RestartAnnotationKey: "2", v1beta1.RestartBrokersAnnotationKey: "1"},
+			currentPod:         &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1beta1.RestartAnnotationKey: "1"}}},
+			expectedAnnotation: "1",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			r := Reconciler{
+				Reconciler: resources.Reconciler{
+					KafkaCluster: &v1beta1.KafkaCluster{
+						ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations},
+						Spec:       v1beta1.KafkaClusterSpec{Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}}},
+					},
+				},
+			}
+			desiredPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1.BrokerIdLabelKey: "0"}}}
+
+			r.setRestartAnnotation(desiredPod, test.currentPod, logr.Discard())
+
+			assert.Equal(t, test.expectedAnnotation, desiredPod.GetAnnotations()[v1beta1.RestartAnnotationKey])
+		})
+	}
+}
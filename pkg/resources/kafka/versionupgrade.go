@@ -0,0 +1,229 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/util/kafka"
+)
+
+// pinProtocolVersion implements the first phase of the Kafka version upgrade: before any of the brokers is restarted
+// with a new image the protocol version is pinned to the one the brokers currently use, so that the restarted brokers
+// keep talking the old protocol while the rest of the cluster is rolled. Clusters whose images do not change are left as is.
+func (r *Reconciler) pinProtocolVersion(pods []corev1.Pod, log logr.Logger) error {
+	if r.KafkaCluster.Status.ProtocolVersion != "" {
+		return nil
+	}
+	changing, err := r.isBrokerImageChanging(pods)
+	if err != nil || !changing {
+		return err
+	}
+
+	var protocolVersion string
+	// KRaft nodes use the metadata.version feature which is only raised by the operator
+	if !r.KafkaCluster.IsKRaftMode() {
+		if protocolVersion, err = r.getEffectiveProtocolVersion(); err != nil {
+			return err
+		}
+	}
+	if protocolVersion == "" {
+		if protocolVersion, err = getLowestBrokerProtocolVersion(r.KafkaCluster); err != nil {
+			return errors.WrapIf(err, "could not determine the protocol version of the brokers")
+		}
+	}
+	if protocolVersion == "" {
+		return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("protocol version of the brokers is not known yet"),
+			"Kafka version upgrade in progress")
+	}
+
+	log.Info("pinning the protocol version of the brokers", "protocolVersion", protocolVersion)
+	if err := k8sutil.UpdateProtocolVersion(r.Client, r.KafkaCluster, protocolVersion, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting protocol version failed")
+	}
+	return nil
+}
+
+// isBrokerImageChanging returns whether any of the brokers runs a different image than the one it is going to be restarted with
+func (r *Reconciler) isBrokerImageChanging(pods []corev1.Pod) (bool, error) {
+	for i := range pods {
+		currentImage := getKafkaContainerImage(&pods[i])
+		if currentImage == "" {
+			continue
+		}
+		brokerID := pods[i].Labels[v1beta1.BrokerIdLabelKey]
+		for _, broker := range r.KafkaCluster.Spec.Brokers {
+			if strconv.Itoa(int(broker.Id)) != brokerID {
+				continue
+			}
+			brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
+			if err != nil {
+				return false, errors.WrapIf(err, "failed to reconcile resource")
+			}
+			if r.getBrokerImage(broker.Id, brokerConfig) != currentImage {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// getEffectiveProtocolVersion returns the lowest inter.broker.protocol.version the running brokers use,
+// or an empty string if none of them reports it
+func (r *Reconciler) getEffectiveProtocolVersion() (string, error) {
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return "", errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer close()
+
+	var lowestVersion string
+	for brokerID := range kClient.Brokers() {
+		configs, err := kClient.DescribePerBrokerConfig(brokerID, []string{kafka.KafkaConfigInterBrokerProtocolVersion})
+		if err != nil {
+			return "", errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not describe broker config", v1beta1.BrokerIdLabelKey, brokerID)
+		}
+		for _, config := range configs {
+			if config.Name != kafka.KafkaConfigInterBrokerProtocolVersion || config.Value == "" {
+				continue
+			}
+			version, err := kafka.GetProtocolVersion(config.Value)
+			if err != nil {
+				return "", errors.WrapIf(err, "could not determine the protocol version of the brokers")
+			}
+			if lowestVersion == "" {
+				lowestVersion = version
+				continue
+			}
+			if cmp, err := kafka.CompareProtocolVersions(version, lowestVersion); err == nil && cmp < 0 {
+				lowestVersion = version
+			}
+		}
+	}
+	return lowestVersion, nil
+}
+
+// reconcileProtocolVersion implements the second phase of the Kafka version upgrade: the brokers are rolled
+// with the new Kafka version while the protocol version stays pinned, and the protocol version is raised
+// only once every broker reports the new version and the cluster is healthy which rolls the brokers once more.
+// In KRaft mode the metadata.version feature of the cluster is raised instead without rolling the nodes.
+func (r *Reconciler) reconcileProtocolVersion(ctx context.Context, log logr.Logger) error {
+	currentVersion := r.KafkaCluster.Status.ProtocolVersion
+	// The protocol version is only pinned during a Kafka version upgrade
+	if currentVersion == "" {
+		return nil
+	}
+
+	lowestVersion, err := getLowestBrokerProtocolVersion(r.KafkaCluster)
+	if err != nil {
+		log.Error(err, "could not determine the protocol version of the brokers")
+		return nil
+	}
+	// The version of some of the brokers is not known yet
+	if lowestVersion == "" {
+		return nil
+	}
+
+	cmp, err := kafka.CompareProtocolVersions(lowestVersion, currentVersion)
+	if err != nil {
+		return errors.WrapIf(err, "could not compare protocol versions")
+	}
+	switch {
+	case cmp < 0:
+		log.Info("some of the brokers run an older Kafka version than the protocol version, downgrade is not supported",
+			"protocolVersion", currentVersion, "brokerVersion", lowestVersion)
+		return nil
+	case cmp == 0:
+		return nil
+	}
+
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer close()
+
+	if err := r.checkClusterHealth(ctx, kClient, errorfactory.ReconcileRollingUpgrade{}, "Kafka version upgrade in progress", log); err != nil {
+		return err
+	}
+
+	if r.KafkaCluster.IsKRaftMode() {
+		if err := raiseMetadataVersion(kClient, log); err != nil {
+			return err
+		}
+	}
+
+	log.Info("raising the protocol version of the brokers", "from", currentVersion, "to", lowestVersion)
+	if err := k8sutil.UpdateProtocolVersion(r.Client, r.KafkaCluster, lowestVersion, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting protocol version failed")
+	}
+	// KRaft nodes do not get the protocol version in their config
+	if r.KafkaCluster.IsKRaftMode() {
+		return nil
+	}
+	// The brokers have to be rolled with the new protocol version
+	return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("protocol version raised"), "Kafka version upgrade in progress",
+		"protocolVersion", lowestVersion)
+}
+
+// raiseMetadataVersion raises the metadata.version feature of the KRaft cluster to the highest level every broker supports
+func raiseMetadataVersion(kClient kafkaclient.KafkaClient, log logr.Logger) error {
+	finalized, supported, err := kClient.MetadataVersion()
+	if err != nil {
+		return err
+	}
+	if finalized >= supported {
+		return nil
+	}
+	log.Info("raising the metadata version of the KRaft cluster", "from", finalized, "to", supported)
+	return kClient.UpdateMetadataVersion(supported)
+}
+
+// getLowestBrokerProtocolVersion returns the lowest major.minor Kafka version the brokers report,
+// or an empty string if the version of some of the brokers is not known yet
+func getLowestBrokerProtocolVersion(kafkaCluster *v1beta1.KafkaCluster) (string, error) {
+	var lowestVersion string
+	for _, broker := range kafkaCluster.Spec.Brokers {
+		brokerState, ok := kafkaCluster.Status.BrokersState[strconv.Itoa(int(broker.Id))]
+		if !ok || brokerState.Version == "" {
+			return "", nil
+		}
+		version, err := kafka.GetProtocolVersion(brokerState.Version)
+		if err != nil {
+			return "", err
+		}
+		if lowestVersion == "" {
+			lowestVersion = version
+			continue
+		}
+		cmp, err := kafka.CompareProtocolVersions(version, lowestVersion)
+		if err != nil {
+			return "", err
+		}
+		if cmp < 0 {
+			lowestVersion = version
+		}
+	}
+	return lowestVersion, nil
+}
@@ -0,0 +1,223 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/IBM/sarama"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka/mocks"
+)
+
+func TestPinProtocolVersion(t *testing.T) {
+	t.Parallel()
+	const (
+		oldImage = "ghcr.io/banzaicloud/kafka:2.13-3.4.1"
+		newImage = "ghcr.io/banzaicloud/kafka:2.13-3.5.1"
+	)
+	kafkaPod := func(brokerID, image string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafka-" + brokerID, Labels: map[string]string{"brokerId": brokerID}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "kafka", Image: image}}},
+		}
+	}
+
+	testCases := []struct {
+		testName                string
+		kRaft                   bool
+		protocolVersion         string
+		pods                    []corev1.Pod
+		brokerProtocolVersions  map[int32]string
+		expectedProtocolVersion string
+		expectedErr             bool
+	}{
+		{
+			testName:                "protocol version is already pinned",
+			protocolVersion:         "3.3",
+			pods:                    []corev1.Pod{kafkaPod("0", oldImage), kafkaPod("1", oldImage)},
+			expectedProtocolVersion: "3.3",
+		},
+		{
+			testName:                "existing cluster whose image does not change is not pinned",
+			pods:                    []corev1.Pod{kafkaPod("0", newImage), kafkaPod("1", newImage)},
+			expectedProtocolVersion: "",
+		},
+		{
+			testName:                "protocol version is pinned from the config of the brokers",
+			pods:                    []corev1.Pod{kafkaPod("0", oldImage), kafkaPod("1", newImage)},
+			brokerProtocolVersions:  map[int32]string{0: "3.3-IV3", 1: "3.4-IV0"},
+			expectedProtocolVersion: "3.3",
+		},
+		{
+			testName:                "brokers without protocol version config are pinned to their version",
+			pods:                    []corev1.Pod{kafkaPod("0", oldImage), kafkaPod("1", oldImage)},
+			brokerProtocolVersions:  map[int32]string{0: "", 1: ""},
+			expectedProtocolVersion: "3.4",
+		},
+		{
+			testName:                "KRaft nodes are pinned to their version",
+			kRaft:                   true,
+			pods:                    []corev1.Pod{kafkaPod("0", oldImage), kafkaPod("1", oldImage)},
+			expectedProtocolVersion: "3.4",
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(mockCtrl)
+			mockSubResourceClient := mocks.NewMockSubResourceClient(mockCtrl)
+			mockKafkaClientProvider := new(kafkaclient.MockedProvider)
+			kafkaCluster := &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+				Spec: v1beta1.KafkaClusterSpec{
+					KRaftMode:    test.kRaft,
+					ClusterImage: newImage,
+					Brokers:      []v1beta1.Broker{{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{}}, {Id: 1, BrokerConfig: &v1beta1.BrokerConfig{}}},
+				},
+				Status: v1beta1.KafkaClusterStatus{
+					ProtocolVersion: test.protocolVersion,
+					BrokersState: map[string]v1beta1.BrokerState{
+						"0": {Version: "3.4.1"},
+						"1": {Version: "3.4.1"},
+					},
+				},
+			}
+			r := New(mockClient, nil, kafkaCluster, mockKafkaClientProvider)
+
+			mockClient.EXPECT().Status().Return(mockSubResourceClient).AnyTimes()
+			mockSubResourceClient.EXPECT().Update(context.Background(), gomock.AssignableToTypeOf(&v1beta1.KafkaCluster{})).Return(nil).AnyTimes()
+
+			mockedKafkaClient := mocks.NewMockKafkaClient(mockCtrl)
+			brokers := make(map[int32]string, len(test.brokerProtocolVersions))
+			for brokerID, version := range test.brokerProtocolVersions {
+				brokers[brokerID] = ""
+				mockedKafkaClient.EXPECT().DescribePerBrokerConfig(brokerID, []string{"inter.broker.protocol.version"}).
+					Return([]*sarama.ConfigEntry{{Name: "inter.broker.protocol.version", Value: version}}, nil)
+			}
+			mockedKafkaClient.EXPECT().Brokers().Return(brokers).AnyTimes()
+			mockKafkaClientProvider.On("NewFromCluster", mockClient, kafkaCluster).Return(mockedKafkaClient, func() {}, nil)
+
+			err := r.pinProtocolVersion(test.pods, logr.Discard())
+
+			assert.Equal(t, test.expectedErr, err != nil)
+			assert.Equal(t, test.expectedProtocolVersion, r.KafkaCluster.Status.ProtocolVersion)
+		})
+	}
+}
+
+func TestReconcileProtocolVersion(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		testName                string
+		kRaft                   bool
+		protocolVersion         string
+		expectedProtocolVersion string
+		expectedRollingUpgrade  bool
+	}{
+		{
+			testName:                "protocol version is not pinned",
+			protocolVersion:         "",
+			expectedProtocolVersion: "",
+		},
+		{
+			testName:                "protocol version matches the version of the brokers",
+			protocolVersion:         "3.5",
+			expectedProtocolVersion: "3.5",
+		},
+		{
+			testName:                "brokers are rolled with the raised protocol version",
+			protocolVersion:         "3.4",
+			expectedProtocolVersion: "3.5",
+			expectedRollingUpgrade:  true,
+		},
+		{
+			testName:                "metadata version of the KRaft cluster is raised",
+			kRaft:                   true,
+			protocolVersion:         "3.4",
+			expectedProtocolVersion: "3.5",
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(mockCtrl)
+			mockSubResourceClient := mocks.NewMockSubResourceClient(mockCtrl)
+			mockKafkaClientProvider := new(kafkaclient.MockedProvider)
+			kafkaCluster := &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+				Spec: v1beta1.KafkaClusterSpec{
+					KRaftMode: test.kRaft,
+					Brokers:   []v1beta1.Broker{{Id: 0}, {Id: 1}},
+				},
+				Status: v1beta1.KafkaClusterStatus{
+					ProtocolVersion: test.protocolVersion,
+					BrokersState: map[string]v1beta1.BrokerState{
+						"0": {Version: "3.5.1", ConfigurationState: v1beta1.ConfigInSync},
+						"1": {Version: "3.5.1", ConfigurationState: v1beta1.ConfigInSync},
+					},
+				},
+			}
+			r := New(mockClient, nil, kafkaCluster, mockKafkaClientProvider)
+
+			mockClient.EXPECT().List(
+				context.Background(),
+				gomock.AssignableToTypeOf(&corev1.PodList{}),
+				client.InNamespace("kafka"),
+				gomock.Any(),
+			).Do(func(ctx context.Context, list *corev1.PodList, opts ...client.ListOption) {
+				list.Items = []corev1.Pod{
+					{ObjectMeta: metav1.ObjectMeta{Name: "kafka-0", Labels: map[string]string{"brokerId": "0"}}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "kafka-1", Labels: map[string]string{"brokerId": "1"}}},
+				}
+			}).Return(nil).AnyTimes()
+			mockClient.EXPECT().Status().Return(mockSubResourceClient).AnyTimes()
+			mockSubResourceClient.EXPECT().Update(context.Background(), gomock.AssignableToTypeOf(&v1beta1.KafkaCluster{})).Return(nil).AnyTimes()
+
+			mockedKafkaClient := mocks.NewMockKafkaClient(mockCtrl)
+			mockedKafkaClient.EXPECT().AllOfflineReplicas().Return([]int32{}, nil).AnyTimes()
+			mockedKafkaClient.EXPECT().OutOfSyncReplicas().Return([]int32{}, nil).AnyTimes()
+			if test.kRaft {
+				mockedKafkaClient.EXPECT().MetadataVersion().Return(int16(8), int16(11), nil)
+				mockedKafkaClient.EXPECT().UpdateMetadataVersion(int16(11)).Return(nil)
+			}
+			mockKafkaClientProvider.On("NewFromCluster", mockClient, kafkaCluster).Return(mockedKafkaClient, func() {}, nil)
+
+			err := r.reconcileProtocolVersion(context.Background(), logr.Discard())
+
+			if test.expectedRollingUpgrade {
+				assert.True(t, errors.As(err, &errorfactory.ReconcileRollingUpgrade{}))
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.expectedProtocolVersion, r.KafkaCluster.Status.ProtocolVersion)
+		})
+	}
+}
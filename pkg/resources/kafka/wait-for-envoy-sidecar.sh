@@ -28,5 +28,8 @@ if [[ -n "$ENVOY_SIDECAR_STATUS" ]]; then
   done
 fi
 touch /var/run/wait/do-not-exit-yet
+if [[ -n "$CLUSTER_ID" ]]; then
+  /opt/kafka/bin/kafka-storage.sh format --cluster-id "$CLUSTER_ID" --config /config/broker-config --ignore-formatted
+fi
 /opt/kafka/bin/kafka-server-start.sh /config/broker-config
 rm /var/run/wait/do-not-exit-yet
@@ -59,6 +59,10 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 				if err != nil {
 					return err
 				}
+				// Dedicated KRaft controllers have no external listeners
+				if brokerConfig.IsControllerOnlyNode() {
+					continue
+				}
 
 				service := r.service(log, broker.Id, brokerConfig, eListener)
 
@@ -689,6 +689,43 @@ func (cc *cruiseControlScaler) KafkaClusterLoad(ctx context.Context) (*api.Kafka
 	return clusterLoadResp, nil
 }
 
+// DemoteBrokers moves the partition leadership away from the given brokers without moving any replicas
+func (cc *cruiseControlScaler) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*Result, error) {
+	if len(brokerIDs) == 0 {
+		return nil, errors.New("no broker id(s) provided for demote brokers request")
+	}
+
+	brokersToDemote := make([]int32, 0, len(brokerIDs))
+	for _, brokerID := range brokerIDs {
+		bID, err := strconv.Atoi(brokerID)
+		if err != nil {
+			cc.log.Error(err, "failed to cast broker ID from string to integer", "broker_id", brokerID)
+			return nil, err
+		}
+		brokersToDemote = append(brokersToDemote, int32(bID))
+	}
+
+	demoteReq := api.DemoteBrokerRequestWithDefaults()
+	demoteReq.BrokerIDs = brokersToDemote
+	demoteResp, err := cc.client.DemoteBroker(ctx, demoteReq)
+	if err != nil {
+		return &Result{
+			TaskID:             demoteResp.TaskID,
+			StartedAt:          demoteResp.Date,
+			ResponseStatusCode: demoteResp.StatusCode,
+			RequestURL:         demoteResp.RequestURL,
+			State:              v1beta1.CruiseControlTaskCompletedWithError,
+			Err:                err,
+		}, err
+	}
+
+	return &Result{
+		TaskID:    demoteResp.TaskID,
+		StartedAt: demoteResp.Date,
+		State:     v1beta1.CruiseControlTaskActive,
+	}, nil
+}
+
 // RebalanceDisks performs a disk rebalance via Cruise Control for the provided list of brokers.
 func (cc *cruiseControlScaler) RebalanceDisks(ctx context.Context, brokerIDs ...string) (*Result, error) {
 	clusterLoadResp, err := cc.client.KafkaClusterLoad(ctx, api.KafkaClusterLoadRequestWithDefaults())
@@ -15,6 +15,8 @@
 package kafka
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"strings"
 
@@ -52,11 +54,44 @@ var writeACLString = "User:%s,Topic,%s,%s,Write,Allow,*"
 var readACLString = "User:%s,Topic,%s,%s,Read,Allow,*"
 
 // readGroupACLString is the raw representation of an ACL allowing Read on ConsumerGroups
-var readGroupACLString = "User:%s,Group,LITERAL,*,Read,Allow,*"
+var readGroupACLString = "User:%s,Group,%s,%s,Read,Allow,*"
+
+// aclString is the raw representation of an ACL of a user
+var aclString = "User:%s,%s,%s,%s,%s,%s,%s"
+
+// aclResourceTypeNames are the names of the resource types used in the raw ACL strings
+var aclResourceTypeNames = map[v1alpha1.KafkaResourceType]string{
+	v1alpha1.KafkaResourceTypeTopic:           "Topic",
+	v1alpha1.KafkaResourceTypeGroup:           "Group",
+	v1alpha1.KafkaResourceTypeCluster:         "Cluster",
+	v1alpha1.KafkaResourceTypeTransactionalID: "TransactionalId",
+	v1alpha1.KafkaResourceTypeDelegationToken: "DelegationToken",
+}
+
+// aclOperationNames are the names of the operations used in the raw ACL strings
+var aclOperationNames = map[v1alpha1.KafkaACLOperation]string{
+	v1alpha1.KafkaACLOperationAll:             "All",
+	v1alpha1.KafkaACLOperationRead:            "Read",
+	v1alpha1.KafkaACLOperationWrite:           "Write",
+	v1alpha1.KafkaACLOperationCreate:          "Create",
+	v1alpha1.KafkaACLOperationDelete:          "Delete",
+	v1alpha1.KafkaACLOperationAlter:           "Alter",
+	v1alpha1.KafkaACLOperationDescribe:        "Describe",
+	v1alpha1.KafkaACLOperationClusterAction:   "ClusterAction",
+	v1alpha1.KafkaACLOperationDescribeConfigs: "DescribeConfigs",
+	v1alpha1.KafkaACLOperationAlterConfigs:    "AlterConfigs",
+	v1alpha1.KafkaACLOperationIdempotentWrite: "IdempotentWrite",
+}
 
-// GrantsToACLStrings converts a user DN and a list of topic grants to raw strings
+// aclPermissionTypeNames are the names of the permission types used in the raw ACL strings
+var aclPermissionTypeNames = map[v1alpha1.KafkaACLPermissionType]string{
+	v1alpha1.KafkaACLPermissionAllow: "Allow",
+	v1alpha1.KafkaACLPermissionDeny:  "Deny",
+}
+
+// GrantsToACLStrings converts a user DN, a list of topic grants and a list of ACLs to raw strings
 // for a CR status
-func GrantsToACLStrings(dn string, grants []v1alpha1.UserTopicGrant) []string {
+func GrantsToACLStrings(dn string, grants []v1alpha1.UserTopicGrant, userACLs []v1alpha1.UserACL) []string {
 	acls := make([]string, 0)
 	for _, x := range grants {
 		if x.PatternType == "" {
@@ -70,7 +105,7 @@ func GrantsToACLStrings(dn string, grants []v1alpha1.UserTopicGrant) []string {
 		switch x.AccessType {
 		case v1alpha1.KafkaAccessTypeRead:
 			readACL := fmt.Sprintf(readACLString, dn, patternType, x.TopicName)
-			readGroupACL := fmt.Sprintf(readGroupACLString, dn)
+			readGroupACL := fmt.Sprintf(readGroupACLString, dn, strings.ToUpper(string(x.GetConsumerGroupPatternType())), x.GetConsumerGroup())
 			for _, y := range []string{readACL, readGroupACL} {
 				if !util.StringSliceContains(acls, y) {
 					acls = append(acls, y)
@@ -86,6 +121,18 @@ func GrantsToACLStrings(dn string, grants []v1alpha1.UserTopicGrant) []string {
 			}
 		}
 	}
+	for _, x := range userACLs {
+		patternType := strings.ToUpper(string(x.GetPatternType()))
+		for _, operation := range x.Operations {
+			for _, host := range x.GetHosts() {
+				acl := fmt.Sprintf(aclString, dn, aclResourceTypeNames[x.ResourceType], patternType, x.GetResourceName(),
+					aclOperationNames[operation], aclPermissionTypeNames[x.GetPermissionType()], host)
+				if !util.StringSliceContains(acls, acl) {
+					acls = append(acls, acl)
+				}
+			}
+		}
+	}
 	return acls
 }
 
@@ -259,3 +306,23 @@ func GatherBrokerConfigIfAvailable(kafkaClusterSpec v1beta1.KafkaClusterSpec, br
 	// That means broker is under deletion, which is not an error.
 	return nil, nil
 }
+
+// GenerateClusterID generates a random cluster id in the format expected by the kafka-storage tool,
+// which is the URL safe base64 encoding of a random UUID
+func GenerateClusterID() (string, error) {
+	for {
+		uuid := make([]byte, 16)
+		if _, err := rand.Read(uuid); err != nil {
+			return "", errors.WrapIf(err, "could not generate random bytes for cluster id")
+		}
+		// set the version (4) and variant bits of the UUID
+		uuid[6] = (uuid[6] & 0x0f) | 0x40
+		uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+		clusterID := base64.RawURLEncoding.EncodeToString(uuid)
+		// ids starting with a dash would be interpreted as a command line flag
+		if !strings.HasPrefix(clusterID, "-") {
+			return clusterID, nil
+		}
+	}
+}
@@ -15,12 +15,15 @@
 package kafka
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/util"
 	properties "github.com/banzaicloud/koperator/properties/pkg"
@@ -349,3 +352,61 @@ func TestGetBootstrapServers(t *testing.T) {
 		}
 	})
 }
+
+func TestGenerateClusterID(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		clusterID, err := GenerateClusterID()
+		if err != nil {
+			t.Errorf("Should not return error. Got %v", err)
+		}
+		if len(clusterID) != 22 {
+			t.Errorf("Cluster id should be 22 characters long. Got %q", clusterID)
+		}
+		if strings.HasPrefix(clusterID, "-") {
+			t.Errorf("Cluster id should not start with a dash. Got %q", clusterID)
+		}
+	}
+}
+
+func TestGrantsToACLStrings(t *testing.T) {
+	grants := []v1alpha1.UserTopicGrant{
+		{TopicName: "orders", AccessType: v1alpha1.KafkaAccessTypeRead},
+		{TopicName: "payments", AccessType: v1alpha1.KafkaAccessTypeRead, ConsumerGroup: "billing-", ConsumerGroupPatternType: v1alpha1.KafkaPatternTypePrefixed},
+	}
+	acls := []v1alpha1.UserACL{
+		{
+			ResourceType: v1alpha1.KafkaResourceTypeCluster,
+			Operations:   []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationIdempotentWrite},
+		},
+		{
+			ResourceType:   v1alpha1.KafkaResourceTypeTransactionalID,
+			ResourceName:   "orders-",
+			PatternType:    v1alpha1.KafkaPatternTypePrefixed,
+			Operations:     []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationWrite, v1alpha1.KafkaACLOperationDescribe},
+			PermissionType: v1alpha1.KafkaACLPermissionDeny,
+			Hosts:          []string{"10.0.0.1"},
+		},
+		{
+			// duplicate of an ACL of the topic grant
+			ResourceType: v1alpha1.KafkaResourceTypeTopic,
+			ResourceName: "orders",
+			Operations:   []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationRead},
+		},
+	}
+
+	expected := []string{
+		"User:CN=test,Topic,LITERAL,orders,Describe,Allow,*",
+		"User:CN=test,Topic,LITERAL,orders,Read,Allow,*",
+		"User:CN=test,Group,LITERAL,*,Read,Allow,*",
+		"User:CN=test,Topic,LITERAL,payments,Describe,Allow,*",
+		"User:CN=test,Topic,LITERAL,payments,Read,Allow,*",
+		"User:CN=test,Group,PREFIXED,billing-,Read,Allow,*",
+		"User:CN=test,Cluster,LITERAL,kafka-cluster,IdempotentWrite,Allow,*",
+		"User:CN=test,TransactionalId,PREFIXED,orders-,Write,Deny,10.0.0.1",
+		"User:CN=test,TransactionalId,PREFIXED,orders-,Describe,Deny,10.0.0.1",
+	}
+	actual := GrantsToACLStrings("CN=test", grants, acls)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected ACL strings %v, got %v", expected, actual)
+	}
+}
@@ -26,6 +26,16 @@ const (
 	KafkaConfigBrokerId           = "broker.id"
 	KafkaConfigBrokerLogDirectory = "log.dirs"
 
+	KafkaConfigNodeId                 = "node.id"
+	KafkaConfigProcessRoles           = "process.roles"
+	KafkaConfigControllerQuorumVoters = "controller.quorum.voters"
+	KafkaConfigControllerListenerName = "controller.listener.names"
+	KafkaConfigZooKeeperMigration     = "zookeeper.metadata.migration.enable"
+	KafkaConfigMetadataLogDirectory   = "metadata.log.dir"
+
+	KafkaConfigInterBrokerProtocolVersion = "inter.broker.protocol.version"
+	KafkaConfigLogMessageFormatVersion    = "log.message.format.version"
+
 	KafkaConfigListeners                   = "listeners"
 	KafkaConfigListenerName                = "listener.name"
 	KafkaConfigListenerSecurityProtocolMap = "listener.security.protocol.map"
@@ -44,9 +54,16 @@ const (
 	KafkaConfigSSLKeyStorePassword   = "ssl.keystore.password"
 )
 
+// KRaft process roles
+const (
+	ProcessRoleBroker     = "broker"
+	ProcessRoleController = "controller"
+)
+
 // used for Cruise Control configurations
 const (
 	CruiseControlConfigMetricsReporters                 = "metric.reporters"
 	CruiseControlConfigMetricsReportersBootstrapServers = "cruise.control.metrics.reporter.bootstrap.servers"
 	CruiseControlConfigMetricsReporterK8sMode           = "cruise.control.metrics.reporter.kubernetes.mode"
+	CruiseControlConfigKafkaBrokerFailureDetection      = "kafka.broker.failure.detection.enable"
 )
@@ -0,0 +1,105 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+)
+
+// GetProtocolVersion returns the major.minor part of the given Kafka version
+// which is the format inter.broker.protocol.version, log.message.format.version and metadata.version are set in
+func GetProtocolVersion(version string) (string, error) {
+	major, minor, err := parseProtocolVersion(version)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d.%d", major, minor), nil
+}
+
+// CompareProtocolVersions compares the major.minor part of the given Kafka versions.
+// The result is 0 if a == b, -1 if a < b, and +1 if a > b.
+func CompareProtocolVersions(a, b string) (int, error) {
+	aMajor, aMinor, err := parseProtocolVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bMajor, bMinor, err := parseProtocolVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case aMajor != bMajor:
+		return compareInts(aMajor, bMajor), nil
+	default:
+		return compareInts(aMinor, bMinor), nil
+	}
+}
+
+// GetVersionFromImage returns the Kafka version from the tag of the given container image
+// e.g. 3.4.1 from ghcr.io/banzaicloud/kafka:2.13-3.4.1. It returns false if the tag does not contain a version.
+func GetVersionFromImage(image string) (string, bool) {
+	// The digest and the registry port must not be mistaken for the tag
+	image, _, _ = strings.Cut(image, "@")
+	tagIndex := strings.LastIndex(image, ":")
+	if tagIndex < 0 || tagIndex < strings.LastIndex(image, "/") {
+		return "", false
+	}
+
+	// The Scala version preceding the Kafka version has only two components
+	tagParts := strings.Split(image[tagIndex+1:], "-")
+	for i := len(tagParts) - 1; i >= 0; i-- {
+		versionParts := strings.Split(tagParts[i], ".")
+		if len(versionParts) != 3 {
+			continue
+		}
+		if _, _, err := parseProtocolVersion(tagParts[i]); err == nil {
+			return tagParts[i], true
+		}
+	}
+	return "", false
+}
+
+func parseProtocolVersion(version string) (int, int, error) {
+	versionParts := strings.SplitN(version, ".", 3)
+	if len(versionParts) < 2 {
+		return 0, 0, errors.NewWithDetails("invalid Kafka version", "version", version)
+	}
+	major, err := strconv.Atoi(versionParts[0])
+	if err != nil {
+		return 0, 0, errors.WrapIfWithDetails(err, "invalid Kafka major version", "version", version)
+	}
+	// The inter.broker.protocol.version may also contain the internal version e.g. 3.4-IV0
+	minorPart, _, _ := strings.Cut(versionParts[1], "-")
+	minor, err := strconv.Atoi(minorPart)
+	if err != nil {
+		return 0, 0, errors.WrapIfWithDetails(err, "invalid Kafka minor version", "version", version)
+	}
+	return major, minor, nil
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
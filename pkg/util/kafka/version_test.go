@@ -0,0 +1,90 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+)
+
+func TestGetProtocolVersion(t *testing.T) {
+	testCases := []struct {
+		Version  string
+		Expected string
+		Error    bool
+	}{
+		{Version: "3.4.1", Expected: "3.4"},
+		{Version: "2.8", Expected: "2.8"},
+		{Version: "3.6.0-SNAPSHOT", Expected: "3.6"},
+		{Version: "3.4-IV0", Expected: "3.4"},
+		{Version: "3", Error: true},
+		{Version: "", Error: true},
+		{Version: "a.b.c", Error: true},
+	}
+
+	for _, test := range testCases {
+		protocolVersion, err := GetProtocolVersion(test.Version)
+		if test.Error != (err != nil) {
+			t.Errorf("version: %s, expected error: %v, got: %v", test.Version, test.Error, err)
+		}
+		if protocolVersion != test.Expected {
+			t.Errorf("version: %s, expected: %s, got: %s", test.Version, test.Expected, protocolVersion)
+		}
+	}
+}
+
+func TestCompareProtocolVersions(t *testing.T) {
+	testCases := []struct {
+		A        string
+		B        string
+		Expected int
+	}{
+		{A: "3.4", B: "3.4.1", Expected: 0},
+		{A: "3.4", B: "3.5", Expected: -1},
+		{A: "3.10", B: "3.9", Expected: 1},
+		{A: "2.8.2", B: "3.0", Expected: -1},
+	}
+
+	for _, test := range testCases {
+		result, err := CompareProtocolVersions(test.A, test.B)
+		if err != nil {
+			t.Errorf("a: %s, b: %s, unexpected error: %s", test.A, test.B, err)
+		}
+		if result != test.Expected {
+			t.Errorf("a: %s, b: %s, expected: %d, got: %d", test.A, test.B, test.Expected, result)
+		}
+	}
+}
+
+func TestGetVersionFromImage(t *testing.T) {
+	testCases := []struct {
+		Image    string
+		Expected string
+		Found    bool
+	}{
+		{Image: "ghcr.io/banzaicloud/kafka:2.13-3.4.1", Expected: "3.4.1", Found: true},
+		{Image: "apache/kafka:3.7.0", Expected: "3.7.0", Found: true},
+		{Image: "registry:5000/kafka:3.6.1-debian@sha256:abcd", Expected: "3.6.1", Found: true},
+		{Image: "registry:5000/kafka", Found: false},
+		{Image: "ghcr.io/banzaicloud/kafka:latest", Found: false},
+		{Image: "ghcr.io/banzaicloud/kafka:2.13", Found: false},
+	}
+
+	for _, test := range testCases {
+		version, found := GetVersionFromImage(test.Image)
+		if found != test.Found || version != test.Expected {
+			t.Errorf("image: %s, expected: %s %v, got: %s %v", test.Image, test.Expected, test.Found, version, found)
+		}
+	}
+}
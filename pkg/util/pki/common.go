@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -80,7 +81,9 @@ type Manager interface {
 	// FinalizePKI performs any cleanup steps necessary for a PKI backend
 	FinalizePKI(ctx context.Context) error
 
-	// ReconcileUserCertificate ensures and returns a user certificate - should be idempotent
+	// ReconcileUserCertificate ensures and returns a user certificate - should be idempotent.
+	// When the renewal of an issued certificate fails the current certificate is returned along with
+	// a CertificateRenewalFailed error.
 	ReconcileUserCertificate(
 		ctx context.Context, user *v1alpha1.KafkaUser, scheme *runtime.Scheme, clusterDomain string) (*UserCertificate, error)
 
@@ -99,6 +102,9 @@ type UserCertificate struct {
 	Key         []byte
 	JKS         []byte
 	Password    []byte
+	// RenewalFailureTime is the time of the failed renewal reported along with a CertificateRenewalFailed error,
+	// the same failure is reported with the same time
+	RenewalFailureTime time.Time
 }
 
 // GetDistinguishedName returns the Distinguished Name of a TLS certificate
@@ -247,6 +247,10 @@ func ConstructEListenerLabelName(ingressConfigName, eListenerName string) string
 // ShouldIncludeBroker returns true if the broker should be included as a resource on external listener resources
 func ShouldIncludeBroker(brokerConfig *v1beta1.BrokerConfig, status v1beta1.KafkaClusterStatus, brokerID int,
 	defaultIngressConfigName, ingressConfigName string) bool {
+	// Dedicated KRaft controllers have no external listeners
+	if brokerConfig.IsControllerOnlyNode() {
+		return false
+	}
 	if brokerConfig != nil {
 		if len(brokerConfig.BrokerIngressMapping) == 0 && (ingressConfigName == defaultIngressConfigName || defaultIngressConfigName == "") ||
 			StringSliceContains(brokerConfig.BrokerIngressMapping, ingressConfigName) {
@@ -23,12 +23,30 @@ import (
 const (
 	cantConnectErrorMsg                            = "failed to connect to kafka cluster"
 	cantConnectAPIServerMsg                        = "failed to connect to Kubernetes API server"
+	topicDeletionProtectedErrMsg                   = "the KafkaTopic is protected from deletion"
 	invalidReplicationFactorErrMsg                 = "replication factor is larger than the number of nodes in the kafka cluster"
 	outOfRangeReplicationFactorErrMsg              = "replication factor must be larger than 0 (or set it to be -1 to use the broker's default)"
 	outOfRangePartitionsErrMsg                     = "number of partitions must be larger than 0 (or set it to be -1 to use the broker's default)"
 	unsupportedRemovingStorageMsg                  = "removing storage from a broker is not supported"
 	invalidExternalListenerStartingPortErrMsg      = "invalid external listener starting port number"
 	invalidContainerPortForIngressControllerErrMsg = "invalid trarget port number for ingress controller deployment"
+	missingZKAddressesErrMsg                       = "zkAddresses must be set unless the cluster runs in KRaft mode"
+	missingKRaftControllerListenerErrMsg           = "an internal listener with usedForControllerCommunication must be set in KRaft mode"
+	unsupportedKRaftModeChangeErrMsg               = "switching an existing cluster between ZooKeeper and KRaft mode is not supported"
+	processRoleWithoutKRaftErrMsg                  = "processRole can only be set when the cluster runs in KRaft mode"
+	missingKRaftControllerNodeErrMsg               = "at least one node must have the controller or combined processRole in KRaft mode"
+	missingKRaftMigrationProcessRoleErrMsg         = "every node must have the broker or controller processRole during the migration to KRaft"
+	unsupportedKRaftMigrationOnCreateErrMsg        = "migrateToKRaft can only be set on an existing ZooKeeper based cluster"
+	unsupportedKRaftMigrationRevertErrMsg          = "migrateToKRaft cannot be disabled while the migration to KRaft is in progress"
+	unsupportedKafkaDowngradeErrMsg                = "downgrading Kafka below the protocol version of the cluster is not supported"
+	conflictingTopicPlacementErrMsg                = "replicaAssignment cannot be used together with brokers and racks"
+	missingPlacementTopicSizeErrMsg                = "partitions and replication factor must be set when the replicas are placed on brokers or racks"
+	notEnoughPlacementBrokersErrMsg                = "replication factor is larger than the number of brokers allowed by the placement"
+	invalidReplicaAssignmentErrMsg                 = "replica assignment must list the replicas of every partition"
+	consumerGroupOfWriteGrantErrMsg                = "consumerGroup can only be set for read grants"
+	missingScopedConsumerGroupErrMsg               = "the kafka cluster requires the read grants to be restricted to a consumer group name or prefix"
+	topicClassInUseErrMsg                          = "the KafkaTopicClass is used by KafkaTopics"
+	invalidTopicClassChangeErrMsg                  = "the change is invalid for the KafkaTopic using the class"
 
 	// errorDuringValidationMsg is added to infrastructure errors (e.g. failed to connect), but not to field validation errors
 	errorDuringValidationMsg = "error during validation"
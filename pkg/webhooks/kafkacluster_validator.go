@@ -30,6 +30,7 @@ import (
 
 	banzaicloudv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/util"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
 )
 
 type KafkaClusterValidator struct {
@@ -41,6 +42,13 @@ func (s KafkaClusterValidator) ValidateUpdate(ctx context.Context, oldObj, newOb
 	kafkaClusterNew := newObj.(*banzaicloudv1beta1.KafkaCluster)
 	log := s.Log.WithValues("name", kafkaClusterNew.GetName(), "namespace", kafkaClusterNew.GetNamespace())
 
+	kafkaClusterOld := oldObj.(*banzaicloudv1beta1.KafkaCluster)
+	allErrs = append(allErrs, checkKRaftModeChange(kafkaClusterOld, kafkaClusterNew)...)
+
+	allErrs = append(allErrs, checkKafkaVersionDowngrade(kafkaClusterOld.Status.ProtocolVersion, &kafkaClusterNew.Spec)...)
+
+	allErrs = append(allErrs, checkMetadataQuorum(&kafkaClusterNew.Spec)...)
+
 	listenerErrs := checkInternalAndExternalListeners(&kafkaClusterNew.Spec)
 	if listenerErrs != nil {
 		allErrs = append(allErrs, listenerErrs...)
@@ -61,6 +69,12 @@ func (s KafkaClusterValidator) ValidateCreate(ctx context.Context, obj runtime.O
 	kafkaCluster := obj.(*banzaicloudv1beta1.KafkaCluster)
 	log := s.Log.WithValues("name", kafkaCluster.GetName(), "namespace", kafkaCluster.GetNamespace())
 
+	if kafkaCluster.Spec.MigrateToKRaft {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("migrateToKRaft"), unsupportedKRaftMigrationOnCreateErrMsg))
+	}
+
+	allErrs = append(allErrs, checkMetadataQuorum(&kafkaCluster.Spec)...)
+
 	listenerErrs := checkInternalAndExternalListeners(&kafkaCluster.Spec)
 	if listenerErrs != nil {
 		allErrs = append(allErrs, listenerErrs...)
@@ -80,6 +94,152 @@ func (s KafkaClusterValidator) ValidateDelete(ctx context.Context, obj runtime.O
 	return nil, nil
 }
 
+// checkMetadataQuorum checks that the cluster either uses Zookeeper or runs in KRaft mode with a controller listener
+func checkMetadataQuorum(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !kafkaClusterSpec.IsKRaftMode() {
+		if len(kafkaClusterSpec.ZKAddresses) == 0 {
+			allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("zkAddresses"), missingZKAddressesErrMsg))
+		}
+		if !kafkaClusterSpec.MigrateToKRaft {
+			return append(allErrs, checkProcessRolesWithoutKRaft(kafkaClusterSpec)...)
+		}
+		// During the migration ZooKeeper is still needed next to the KRaft controllers
+		allErrs = append(allErrs, checkKRaftControllerListener(kafkaClusterSpec)...)
+		allErrs = append(allErrs, checkKRaftMigrationProcessRoles(kafkaClusterSpec)...)
+		return append(allErrs, checkKRaftControllerNodes(kafkaClusterSpec)...)
+	}
+
+	allErrs = append(allErrs, checkKRaftControllerListener(kafkaClusterSpec)...)
+
+	return append(allErrs, checkKRaftControllerNodes(kafkaClusterSpec)...)
+}
+
+// checkKRaftControllerListener checks that one of the internal listeners is used by the KRaft controller quorum
+func checkKRaftControllerListener(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for _, intListener := range kafkaClusterSpec.ListenersConfig.InternalListeners {
+		if intListener.UsedForControllerCommunication {
+			return allErrs
+		}
+	}
+	allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("listenersConfig").Child("internalListeners"), missingKRaftControllerListenerErrMsg))
+
+	return allErrs
+}
+
+// checkKRaftMigrationProcessRoles checks that every node is either a dedicated broker or a dedicated controller
+// since the existing brokers cannot join the KRaft controller quorum during the migration
+func checkKRaftMigrationProcessRoles(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, broker := range kafkaClusterSpec.Brokers {
+		brokerConfig, err := broker.GetBrokerConfig(*kafkaClusterSpec)
+		// invalid broker config groups are reported by the reconciler
+		if err != nil {
+			continue
+		}
+		if brokerConfig.ProcessRole != banzaicloudv1beta1.ProcessRoleBroker && brokerConfig.ProcessRole != banzaicloudv1beta1.ProcessRoleController {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("brokers").Index(i).Child("brokerConfig").Child("processRole"), brokerConfig.ProcessRole, missingKRaftMigrationProcessRoleErrMsg))
+		}
+	}
+
+	return allErrs
+}
+
+// checkProcessRolesWithoutKRaft checks that process roles are only set when the cluster runs in KRaft mode
+func checkProcessRolesWithoutKRaft(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for name, groupConfig := range kafkaClusterSpec.BrokerConfigGroups {
+		if groupConfig.ProcessRole != "" {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("brokerConfigGroups").Key(name).Child("processRole"), processRoleWithoutKRaftErrMsg))
+		}
+	}
+	for i, broker := range kafkaClusterSpec.Brokers {
+		if broker.BrokerConfig != nil && broker.BrokerConfig.ProcessRole != "" {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("brokers").Index(i).Child("brokerConfig").Child("processRole"), processRoleWithoutKRaftErrMsg))
+		}
+	}
+
+	return allErrs
+}
+
+// checkKRaftControllerNodes checks that there is at least one node in the KRaft controller quorum
+func checkKRaftControllerNodes(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(kafkaClusterSpec.Brokers) == 0 {
+		return allErrs
+	}
+	for _, broker := range kafkaClusterSpec.Brokers {
+		brokerConfig, err := broker.GetBrokerConfig(*kafkaClusterSpec)
+		// invalid broker config groups are reported by the reconciler
+		if err != nil || brokerConfig.IsControllerNode() {
+			return allErrs
+		}
+	}
+	allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("brokers"), len(kafkaClusterSpec.Brokers), missingKRaftControllerNodeErrMsg))
+
+	return allErrs
+}
+
+// checkKRaftModeChange checks that the metadata quorum of an existing cluster is not switched between Zookeeper and KRaft
+// other than by completing the migration to KRaft
+func checkKRaftModeChange(kafkaClusterOld, kafkaClusterNew *banzaicloudv1beta1.KafkaCluster) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if kafkaClusterOld.Spec.IsKRaftMode() != kafkaClusterNew.Spec.IsKRaftMode() {
+		migrationCompleted := !kafkaClusterOld.Spec.IsKRaftMode() && kafkaClusterOld.IsKRaftMode()
+		if !migrationCompleted {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("kRaft"), unsupportedKRaftModeChangeErrMsg))
+		}
+	}
+
+	if kafkaClusterOld.IsKRaftMigrationInProgress() && kafkaClusterOld.Status.KRaftMigrationState != "" && !kafkaClusterNew.Spec.MigrateToKRaft {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("migrateToKRaft"), unsupportedKRaftMigrationRevertErrMsg))
+	}
+
+	return allErrs
+}
+
+// checkKafkaVersionDowngrade checks that none of the images would downgrade the brokers below the protocol version
+// the cluster has already been upgraded to. Images without a Kafka version in their tag can not be checked.
+func checkKafkaVersionDowngrade(protocolVersion string, kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if protocolVersion == "" {
+		return allErrs
+	}
+
+	isDowngrade := func(image string) bool {
+		version, found := kafkautils.GetVersionFromImage(image)
+		if !found {
+			return false
+		}
+		cmp, err := kafkautils.CompareProtocolVersions(version, protocolVersion)
+		return err == nil && cmp < 0
+	}
+
+	if isDowngrade(kafkaClusterSpec.GetClusterImage()) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("clusterImage"), unsupportedKafkaDowngradeErrMsg))
+	}
+	for name, groupConfig := range kafkaClusterSpec.BrokerConfigGroups {
+		if groupConfig.Image != "" && isDowngrade(groupConfig.Image) {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("brokerConfigGroups").Key(name).Child("image"), unsupportedKafkaDowngradeErrMsg))
+		}
+	}
+	for i, broker := range kafkaClusterSpec.Brokers {
+		if broker.BrokerConfig != nil && broker.BrokerConfig.Image != "" && isDowngrade(broker.BrokerConfig.Image) {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("brokers").Index(i).Child("brokerConfig").Child("image"), unsupportedKafkaDowngradeErrMsg))
+		}
+	}
+
+	return allErrs
+}
+
 // checkListeners validates the spec.listenersConfig object
 func checkInternalAndExternalListeners(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
 	var allErrs field.ErrorList
@@ -384,3 +384,321 @@ func TestCheckTargetPortsCollisionForEnvoy(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckMetadataQuorum(t *testing.T) {
+	testCases := []struct {
+		testName         string
+		kafkaClusterSpec v1beta1.KafkaClusterSpec
+		expected         field.ErrorList
+	}{
+		{
+			testName: "ZooKeeper mode with zkAddresses",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				ZKAddresses: []string{"example.zk:2181"},
+			},
+			expected: nil,
+		},
+		{
+			testName:         "ZooKeeper mode without zkAddresses",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{},
+			expected: append(field.ErrorList{},
+				field.Required(field.NewPath("spec").Child("zkAddresses"), missingZKAddressesErrMsg)),
+		},
+		{
+			testName: "KRaft mode with controller listener",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				KRaftMode: true,
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{
+						{
+							CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", ContainerPort: 29092},
+						},
+						{
+							CommonListenerSpec:             v1beta1.CommonListenerSpec{Name: "controller", ContainerPort: 29093},
+							UsedForControllerCommunication: true,
+						},
+					},
+				},
+			},
+			expected: nil,
+		},
+		{
+			testName: "ZooKeeper mode with processRole",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				ZKAddresses: []string{"example.zk:2181"},
+				Brokers: []v1beta1.Broker{
+					{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{ProcessRole: v1beta1.ProcessRoleController}},
+				},
+			},
+			expected: append(field.ErrorList{},
+				field.Forbidden(field.NewPath("spec").Child("brokers").Index(0).Child("brokerConfig").Child("processRole"), processRoleWithoutKRaftErrMsg)),
+		},
+		{
+			testName: "KRaft mode with dedicated controller and broker nodes",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				KRaftMode: true,
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{
+						{
+							CommonListenerSpec:             v1beta1.CommonListenerSpec{Name: "controller", ContainerPort: 29093},
+							UsedForControllerCommunication: true,
+						},
+					},
+				},
+				Brokers: []v1beta1.Broker{
+					{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{ProcessRole: v1beta1.ProcessRoleController}},
+					{Id: 1, BrokerConfig: &v1beta1.BrokerConfig{ProcessRole: v1beta1.ProcessRoleBroker}},
+				},
+			},
+			expected: nil,
+		},
+		{
+			testName: "KRaft mode without controller nodes",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				KRaftMode: true,
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{
+						{
+							CommonListenerSpec:             v1beta1.CommonListenerSpec{Name: "controller", ContainerPort: 29093},
+							UsedForControllerCommunication: true,
+						},
+					},
+				},
+				Brokers: []v1beta1.Broker{
+					{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{ProcessRole: v1beta1.ProcessRoleBroker}},
+				},
+			},
+			expected: append(field.ErrorList{},
+				field.Invalid(field.NewPath("spec").Child("brokers"), 1, missingKRaftControllerNodeErrMsg)),
+		},
+		{
+			testName: "KRaft mode without controller listener",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				KRaftMode: true,
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{
+						{
+							CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", ContainerPort: 29092},
+						},
+					},
+				},
+			},
+			expected: append(field.ErrorList{},
+				field.Required(field.NewPath("spec").Child("listenersConfig").Child("internalListeners"), missingKRaftControllerListenerErrMsg)),
+		},
+		{
+			testName: "migration to KRaft with dedicated controller and broker nodes",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				MigrateToKRaft: true,
+				ZKAddresses:    []string{"example.zk:2181"},
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{
+						{
+							CommonListenerSpec:             v1beta1.CommonListenerSpec{Name: "controller", ContainerPort: 29093},
+							UsedForControllerCommunication: true,
+						},
+					},
+				},
+				Brokers: []v1beta1.Broker{
+					{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{ProcessRole: v1beta1.ProcessRoleBroker}},
+					{Id: 3000, BrokerConfig: &v1beta1.BrokerConfig{ProcessRole: v1beta1.ProcessRoleController}},
+				},
+			},
+			expected: nil,
+		},
+		{
+			testName: "migration to KRaft without zkAddresses and controller listener",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				MigrateToKRaft: true,
+				Brokers: []v1beta1.Broker{
+					{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{ProcessRole: v1beta1.ProcessRoleBroker}},
+					{Id: 3000, BrokerConfig: &v1beta1.BrokerConfig{ProcessRole: v1beta1.ProcessRoleController}},
+				},
+			},
+			expected: append(field.ErrorList{},
+				field.Required(field.NewPath("spec").Child("zkAddresses"), missingZKAddressesErrMsg),
+				field.Required(field.NewPath("spec").Child("listenersConfig").Child("internalListeners"), missingKRaftControllerListenerErrMsg)),
+		},
+		{
+			testName: "migration to KRaft with nodes without dedicated roles",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				MigrateToKRaft: true,
+				ZKAddresses:    []string{"example.zk:2181"},
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{
+						{
+							CommonListenerSpec:             v1beta1.CommonListenerSpec{Name: "controller", ContainerPort: 29093},
+							UsedForControllerCommunication: true,
+						},
+					},
+				},
+				Brokers: []v1beta1.Broker{
+					{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{}},
+					{Id: 1, BrokerConfig: &v1beta1.BrokerConfig{ProcessRole: v1beta1.ProcessRoleCombined}},
+				},
+			},
+			expected: append(field.ErrorList{},
+				field.Invalid(field.NewPath("spec").Child("brokers").Index(0).Child("brokerConfig").Child("processRole"), v1beta1.ProcessRole(""), missingKRaftMigrationProcessRoleErrMsg),
+				field.Invalid(field.NewPath("spec").Child("brokers").Index(1).Child("brokerConfig").Child("processRole"), v1beta1.ProcessRoleCombined, missingKRaftMigrationProcessRoleErrMsg)),
+		},
+		{
+			testName: "migration to KRaft without controller nodes",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				MigrateToKRaft: true,
+				ZKAddresses:    []string{"example.zk:2181"},
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{
+						{
+							CommonListenerSpec:             v1beta1.CommonListenerSpec{Name: "controller", ContainerPort: 29093},
+							UsedForControllerCommunication: true,
+						},
+					},
+				},
+				Brokers: []v1beta1.Broker{
+					{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{ProcessRole: v1beta1.ProcessRoleBroker}},
+				},
+			},
+			expected: append(field.ErrorList{},
+				field.Invalid(field.NewPath("spec").Child("brokers"), 1, missingKRaftControllerNodeErrMsg)),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			got := checkMetadataQuorum(&testCase.kafkaClusterSpec)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckKRaftModeChange(t *testing.T) {
+	testCases := []struct {
+		testName        string
+		kafkaClusterOld v1beta1.KafkaCluster
+		kafkaClusterNew v1beta1.KafkaCluster
+		expected        field.ErrorList
+	}{
+		{
+			testName:        "ZooKeeper mode unchanged",
+			kafkaClusterOld: v1beta1.KafkaCluster{},
+			kafkaClusterNew: v1beta1.KafkaCluster{},
+			expected:        nil,
+		},
+		{
+			testName:        "ZooKeeper mode switched to KRaft mode",
+			kafkaClusterOld: v1beta1.KafkaCluster{},
+			kafkaClusterNew: v1beta1.KafkaCluster{Spec: v1beta1.KafkaClusterSpec{KRaftMode: true}},
+			expected: append(field.ErrorList{},
+				field.Forbidden(field.NewPath("spec").Child("kRaft"), unsupportedKRaftModeChangeErrMsg)),
+		},
+		{
+			testName:        "KRaft mode switched to ZooKeeper mode",
+			kafkaClusterOld: v1beta1.KafkaCluster{Spec: v1beta1.KafkaClusterSpec{KRaftMode: true}},
+			kafkaClusterNew: v1beta1.KafkaCluster{},
+			expected: append(field.ErrorList{},
+				field.Forbidden(field.NewPath("spec").Child("kRaft"), unsupportedKRaftModeChangeErrMsg)),
+		},
+		{
+			testName: "KRaft mode enabled after the migration completed",
+			kafkaClusterOld: v1beta1.KafkaCluster{
+				Spec:   v1beta1.KafkaClusterSpec{MigrateToKRaft: true},
+				Status: v1beta1.KafkaClusterStatus{KRaftMigrationState: v1beta1.KafkaClusterKRaftMigrationCompleted},
+			},
+			kafkaClusterNew: v1beta1.KafkaCluster{Spec: v1beta1.KafkaClusterSpec{KRaftMode: true}},
+			expected:        nil,
+		},
+		{
+			testName: "KRaft mode enabled during the migration",
+			kafkaClusterOld: v1beta1.KafkaCluster{
+				Spec:   v1beta1.KafkaClusterSpec{MigrateToKRaft: true},
+				Status: v1beta1.KafkaClusterStatus{KRaftMigrationState: v1beta1.KafkaClusterKRaftMigrationMigratingBrokers},
+			},
+			kafkaClusterNew: v1beta1.KafkaCluster{Spec: v1beta1.KafkaClusterSpec{KRaftMode: true, MigrateToKRaft: true}},
+			expected: append(field.ErrorList{},
+				field.Forbidden(field.NewPath("spec").Child("kRaft"), unsupportedKRaftModeChangeErrMsg)),
+		},
+		{
+			testName: "migration disabled before it started",
+			kafkaClusterOld: v1beta1.KafkaCluster{
+				Spec: v1beta1.KafkaClusterSpec{MigrateToKRaft: true},
+			},
+			kafkaClusterNew: v1beta1.KafkaCluster{},
+			expected:        nil,
+		},
+		{
+			testName: "migration disabled while in progress",
+			kafkaClusterOld: v1beta1.KafkaCluster{
+				Spec:   v1beta1.KafkaClusterSpec{MigrateToKRaft: true},
+				Status: v1beta1.KafkaClusterStatus{KRaftMigrationState: v1beta1.KafkaClusterKRaftMigrationEnablingBrokers},
+			},
+			kafkaClusterNew: v1beta1.KafkaCluster{},
+			expected: append(field.ErrorList{},
+				field.Forbidden(field.NewPath("spec").Child("migrateToKRaft"), unsupportedKRaftMigrationRevertErrMsg)),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			got := checkKRaftModeChange(&testCase.kafkaClusterOld, &testCase.kafkaClusterNew)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckKafkaVersionDowngrade(t *testing.T) {
+	testCases := []struct {
+		testName         string
+		protocolVersion  string
+		kafkaClusterSpec v1beta1.KafkaClusterSpec
+		expected         field.ErrorList
+	}{
+		{
+			testName:         "protocol version not known yet",
+			protocolVersion:  "",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{ClusterImage: "ghcr.io/banzaicloud/kafka:2.13-2.8.1"},
+			expected:         nil,
+		},
+		{
+			testName:         "upgrade",
+			protocolVersion:  "3.4",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{ClusterImage: "ghcr.io/banzaicloud/kafka:2.13-3.5.1"},
+			expected:         nil,
+		},
+		{
+			testName:         "patch version downgrade",
+			protocolVersion:  "3.4",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{ClusterImage: "ghcr.io/banzaicloud/kafka:2.13-3.4.0"},
+			expected:         nil,
+		},
+		{
+			testName:         "image without version",
+			protocolVersion:  "3.4",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{ClusterImage: "ghcr.io/banzaicloud/kafka:latest"},
+			expected:         nil,
+		},
+		{
+			testName:        "downgrade",
+			protocolVersion: "3.4",
+			kafkaClusterSpec: v1beta1.KafkaClusterSpec{
+				ClusterImage: "ghcr.io/banzaicloud/kafka:2.13-3.3.2",
+				BrokerConfigGroups: map[string]v1beta1.BrokerConfig{
+					"default": {Image: "ghcr.io/banzaicloud/kafka:2.13-3.4.1"},
+				},
+				Brokers: []v1beta1.Broker{
+					{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{Image: "ghcr.io/banzaicloud/kafka:2.13-2.8.1"}},
+					{Id: 1},
+				},
+			},
+			expected: append(field.ErrorList{},
+				field.Forbidden(field.NewPath("spec").Child("clusterImage"), unsupportedKafkaDowngradeErrMsg),
+				field.Forbidden(field.NewPath("spec").Child("brokers").Index(0).Child("brokerConfig").Child("image"), unsupportedKafkaDowngradeErrMsg)),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			got := checkKafkaVersionDowngrade(testCase.protocolVersion, &testCase.kafkaClusterSpec)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
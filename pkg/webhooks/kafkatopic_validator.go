@@ -38,11 +38,17 @@ import (
 	"github.com/banzaicloud/koperator/pkg/k8sutil"
 	"github.com/banzaicloud/koperator/pkg/kafkaclient"
 	"github.com/banzaicloud/koperator/pkg/util"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
 )
 
 const (
 	TopicManagedByAnnotationKey            = "managedBy"
 	TopicManagedByKoperatorAnnotationValue = "koperator"
+	// TopicManagedByDiscoveryAnnotationValue marks the KafkaTopics created for the existing topics by the topic discovery,
+	// which are not managed by Koperator until the annotation is changed to koperator
+	TopicManagedByDiscoveryAnnotationValue = "koperator-discovery"
+	// TopicDeletionProtectionAnnotationKey refuses the deletion of the KafkaTopic when set to "true"
+	TopicDeletionProtectionAnnotationKey = "kafka.banzaicloud.io/deletion-protection"
 )
 
 type KafkaTopicValidator struct {
@@ -60,7 +66,36 @@ func (s KafkaTopicValidator) ValidateUpdate(ctx context.Context, oldObj, newObj
 }
 
 func (s KafkaTopicValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
-	return nil, nil
+	kafkaTopic := obj.(*banzaicloudv1alpha1.KafkaTopic)
+	if !isTopicDeletionProtected(kafkaTopic) {
+		return nil, nil
+	}
+	log := s.Log.WithValues("name", kafkaTopic.GetName(), "namespace", kafkaTopic.GetNamespace())
+
+	// The topics of a KafkaCluster being deleted are let through, the protection would block the cluster deletion
+	clusterNamespace := kafkaTopic.Spec.ClusterRef.Namespace
+	if clusterNamespace == "" {
+		clusterNamespace = kafkaTopic.GetNamespace()
+	}
+	cluster, err := k8sutil.LookupKafkaCluster(ctx, s.Client, kafkaTopic.Spec.ClusterRef.Name, clusterNamespace)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, errorDuringValidationMsg)
+			return nil, apierrors.NewInternalError(errors.WithMessage(err, cantConnectAPIServerMsg))
+		}
+		return nil, nil
+	}
+	if k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
+		return nil, nil
+	}
+
+	log.Info("rejected", "reason", topicDeletionProtectedErrMsg)
+	return nil, apierrors.NewForbidden(banzaicloudv1alpha1.GroupVersion.WithResource("kafkatopics").GroupResource(),
+		kafkaTopic.Name, errors.Errorf("%s, remove the %q annotation to delete it", topicDeletionProtectedErrMsg, TopicDeletionProtectionAnnotationKey))
+}
+
+func isTopicDeletionProtected(topic *banzaicloudv1alpha1.KafkaTopic) bool {
+	return strings.ToLower(topic.GetAnnotations()[TopicDeletionProtectionAnnotationKey]) == "true"
 }
 
 func (s *KafkaTopicValidator) validate(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
@@ -82,6 +117,27 @@ func (s *KafkaTopicValidator) validate(ctx context.Context, obj runtime.Object)
 }
 
 func (s *KafkaTopicValidator) validateKafkaTopic(ctx context.Context, log logr.Logger, topic *banzaicloudv1alpha1.KafkaTopic) (field.ErrorList, error) {
+	// The topic is validated together with the defaults of its topic class
+	if topic.Spec.TopicClassName != "" {
+		topicClass := &banzaicloudv1alpha1.KafkaTopicClass{}
+		if err := s.Client.Get(ctx, types.NamespacedName{Name: topic.Spec.TopicClassName}, topicClass); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, errors.Wrap(err, cantConnectAPIServerMsg)
+			}
+			if k8sutil.IsMarkedForDeletion(topic.ObjectMeta) {
+				return nil, nil
+			}
+			return field.ErrorList{field.NotFound(field.NewPath("spec").Child("topicClassName"), topic.Spec.TopicClassName)}, nil
+		}
+		merged := topic.DeepCopy()
+		merged.Spec = topic.Spec.WithTopicClass(&topicClass.Spec)
+		topic = merged
+	}
+	return s.validateKafkaTopicSpec(ctx, log, topic)
+}
+
+// validateKafkaTopicSpec validates the topic whose spec is already completed with the defaults of its topic class
+func (s *KafkaTopicValidator) validateKafkaTopicSpec(ctx context.Context, log logr.Logger, topic *banzaicloudv1alpha1.KafkaTopic) (field.ErrorList, error) {
 	var allErrs field.ErrorList
 	var logMsg string
 	// First check if the kafkatopic is valid
@@ -129,6 +185,8 @@ func (s *KafkaTopicValidator) validateKafkaTopic(ctx context.Context, log logr.L
 		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("clusterRef").Child("name"), clusterName, logMsg))
 	}
 
+	allErrs = append(allErrs, checkTopicPlacement(&topic.Spec, cluster)...)
+
 	fieldErr, err := s.checkExistingKafkaTopicCRs(ctx, clusterNamespace, topic)
 	if err != nil {
 		return nil, err
@@ -172,7 +230,9 @@ func (s *KafkaTopicValidator) checkKafka(ctx context.Context, topic *banzaicloud
 		if err := s.Client.Get(ctx, types.NamespacedName{Name: topic.Name, Namespace: topic.Namespace}, topicCR); err != nil {
 			// Checking that the validation request is update
 			if apierrors.IsNotFound(err) {
-				if manager, ok := topic.GetAnnotations()[TopicManagedByAnnotationKey]; !ok || strings.ToLower(manager) != TopicManagedByKoperatorAnnotationValue {
+				manager, ok := topic.GetAnnotations()[TopicManagedByAnnotationKey]
+				manager = strings.ToLower(manager)
+				if !ok || (manager != TopicManagedByKoperatorAnnotationValue && manager != TopicManagedByDiscoveryAnnotationValue) {
 					allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("name"), topic.Spec.Name,
 						fmt.Sprintf(`topic "%s" already exists on kafka cluster and it is not managed by Koperator,
 					if you want it to be managed by Koperator so you can modify its configurations through a KafkaTopic CR,
@@ -208,10 +268,10 @@ func (s *KafkaTopicValidator) checkKafka(ctx context.Context, topic *banzaicloud
 				fmt.Sprintf("kafka does not support decreasing partition count on an existing topic (from %v to %v)", existing.NumPartitions, topic.Spec.Partitions)))
 		}
 
-		// check if the user is trying to change the replication factor
-		if existing.ReplicationFactor != int16(topic.Spec.ReplicationFactor) {
+		// the partitions are reassigned when the replication factor is changed, which needs enough brokers
+		if existing.ReplicationFactor != int16(topic.Spec.ReplicationFactor) && int(topic.Spec.ReplicationFactor) > broker.NumBrokers() {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("replicationFactor"), topic.Spec.ReplicationFactor,
-				fmt.Sprintf("kafka does not support changing the replication factor on an existing topic (from %v to %v)", existing.ReplicationFactor, topic.Spec.ReplicationFactor)))
+				fmt.Sprintf("%s (available brokers: %v)", invalidReplicationFactorErrMsg, broker.NumBrokers())))
 		}
 
 		// the topic does not exist check if requesting a replication factor larger than the broker size
@@ -222,6 +282,95 @@ func (s *KafkaTopicValidator) checkKafka(ctx context.Context, topic *banzaicloud
 	return allErrs, nil
 }
 
+// checkTopicPlacement checks that the brokers and racks referenced by the placement of the topic exist in the KafkaCluster,
+// and that the replica assignment matches the partitions and replication factor of the topic
+func checkTopicPlacement(spec *banzaicloudv1alpha1.KafkaTopicSpec, cluster *banzaicloudv1beta1.KafkaCluster) field.ErrorList {
+	placement := spec.Placement
+	if placement == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	placementPath := field.NewPath("spec").Child("placement")
+	if placement.IsConstrained() && placement.HasReplicaAssignment() {
+		allErrs = append(allErrs, field.Invalid(placementPath.Child("replicaAssignment"), placement.ReplicaAssignment, conflictingTopicPlacementErrMsg))
+		return allErrs
+	}
+
+	brokerRacks := make(map[int32]string, len(cluster.Spec.Brokers))
+	racks := make(map[string]bool)
+	for _, broker := range cluster.Spec.Brokers {
+		brokerRacks[broker.Id] = ""
+		readOnlyConfigs, err := properties.NewFromString(broker.ReadOnlyConfig)
+		if err != nil {
+			continue
+		}
+		if brokerRack, found := readOnlyConfigs.Get("broker.rack"); found {
+			brokerRacks[broker.Id] = brokerRack.Value()
+			racks[brokerRack.Value()] = true
+		}
+	}
+
+	placementBrokers := make(map[int32]bool, len(placement.Brokers))
+	for i, brokerID := range placement.Brokers {
+		placementBrokers[brokerID] = true
+		if _, ok := brokerRacks[brokerID]; !ok {
+			allErrs = append(allErrs, field.NotFound(placementPath.Child("brokers").Index(i), brokerID))
+		}
+	}
+	for i, rack := range placement.Racks {
+		if !racks[rack] {
+			allErrs = append(allErrs, field.NotFound(placementPath.Child("racks").Index(i), rack))
+		}
+	}
+
+	if placement.IsConstrained() {
+		if spec.Partitions <= 0 || spec.ReplicationFactor <= 0 {
+			allErrs = append(allErrs, field.Invalid(placementPath, placement, missingPlacementTopicSizeErrMsg))
+			return allErrs
+		}
+		allowedBrokers := 0
+		for brokerID, rack := range brokerRacks {
+			if (len(placement.Brokers) == 0 || placementBrokers[brokerID]) &&
+				(len(placement.Racks) == 0 || util.StringSliceContains(placement.Racks, rack)) {
+				allowedBrokers++
+			}
+		}
+		if int(spec.ReplicationFactor) > allowedBrokers {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("replicationFactor"), spec.ReplicationFactor,
+				fmt.Sprintf("%s (allowed brokers: %v)", notEnoughPlacementBrokersErrMsg, allowedBrokers)))
+		}
+	}
+
+	if placement.HasReplicaAssignment() {
+		assignmentPath := placementPath.Child("replicaAssignment")
+		if spec.Partitions > 0 && len(placement.ReplicaAssignment) != int(spec.Partitions) {
+			allErrs = append(allErrs, field.Invalid(assignmentPath, len(placement.ReplicaAssignment),
+				fmt.Sprintf("%s (partitions: %v)", invalidReplicaAssignmentErrMsg, spec.Partitions)))
+		}
+		replicationFactor := len(placement.ReplicaAssignment[0])
+		if spec.ReplicationFactor > 0 {
+			replicationFactor = int(spec.ReplicationFactor)
+		}
+		for partition, replicas := range placement.ReplicaAssignment {
+			if len(replicas) != replicationFactor {
+				allErrs = append(allErrs, field.Invalid(assignmentPath.Index(partition), replicas,
+					fmt.Sprintf("%s (replication factor: %v)", invalidReplicaAssignmentErrMsg, replicationFactor)))
+			}
+			seen := make(map[int32]bool, len(replicas))
+			for i, brokerID := range replicas {
+				if _, ok := brokerRacks[brokerID]; !ok {
+					allErrs = append(allErrs, field.NotFound(assignmentPath.Index(partition).Index(i), brokerID))
+				}
+				if seen[brokerID] {
+					allErrs = append(allErrs, field.Duplicate(assignmentPath.Index(partition).Index(i), brokerID))
+				}
+				seen[brokerID] = true
+			}
+		}
+	}
+	return allErrs
+}
+
 // checkExistingKafkaTopicCRs checks whether there's any other duplicate KafkaTopic CR exists
 // that refers to the same KafkaCluster's same topic
 func (s *KafkaTopicValidator) checkExistingKafkaTopicCRs(ctx context.Context,
@@ -346,7 +346,7 @@ func TestValidateTopic(t *testing.T) {
 		t.Error("Expected not allowed for reason: kafka does not support decreasing partition count")
 	}
 
-	// replication factor change attempt
+	// replication factor increase beyond the number of brokers
 	topic.Spec.Partitions = 2
 	topic.Spec.ReplicationFactor = 2
 	fieldErrorList, err = kafkaTopicValidator.validateKafkaTopic(context.Background(), logr.Discard(), topic)
@@ -354,8 +354,200 @@ func TestValidateTopic(t *testing.T) {
 		t.Errorf("err should be nil, got: %s", err)
 	}
 	if len(fieldErrorList) != 1 {
-		t.Error("Expected not allowed due to replication factor change, got allowed")
-	} else if !strings.Contains(fieldErrorList.ToAggregate().Error(), "kafka does not support changing the replication factor") {
-		t.Error("Expected not allowed for reason: kafka does not support changing the replication factor")
+		t.Error("Expected not allowed due to replication factor larger than num brokers, got allowed")
+	} else if !strings.Contains(fieldErrorList.ToAggregate().Error(), invalidReplicationFactorErrMsg) {
+		t.Errorf("Expected not allowed for reason: %s", invalidReplicationFactorErrMsg)
+	}
+}
+
+func TestValidateTopicDelete(t *testing.T) {
+	topic := newMockTopic()
+	cluster := newMockCluster()
+	client, _, returnMockedKafkaClient := newMockClients(cluster)
+
+	kafkaTopicValidator := KafkaTopicValidator{
+		Client:              client,
+		NewKafkaFromCluster: returnMockedKafkaClient,
+		Log:                 logr.Discard(),
+	}
+
+	// Unprotected topic
+	if _, err := kafkaTopicValidator.ValidateDelete(context.Background(), topic); err != nil {
+		t.Error("Expected no error for unprotected topic, got:", err)
+	}
+
+	// Protected topic of a non-existent cluster
+	topic.SetAnnotations(map[string]string{TopicDeletionProtectionAnnotationKey: "true"})
+	if _, err := kafkaTopicValidator.ValidateDelete(context.Background(), topic); err != nil {
+		t.Error("Expected no error for protected topic of a non-existent cluster, got:", err)
+	}
+
+	// Protected topic
+	if err := client.Create(context.Background(), cluster); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if _, err := kafkaTopicValidator.ValidateDelete(context.Background(), topic); err == nil {
+		t.Error("Expected deletion of protected topic to be refused, got allowed")
+	} else if !strings.Contains(err.Error(), topicDeletionProtectedErrMsg) {
+		t.Errorf("Expected not allowed for reason: %s, got: %s", topicDeletionProtectedErrMsg, err)
+	}
+
+	// Protected topic of a cluster being deleted
+	cluster.SetFinalizers([]string{"finalizer.kafkaclusters.kafka.banzaicloud.io"})
+	if err := client.Update(context.Background(), cluster); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if err := client.Delete(context.Background(), cluster); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if _, err := kafkaTopicValidator.ValidateDelete(context.Background(), topic); err != nil {
+		t.Error("Expected no error for protected topic of a cluster being deleted, got:", err)
+	}
+}
+
+func TestValidateTopicWithTopicClass(t *testing.T) {
+	topic := newMockTopic()
+	topic.Spec.TopicClassName = "standard"
+	cluster := newMockCluster()
+	client, _, returnMockedKafkaClient := newMockClients(cluster)
+
+	kafkaTopicValidator := KafkaTopicValidator{
+		Client:              client,
+		NewKafkaFromCluster: returnMockedKafkaClient,
+	}
+	if err := client.Create(context.Background(), cluster); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	// Test non-existent topic class
+	fieldErrorList, err := kafkaTopicValidator.validateKafkaTopic(context.Background(), logr.Discard(), topic)
+	if err != nil {
+		t.Errorf("err should be nil, got: %s", err)
+	}
+	if len(fieldErrorList) != 1 || !strings.Contains(fieldErrorList.ToAggregate().Error(), "topicClassName") {
+		t.Errorf("Expected not found topic class, got: %v", fieldErrorList)
+	}
+
+	// Test partitions and replication factor supplied by the topic class
+	topicClass := &v1alpha1.KafkaTopicClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+		Spec:       v1alpha1.KafkaTopicClassSpec{Partitions: 2, ReplicationFactor: 1},
+	}
+	if err := client.Create(context.Background(), topicClass); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	fieldErrorList, err = kafkaTopicValidator.validateKafkaTopic(context.Background(), logr.Discard(), topic)
+	if err != nil {
+		t.Errorf("err should be nil, got: %s", err)
+	}
+	if len(fieldErrorList) != 0 {
+		t.Errorf("Expected the topic to be valid with the topic class defaults, got: %v", fieldErrorList)
+	}
+
+	// Test the merged replication factor being larger than the number of brokers
+	topicClass.Spec.ReplicationFactor = 3
+	if err := client.Update(context.Background(), topicClass); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	fieldErrorList, err = kafkaTopicValidator.validateKafkaTopic(context.Background(), logr.Discard(), topic)
+	if err != nil {
+		t.Errorf("err should be nil, got: %s", err)
+	}
+	if len(fieldErrorList) != 1 || !strings.Contains(fieldErrorList.ToAggregate().Error(), invalidReplicationFactorErrMsg) {
+		t.Errorf("Expected not allowed for reason: %s, got: %v", invalidReplicationFactorErrMsg, fieldErrorList)
+	}
+}
+
+func TestCheckTopicPlacement(t *testing.T) {
+	t.Parallel()
+	cluster := newMockCluster()
+	cluster.Spec.Brokers = []v1beta1.Broker{
+		{Id: 0, ReadOnlyConfig: "broker.rack=eu-west-1a\n"},
+		{Id: 1, ReadOnlyConfig: "broker.rack=eu-west-1b\n"},
+		{Id: 2, ReadOnlyConfig: "broker.rack=eu-west-1c\n"},
+	}
+
+	testCases := []struct {
+		testName          string
+		partitions        int32
+		replicationFactor int32
+		placement         *v1alpha1.TopicPlacement
+		expectedErrs      []string
+	}{
+		{
+			testName:          "no placement",
+			partitions:        -1,
+			replicationFactor: -1,
+		},
+		{
+			testName:          "valid racks",
+			partitions:        3,
+			replicationFactor: 2,
+			placement:         &v1alpha1.TopicPlacement{Racks: []string{"eu-west-1a", "eu-west-1b"}},
+		},
+		{
+			testName:          "unknown broker and rack",
+			partitions:        3,
+			replicationFactor: 1,
+			placement:         &v1alpha1.TopicPlacement{Brokers: []int32{0, 5}, Racks: []string{"eu-west-1d"}},
+			expectedErrs:      []string{"spec.placement.brokers[1]", "spec.placement.racks[0]", notEnoughPlacementBrokersErrMsg},
+		},
+		{
+			testName:          "not enough brokers in the racks",
+			partitions:        3,
+			replicationFactor: 2,
+			placement:         &v1alpha1.TopicPlacement{Racks: []string{"eu-west-1a"}},
+			expectedErrs:      []string{notEnoughPlacementBrokersErrMsg},
+		},
+		{
+			testName:          "broker's default partitions",
+			partitions:        -1,
+			replicationFactor: 2,
+			placement:         &v1alpha1.TopicPlacement{Brokers: []int32{0, 1}},
+			expectedErrs:      []string{missingPlacementTopicSizeErrMsg},
+		},
+		{
+			testName:          "replica assignment together with racks",
+			partitions:        1,
+			replicationFactor: 1,
+			placement:         &v1alpha1.TopicPlacement{Racks: []string{"eu-west-1a"}, ReplicaAssignment: [][]int32{{0}}},
+			expectedErrs:      []string{conflictingTopicPlacementErrMsg},
+		},
+		{
+			testName:          "valid replica assignment",
+			partitions:        2,
+			replicationFactor: 2,
+			placement:         &v1alpha1.TopicPlacement{ReplicaAssignment: [][]int32{{0, 1}, {1, 2}}},
+		},
+		{
+			testName:          "invalid replica assignment",
+			partitions:        3,
+			replicationFactor: 2,
+			placement:         &v1alpha1.TopicPlacement{ReplicaAssignment: [][]int32{{0, 0}, {1, 3}}},
+			expectedErrs: []string{"spec.placement.replicaAssignment: Invalid value: 2",
+				"spec.placement.replicaAssignment[0][1]: Duplicate value", "spec.placement.replicaAssignment[1][1]: Not found"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			t.Parallel()
+			spec := &v1alpha1.KafkaTopicSpec{
+				Name:              "test-topic",
+				Partitions:        test.partitions,
+				ReplicationFactor: test.replicationFactor,
+				Placement:         test.placement,
+			}
+			fieldErrorList := checkTopicPlacement(spec, cluster)
+			if len(fieldErrorList) != len(test.expectedErrs) {
+				t.Fatalf("Expected %d field errors, got: %v", len(test.expectedErrs), fieldErrorList)
+			}
+			for _, expectedErr := range test.expectedErrs {
+				if !strings.Contains(fieldErrorList.ToAggregate().Error(), expectedErr) {
+					t.Errorf("Expected field error %q, got: %v", expectedErr, fieldErrorList)
+				}
+			}
+		})
 	}
 }
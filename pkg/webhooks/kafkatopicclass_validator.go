@@ -0,0 +1,140 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"emperror.dev/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/go-logr/logr"
+
+	banzaicloudv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaicloudv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// KafkaTopicClassValidator refuses the deletion of the topic classes used by KafkaTopics, and the changes of the topic
+// classes which would make the KafkaTopics using them invalid
+type KafkaTopicClassValidator struct {
+	Client              client.Client
+	NewKafkaFromCluster func(client.Client, *banzaicloudv1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error)
+	Log                 logr.Logger
+}
+
+func (s KafkaTopicClassValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return nil, nil
+}
+
+func (s KafkaTopicClassValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
+	oldTopicClass := oldObj.(*banzaicloudv1alpha1.KafkaTopicClass)
+	topicClass := newObj.(*banzaicloudv1alpha1.KafkaTopicClass)
+	log := s.Log.WithValues("name", topicClass.GetName())
+
+	fieldErrs, err := s.validateTopicClassUpdate(ctx, log, oldTopicClass, topicClass)
+	if err != nil {
+		log.Error(err, errorDuringValidationMsg)
+		return nil, apierrors.NewInternalError(errors.WithMessage(err, errorDuringValidationMsg))
+	}
+	if len(fieldErrs) == 0 {
+		return nil, nil
+	}
+	log.Info("rejected", "invalid field(s)", fieldErrs.ToAggregate().Error())
+	return nil, apierrors.NewInvalid(
+		topicClass.GetObjectKind().GroupVersionKind().GroupKind(),
+		topicClass.Name, fieldErrs)
+}
+
+func (s KafkaTopicClassValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	topicClass := obj.(*banzaicloudv1alpha1.KafkaTopicClass)
+	log := s.Log.WithValues("name", topicClass.GetName())
+
+	topics, err := s.kafkaTopicsOfClass(ctx, topicClass.Name)
+	if err != nil {
+		log.Error(err, errorDuringValidationMsg)
+		return nil, apierrors.NewInternalError(errors.WithMessage(err, cantConnectAPIServerMsg))
+	}
+	if len(topics) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		names = append(names, topic.Namespace+"/"+topic.Name)
+	}
+
+	log.Info("rejected", "reason", topicClassInUseErrMsg, "kafkaTopics", names)
+	return nil, apierrors.NewForbidden(banzaicloudv1alpha1.GroupVersion.WithResource("kafkatopicclasses").GroupResource(),
+		topicClass.Name, errors.Errorf("%s: %s", topicClassInUseErrMsg, strings.Join(names, ", ")))
+}
+
+// validateTopicClassUpdate validates the KafkaTopics using the topic class with the partitions, replication factor and
+// config of the updated class. The topics not changed by the update are not validated again.
+func (s *KafkaTopicClassValidator) validateTopicClassUpdate(ctx context.Context, log logr.Logger,
+	oldTopicClass, topicClass *banzaicloudv1alpha1.KafkaTopicClass) (field.ErrorList, error) {
+	topics, err := s.kafkaTopicsOfClass(ctx, topicClass.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, cantConnectAPIServerMsg)
+	}
+
+	topicValidator := KafkaTopicValidator{
+		Client:              s.Client,
+		NewKafkaFromCluster: s.NewKafkaFromCluster,
+		Log:                 s.Log,
+	}
+	var allErrs field.ErrorList
+	for i := range topics {
+		topic := topics[i].DeepCopy()
+		spec := topic.Spec.WithTopicClass(&topicClass.Spec)
+		if reflect.DeepEqual(spec, topic.Spec.WithTopicClass(&oldTopicClass.Spec)) {
+			continue
+		}
+		topic.Spec = spec
+		topicErrs, err := topicValidator.validateKafkaTopicSpec(ctx, log.WithValues("kafkaTopic", topic.Name, "namespace", topic.Namespace), topic)
+		if err != nil {
+			return nil, err
+		}
+		for _, topicErr := range topicErrs {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"),
+				fmt.Sprintf("%s %s/%s: %s", invalidTopicClassChangeErrMsg, topic.Namespace, topic.Name, topicErr.Error())))
+		}
+	}
+	return allErrs, nil
+}
+
+// kafkaTopicsOfClass returns the KafkaTopics using the topic class, the ones being deleted are left out
+func (s *KafkaTopicClassValidator) kafkaTopicsOfClass(ctx context.Context, topicClassName string) ([]banzaicloudv1alpha1.KafkaTopic, error) {
+	kafkaTopics := &banzaicloudv1alpha1.KafkaTopicList{}
+	if err := s.Client.List(ctx, kafkaTopics); err != nil {
+		return nil, err
+	}
+	var topics []banzaicloudv1alpha1.KafkaTopic
+	for _, topic := range kafkaTopics.Items {
+		if topic.Spec.TopicClassName == topicClassName && !k8sutil.IsMarkedForDeletion(topic.ObjectMeta) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics, nil
+}
@@ -0,0 +1,122 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+func TestValidateTopicClassDelete(t *testing.T) {
+	cluster := newMockCluster()
+	client, _, returnMockedKafkaClient := newMockClients(cluster)
+	topicClassValidator := KafkaTopicClassValidator{
+		Client:              client,
+		NewKafkaFromCluster: returnMockedKafkaClient,
+		Log:                 logr.Discard(),
+	}
+	topicClass := &v1alpha1.KafkaTopicClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}}
+
+	// Test topic class without KafkaTopics
+	if _, err := topicClassValidator.ValidateDelete(context.Background(), topicClass); err != nil {
+		t.Errorf("Expected no error for unused topic class, got: %s", err)
+	}
+
+	// Test topic class used by a KafkaTopic
+	topic := newMockTopic()
+	topic.Spec.TopicClassName = "standard"
+	if err := client.Create(context.Background(), topic); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	_, err := topicClassValidator.ValidateDelete(context.Background(), topicClass)
+	if !apierrors.IsForbidden(err) || !strings.Contains(err.Error(), "test-namespace/test-topic") {
+		t.Errorf("Expected forbidden for reason: %s, got: %v", topicClassInUseErrMsg, err)
+	}
+}
+
+func TestValidateTopicClassUpdate(t *testing.T) {
+	cluster := newMockCluster()
+	client, kafkaClient, returnMockedKafkaClient := newMockClients(cluster)
+	topicClassValidator := KafkaTopicClassValidator{
+		Client:              client,
+		NewKafkaFromCluster: returnMockedKafkaClient,
+		Log:                 logr.Discard(),
+	}
+	if err := client.Create(context.Background(), cluster); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	oldTopicClass := &v1alpha1.KafkaTopicClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+		Spec:       v1alpha1.KafkaTopicClassSpec{Partitions: 3, ReplicationFactor: 1},
+	}
+	topic := newMockTopic()
+	topic.Spec.TopicClassName = "standard"
+	if err := client.Create(context.Background(), topic); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	// The topic overriding the partitions is not affected by the partitions of the class
+	overriding := newMockTopic()
+	overriding.Name = "overriding-topic"
+	overriding.Spec.Name = "overriding-topic"
+	overriding.Spec.Partitions = 1
+	overriding.Spec.TopicClassName = "standard"
+	if err := client.Create(context.Background(), overriding); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if err := kafkaClient.CreateTopic(&kafkaclient.CreateTopicOptions{Name: "test-topic", Partitions: 3, ReplicationFactor: 1}); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+
+	// Test increasing the partitions of the topics using the class
+	topicClass := oldTopicClass.DeepCopy()
+	topicClass.Spec.Partitions = 6
+	fieldErrorList, err := topicClassValidator.validateTopicClassUpdate(context.Background(), logr.Discard(), oldTopicClass, topicClass)
+	if err != nil {
+		t.Errorf("err should be nil, got: %s", err)
+	}
+	if len(fieldErrorList) != 0 {
+		t.Errorf("Expected the topic class change to be valid, got: %v", fieldErrorList)
+	}
+
+	// Test decreasing the partitions of an existing topic using the class
+	topicClass.Spec.Partitions = 2
+	fieldErrorList, err = topicClassValidator.validateTopicClassUpdate(context.Background(), logr.Discard(), oldTopicClass, topicClass)
+	if err != nil {
+		t.Errorf("err should be nil, got: %s", err)
+	}
+	if len(fieldErrorList) != 1 || !strings.Contains(fieldErrorList.ToAggregate().Error(), "test-namespace/test-topic") ||
+		!strings.Contains(fieldErrorList.ToAggregate().Error(), "decreasing partition count") {
+		t.Errorf("Expected not allowed for reason: %s, got: %v", invalidTopicClassChangeErrMsg, fieldErrorList)
+	}
+
+	// Test removing the partitions the topics using the class rely on
+	topicClass.Spec.Partitions = 0
+	fieldErrorList, err = topicClassValidator.validateTopicClassUpdate(context.Background(), logr.Discard(), oldTopicClass, topicClass)
+	if err != nil {
+		t.Errorf("err should be nil, got: %s", err)
+	}
+	if len(fieldErrorList) == 0 || !strings.Contains(fieldErrorList.ToAggregate().Error(), outOfRangePartitionsErrMsg) {
+		t.Errorf("Expected not allowed for reason: %s, got: %v", outOfRangePartitionsErrMsg, fieldErrorList)
+	}
+}
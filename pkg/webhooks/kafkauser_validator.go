@@ -0,0 +1,130 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"reflect"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"emperror.dev/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/go-logr/logr"
+
+	banzaicloudv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaicloudv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+type KafkaUserValidator struct {
+	Client client.Client
+	Log    logr.Logger
+}
+
+func (s KafkaUserValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return s.validate(ctx, nil, obj)
+}
+
+func (s KafkaUserValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
+	return s.validate(ctx, oldObj.(*banzaicloudv1alpha1.KafkaUser), newObj)
+}
+
+func (s KafkaUserValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return nil, nil
+}
+
+func (s *KafkaUserValidator) validate(ctx context.Context, oldKafkaUser *banzaicloudv1alpha1.KafkaUser, obj runtime.Object) (warnings admission.Warnings, err error) {
+	kafkaUser := obj.(*banzaicloudv1alpha1.KafkaUser)
+	log := s.Log.WithValues("name", kafkaUser.GetName(), "namespace", kafkaUser.GetNamespace())
+
+	fieldErrs, err := s.validateKafkaUser(ctx, oldKafkaUser, kafkaUser)
+	if err != nil {
+		log.Error(err, errorDuringValidationMsg)
+		return nil, apierrors.NewInternalError(errors.WithMessage(err, errorDuringValidationMsg))
+	}
+	if len(fieldErrs) == 0 {
+		return nil, nil
+	}
+	log.Info("rejected", "invalid field(s)", fieldErrs.ToAggregate().Error())
+	return nil, apierrors.NewInvalid(
+		kafkaUser.GetObjectKind().GroupVersionKind().GroupKind(),
+		kafkaUser.Name, fieldErrs)
+}
+
+func (s *KafkaUserValidator) validateKafkaUser(ctx context.Context, oldUser, user *banzaicloudv1alpha1.KafkaUser) (field.ErrorList, error) {
+	// The finalizer of a user being deleted has to be removable whatever its spec is
+	if k8sutil.IsMarkedForDeletion(user.ObjectMeta) {
+		return nil, nil
+	}
+	// The grants are only checked when they change, so the users created before the cluster required scoped
+	// consumer groups can still be updated, e.g. their labels and finalizers
+	if oldUser != nil && reflect.DeepEqual(oldUser.Spec.TopicGrants, user.Spec.TopicGrants) {
+		return nil, nil
+	}
+	allErrs := checkTopicGrantConsumerGroups(user.Spec.TopicGrants)
+
+	clusterNamespace := user.Spec.ClusterRef.Namespace
+	if clusterNamespace == "" {
+		clusterNamespace = user.GetNamespace()
+	}
+	cluster, err := k8sutil.LookupKafkaCluster(ctx, s.Client, user.Spec.ClusterRef.Name, clusterNamespace)
+	if err != nil {
+		// The controller waits for the cluster to be created, there is nothing to check against until then
+		if apierrors.IsNotFound(err) {
+			return allErrs, nil
+		}
+		return nil, errors.Wrap(err, cantConnectAPIServerMsg)
+	}
+	return append(allErrs, checkScopedConsumerGroups(user.Spec.TopicGrants, cluster)...), nil
+}
+
+// checkTopicGrantConsumerGroups checks that the consumer groups are only set for the read grants
+func checkTopicGrantConsumerGroups(grants []banzaicloudv1alpha1.UserTopicGrant) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, grant := range grants {
+		if grant.AccessType != banzaicloudv1alpha1.KafkaAccessTypeRead && grant.ConsumerGroup != "" {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("topicGrants").Index(i).Child("consumerGroup"),
+				grant.ConsumerGroup, consumerGroupOfWriteGrantErrMsg))
+		}
+	}
+	return allErrs
+}
+
+// checkScopedConsumerGroups checks that the read grants are restricted to a consumer group when the cluster requires it
+func checkScopedConsumerGroups(grants []banzaicloudv1alpha1.UserTopicGrant, cluster *banzaicloudv1beta1.KafkaCluster) field.ErrorList {
+	if !cluster.Spec.RequireScopedConsumerGroups {
+		return nil
+	}
+	var allErrs field.ErrorList
+	for i, grant := range grants {
+		if grant.HasScopedConsumerGroup() {
+			continue
+		}
+		path := field.NewPath("spec").Child("topicGrants").Index(i).Child("consumerGroup")
+		switch grant.ConsumerGroup {
+		case "":
+			allErrs = append(allErrs, field.Required(path, missingScopedConsumerGroupErrMsg))
+		case banzaicloudv1alpha1.KafkaAnyConsumerGroup:
+			allErrs = append(allErrs, field.Invalid(path, grant.ConsumerGroup, missingScopedConsumerGroupErrMsg))
+		}
+	}
+	return allErrs
+}
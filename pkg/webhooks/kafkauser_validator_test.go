@@ -0,0 +1,154 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+)
+
+func newMockUser(grants ...v1alpha1.UserTopicGrant) *v1alpha1.KafkaUser {
+	return &v1alpha1.KafkaUser{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "KafkaUser",
+			APIVersion: "v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-user", Namespace: "test-namespace"},
+		Spec: v1alpha1.KafkaUserSpec{
+			SecretName: "test-user-secret",
+			ClusterRef: v1alpha1.ClusterReference{
+				Name: "test-cluster",
+			},
+			TopicGrants: grants,
+		},
+	}
+}
+
+func TestValidateUser(t *testing.T) {
+	readAnyGroup := v1alpha1.UserTopicGrant{TopicName: "test-topic", AccessType: v1alpha1.KafkaAccessTypeRead}
+	readScopedGroup := v1alpha1.UserTopicGrant{
+		TopicName:                "test-topic",
+		AccessType:               v1alpha1.KafkaAccessTypeRead,
+		ConsumerGroup:            "test-group-",
+		ConsumerGroupPatternType: v1alpha1.KafkaPatternTypePrefixed,
+	}
+	readStarGroup := v1alpha1.UserTopicGrant{TopicName: "test-topic", AccessType: v1alpha1.KafkaAccessTypeRead, ConsumerGroup: "*"}
+	write := v1alpha1.UserTopicGrant{TopicName: "test-topic", AccessType: v1alpha1.KafkaAccessTypeWrite}
+	writeWithGroup := v1alpha1.UserTopicGrant{TopicName: "test-topic", AccessType: v1alpha1.KafkaAccessTypeWrite, ConsumerGroup: "test-group"}
+
+	testCases := []struct {
+		testName      string
+		requireScoped bool
+		clusterExists bool
+		grants        []v1alpha1.UserTopicGrant
+		expectedErrs  []string
+	}{
+		{
+			testName:      "any consumer group allowed by the cluster",
+			clusterExists: true,
+			grants:        []v1alpha1.UserTopicGrant{readAnyGroup, readScopedGroup, write},
+		},
+		{
+			testName:      "scoped consumer groups required by the cluster",
+			requireScoped: true,
+			clusterExists: true,
+			grants:        []v1alpha1.UserTopicGrant{readScopedGroup, write},
+		},
+		{
+			testName:      "unscoped consumer group refused by the cluster",
+			requireScoped: true,
+			clusterExists: true,
+			grants:        []v1alpha1.UserTopicGrant{readScopedGroup, readAnyGroup},
+			expectedErrs:  []string{"spec.topicGrants[1].consumerGroup", missingScopedConsumerGroupErrMsg},
+		},
+		{
+			testName:      "every consumer group refused by the cluster",
+			requireScoped: true,
+			clusterExists: true,
+			grants:        []v1alpha1.UserTopicGrant{readStarGroup},
+			expectedErrs:  []string{"spec.topicGrants[0].consumerGroup", missingScopedConsumerGroupErrMsg},
+		},
+		{
+			testName:      "consumer group of a write grant",
+			clusterExists: true,
+			grants:        []v1alpha1.UserTopicGrant{writeWithGroup},
+			expectedErrs:  []string{"spec.topicGrants[0].consumerGroup", consumerGroupOfWriteGrantErrMsg},
+		},
+		{
+			testName: "non-existent cluster",
+			grants:   []v1alpha1.UserTopicGrant{readAnyGroup},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			cluster := newMockCluster()
+			cluster.Spec.RequireScopedConsumerGroups = testCase.requireScoped
+			client, _, _ := newMockClients(cluster)
+			if testCase.clusterExists {
+				if err := client.Create(context.Background(), cluster); err != nil {
+					t.Fatal("Expected no error, got:", err)
+				}
+			}
+			kafkaUserValidator := KafkaUserValidator{
+				Client: client,
+				Log:    logr.Discard(),
+			}
+
+			user := newMockUser(testCase.grants...)
+			_, err := kafkaUserValidator.ValidateCreate(context.Background(), user)
+			if len(testCase.expectedErrs) == 0 {
+				if err != nil {
+					t.Error("Expected no error, got:", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			for _, expected := range testCase.expectedErrs {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Expected error to contain %q, got: %s", expected, err)
+				}
+			}
+
+			// The users with unchanged grants can still be updated, e.g. their labels
+			updated := user.DeepCopy()
+			updated.SetLabels(map[string]string{"kafkaCluster": "test-cluster.test-namespace"})
+			if _, err = kafkaUserValidator.ValidateUpdate(context.Background(), user, updated); err != nil {
+				t.Error("Expected no error for user with unchanged grants, got:", err)
+			}
+			// Changing the grants is checked again
+			updated.Spec.TopicGrants = append(updated.Spec.TopicGrants, testCase.grants...)
+			if _, err = kafkaUserValidator.ValidateUpdate(context.Background(), user, updated); err == nil {
+				t.Error("Expected error for user with changed grants, got nil")
+			}
+
+			// The finalizer of a user being deleted can be removed even if it is invalid
+			now := metav1.Now()
+			user.SetDeletionTimestamp(&now)
+			if _, err = kafkaUserValidator.ValidateUpdate(context.Background(), user, user); err != nil {
+				t.Error("Expected no error for user being deleted, got:", err)
+			}
+		})
+	}
+}
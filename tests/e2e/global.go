@@ -57,7 +57,9 @@ var (
 			LocalCRDSubpaths: []string{
 				"crds/cruisecontroloperations.yaml",
 				"crds/kafkaclusters.yaml",
+				"crds/kafkaconsumergroups.yaml",
 				"crds/kafkatopics.yaml",
+				"crds/kafkatopicclasses.yaml",
 				"crds/kafkausers.yaml",
 			},
 		}
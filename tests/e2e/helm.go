@@ -68,7 +68,9 @@ func (helmDescriptor *helmDescriptor) crdPath() (string, error) { //nolint:unuse
 		[]string{
 			"crds/cruisecontroloperations.yaml",
 			"crds/kafkaclusters.yaml",
+			"crds/kafkaconsumergroups.yaml",
 			"crds/kafkatopics.yaml",
+			"crds/kafkatopicclasses.yaml",
 			"crds/kafkausers.yaml",
 		},
 	))